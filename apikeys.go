@@ -0,0 +1,167 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/rsjethani/rsling"
+)
+
+// APIKeysClient exposes operations around the API keys resource.
+type APIKeysClient struct {
+	sl *rsling.Sling
+}
+
+// newAPIKeysClient creates a new instance of APIKeysClient.
+func newAPIKeysClient(sl *rsling.Sling) *APIKeysClient {
+	return &APIKeysClient{sl: sl}
+}
+
+// List returns a client for listing all API keys of the current user.
+// API reference: https://www.getoutline.com/developers#tag/ApiKeys/paths/~1apiKeys.list/post
+func (cl *APIKeysClient) List() *APIKeysListClient {
+	return newAPIKeysListClient(cl.sl)
+}
+
+// Create returns a client for creating an API key. A zero expiresAt means the key never expires and empty scopes means
+// the key has full access of the current user. Scopes restrict the key to matching endpoints e.g. "documents.info" or
+// "documents.*".
+// API reference: https://www.getoutline.com/developers#tag/ApiKeys/paths/~1apiKeys.create/post
+func (cl *APIKeysClient) Create(name string, expiresAt time.Time, scopes []string) *APIKeysCreateClient {
+	return newAPIKeysCreateClient(cl.sl, name, expiresAt, scopes)
+}
+
+// Revoke returns a client for revoking the API key identified by id.
+// API reference: https://www.getoutline.com/developers#tag/ApiKeys/paths/~1apiKeys.delete/post
+func (cl *APIKeysClient) Revoke(id APIKeyID) *APIKeysRevokeClient {
+	return newAPIKeysRevokeClient(cl.sl, id)
+}
+
+// APIKeysListClient is a client for listing API keys.
+type APIKeysListClient struct {
+	sl *rsling.Sling
+}
+
+func newAPIKeysListClient(sl *rsling.Sling) *APIKeysListClient {
+	copy := sl.New()
+	copy.Post(common.APIKeysListEndpoint())
+
+	return &APIKeysListClient{sl: copy}
+}
+
+// APIKeysListFn is the type of function called by [APIKeysListClient.Do] for every API key it finds.
+type APIKeysListFn func(*APIKey, error) (bool, error)
+
+// Do makes the actual request for listing API keys. If the request is successful then fn is called sequentially with
+// every key received. But if there is some error/bad response then fn is called with the error. If fn returns false
+// then the whole process is aborted otherwise the request is retried.
+func (cl *APIKeysListClient) Do(ctx context.Context, fn APIKeysListFn) error {
+	success := &struct {
+		Data       []*APIKey  `json:"data"`
+		Pagination pagination `json:"pagination"`
+	}{}
+
+	params := &paginationQueryParams{}
+	for {
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, key := range success.Data {
+			if ok, e := fn(key, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
+
+// apiKeysCreateParams represents the Outline ApiKeys.create parameters
+type apiKeysCreateParams struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Scope     []string   `json:"scope,omitempty"`
+}
+
+// APIKeysCreateClient is a client for creating a single API key.
+type APIKeysCreateClient struct {
+	sl     *rsling.Sling
+	params apiKeysCreateParams
+}
+
+func newAPIKeysCreateClient(sl *rsling.Sling, name string, expiresAt time.Time, scopes []string) *APIKeysCreateClient {
+	copy := sl.New()
+	params := apiKeysCreateParams{Name: name, Scope: scopes}
+	if !expiresAt.IsZero() {
+		params.ExpiresAt = &expiresAt
+	}
+	return &APIKeysCreateClient{sl: copy, params: params}
+}
+
+// Do makes the actual request to create an API key. The returned key contains the secret which can be passed to
+// [outline.New].
+func (cl *APIKeysCreateClient) Do(ctx context.Context) (*APIKey, error) {
+	req := cl.sl.New().Post(common.APIKeysCreateEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data *APIKey `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// APIKeysRevokeClient is a client for revoking a single API key.
+type APIKeysRevokeClient struct {
+	sl *rsling.Sling
+}
+
+func newAPIKeysRevokeClient(sl *rsling.Sling, id APIKeyID) *APIKeysRevokeClient {
+	data := struct {
+		ID APIKeyID `json:"id"`
+	}{ID: id}
+
+	copy := sl.New()
+	copy.Post(common.APIKeysRevokeEndpoint()).BodyJSON(&data)
+
+	return &APIKeysRevokeClient{sl: copy}
+}
+
+// Do makes the actual request to revoke the API key. Requests made with the key fail afterwards.
+func (cl *APIKeysRevokeClient) Do(ctx context.Context) error {
+	br, err := request(ctx, cl.sl, nil)
+	if err != nil {
+		return fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return nil
+}
@@ -1,21 +1,57 @@
 package outline
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/ioki-mobility/go-outline/internal/common"
 	"github.com/rsjethani/rsling"
 )
 
+// preferredExtensions are the extensions used for common content types. Others are looked up via [mime].
+var preferredExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/svg+xml":   ".svg",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// FileExtension returns the file extension, including the dot, for attachments of the given content type e.g. .png for
+// image/png. It is empty for unknown content types.
+func FileExtension(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if ext, ok := preferredExtensions[mediaType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
 // AttachmentsClient exposes CRUD operations around the attachments resource.
 type AttachmentsClient struct {
-	sl *rsling.Sling
+	sl      *rsling.Sling
+	server  *url.URL
+	storage *http.Client
 }
 
 // newAttachmentsClient creates a new instance of AttachmentsClient.
-func newAttachmentsClient(sl *rsling.Sling) *AttachmentsClient {
-	return &AttachmentsClient{sl: sl}
+func newAttachmentsClient(sl *rsling.Sling, server *url.URL, storage *http.Client) *AttachmentsClient {
+	return &AttachmentsClient{sl: sl, server: server, storage: storage}
 }
 
 // Create returns a client for creating a single attachment in the specified collection.
@@ -24,6 +60,24 @@ func (cl *AttachmentsClient) Create(name string, contentType string, size int) *
 	return newAttachmentCreateClient(cl.sl, name, contentType, size)
 }
 
+// Upload returns a client for creating a single attachment and uploading its content data in one go.
+func (cl *AttachmentsClient) Upload(name string, contentType string, data []byte) *AttachmentsUploadClient {
+	return newAttachmentsUploadClient(cl, name, contentType, data)
+}
+
+// Download returns a client for downloading the content of the attachment identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Attachments/paths/~1attachments.redirect/post
+func (cl *AttachmentsClient) Download(id AttachmentID) *AttachmentsDownloadClient {
+	return newAttachmentsDownloadClient(cl.sl, id)
+}
+
+// Redirect returns a client for finding out where the content of the attachment identified by id is stored without
+// downloading it.
+// API reference: https://www.getoutline.com/developers#tag/Attachments/paths/~1attachments.redirect/post
+func (cl *AttachmentsClient) Redirect(id AttachmentID) *AttachmentsRedirectClient {
+	return newAttachmentsRedirectClient(cl.sl, id)
+}
+
 // attachmentsCreateParams represents the Outline Attachment.create parameters
 type attachmentsCreateParams struct {
 	Name        string     `json:"name"`
@@ -46,19 +100,20 @@ func newAttachmentCreateClient(sl *rsling.Sling, name string, contentType string
 }
 
 func (cl *AttachmentCreateClient) DocumentID(id DocumentID) *AttachmentCreateClient {
-	cl.params.DocumentID = id
-	return cl
+	c := *cl
+	c.params.DocumentID = id
+	return &c
 }
 
 // Do makes the actual request to create a attachment.
 func (cl *AttachmentCreateClient) Do(ctx context.Context) (*Attachment, error) {
-	cl.sl.Post(common.AttachmentsCreateEndpoint()).BodyJSON(&cl.params)
+	req := cl.sl.New().Post(common.AttachmentsCreateEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Attachment `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -68,3 +123,167 @@ func (cl *AttachmentCreateClient) Do(ctx context.Context) (*Attachment, error) {
 
 	return success.Data, nil
 }
+
+// AttachmentsUploadClient is a client for creating a single attachment along with its content.
+type AttachmentsUploadClient struct {
+	sl      *rsling.Sling
+	server  *url.URL
+	storage *http.Client
+	create  *AttachmentCreateClient
+	name    string
+	data    []byte
+}
+
+func newAttachmentsUploadClient(
+	cl *AttachmentsClient, name string, contentType string, data []byte,
+) *AttachmentsUploadClient {
+	return &AttachmentsUploadClient{
+		sl:      cl.sl.New(),
+		server:  cl.server,
+		storage: cl.storage,
+		create:  newAttachmentCreateClient(cl.sl, name, contentType, len(data)),
+		name:    name,
+		data:    data,
+	}
+}
+
+// DocumentID sets the document the attachment belongs to.
+func (cl *AttachmentsUploadClient) DocumentID(id DocumentID) *AttachmentsUploadClient {
+	c := *cl
+	c.create = cl.create.DocumentID(id)
+	return &c
+}
+
+// Do creates the attachment and uploads its content to the upload url returned by the server, which is either the
+// server itself or a storage like S3. The upload to a storage is made with the [http.Client] the client was created
+// with but neither authorized with the client's credentials nor passed through its middlewares as the form returned
+// along with the upload url already grants access.
+func (cl *AttachmentsUploadClient) Do(ctx context.Context) (*AttachmentData, error) {
+	att, err := cl.create.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	for k, v := range att.Form {
+		if err := mw.WriteField(k, fmt.Sprint(v)); err != nil {
+			return nil, fmt.Errorf("failed writing upload form: %w", err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", cl.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed writing upload form: %w", err)
+	}
+	if _, err := fw.Write(cl.data); err != nil {
+		return nil, fmt.Errorf("failed writing upload form: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed writing upload form: %w", err)
+	}
+	uploadURL, err := cl.server.Parse(att.UploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload url '%s': %w", att.UploadURL, err)
+	}
+
+	buf := &bytes.Buffer{}
+	var resp *http.Response
+	var br *badResponse
+	if strings.EqualFold(uploadURL.Host, cl.server.Host) {
+		sl := cl.sl.New().Post(att.UploadURL).Body(body).Set(common.HdrKeyContentType, mw.FormDataContentType())
+		resp, br, err = requestRaw(ctx, sl, io.Discard)
+	} else {
+		resp, err = upload(ctx, cl.storage, uploadURL.String(), body, mw.FormDataContentType(), buf)
+		if err == nil {
+			br = newBadResponse(resp, buf)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return &att.AttachmentData, nil
+}
+
+// upload posts body to the storage at url using hc and writes the response body to w.
+func upload(
+	ctx context.Context, hc *http.Client, url string, body io.Reader, contentType string, w io.Writer,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(common.HdrKeyContentType, contentType)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// AttachmentsDownloadClient is a client for downloading the content of a single attachment.
+type AttachmentsDownloadClient struct {
+	sl *rsling.Sling
+}
+
+func newAttachmentsDownloadClient(sl *rsling.Sling, id AttachmentID) *AttachmentsDownloadClient {
+	// Attachment links in document text carry the id as query parameter hence do the same.
+	params := struct {
+		ID AttachmentID `url:"id"`
+	}{ID: id}
+
+	copy := sl.New()
+	copy.Post(common.AttachmentsRedirectEndpoint()).QueryStruct(&params)
+
+	return &AttachmentsDownloadClient{sl: copy}
+}
+
+// Do makes the actual request and writes the content of the attachment to w. The server redirects to the storage
+// holding the content which is followed automatically. The content type of the attachment is returned.
+func (cl *AttachmentsDownloadClient) Do(ctx context.Context, w io.Writer) (string, error) {
+	resp, br, err := requestRaw(ctx, cl.sl, w)
+	if err != nil {
+		return "", fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return "", fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return resp.Header.Get("Content-Type"), nil
+}
+
+// AttachmentsRedirectClient is a client for getting the url the content of a single attachment is stored at.
+type AttachmentsRedirectClient struct {
+	sl *rsling.Sling
+}
+
+func newAttachmentsRedirectClient(sl *rsling.Sling, id AttachmentID) *AttachmentsRedirectClient {
+	// The request is the same as the one made for downloading, it just stops at the redirect.
+	return &AttachmentsRedirectClient{sl: newAttachmentsDownloadClient(sl, id).sl}
+}
+
+// Do makes the actual request and returns the url the server redirects to without following the redirect. If the
+// server responds with the content right away the url of the request is returned.
+func (cl *AttachmentsRedirectClient) Do(ctx context.Context) (string, error) {
+	resp, br, err := requestRaw(context.WithValue(ctx, noRedirectKey{}, true), cl.sl, io.Discard)
+	if err != nil {
+		return "", fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return "", fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	if loc, err := resp.Location(); err == nil {
+		return loc.String(), nil
+	}
+	return resp.Request.URL.String(), nil
+}
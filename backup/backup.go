@@ -0,0 +1,209 @@
+// Package backup writes all collections of an outline workspace to an archive and restores them from it, on the same
+// or a different server.
+//
+// An archive is a zip file with the following content:
+//
+//	manifest.json
+//	documents/
+//	  4704590c-004e-410d-adf7-acb7ca0a7052.json
+//	attachments/
+//	  0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10
+//
+// The manifest holds the version of the format, every collection along with its document structure and the content
+// type of every attachment. Documents are stored as returned by the API i.e. including their text, metadata and
+// revision. Attachments are stored as is.
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// Version is the version of the archive format written by [Write]. [Restore] rejects archives of newer versions.
+const Version = 1
+
+const (
+	manifestFile   = "manifest.json"
+	documentsDir   = "documents"
+	attachmentsDir = "attachments"
+)
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Hosts are the hosts the backed up server is reachable under. Absolute links to them are internal links.
+	Hosts       []string             `json:"hosts"`
+	Collections []ManifestCollection `json:"collections"`
+	Attachments []ManifestAttachment `json:"attachments"`
+}
+
+// ManifestCollection is a backed up collection. All documents of its structure are part of the archive.
+type ManifestCollection struct {
+	Collection outline.Collection        `json:"collection"`
+	Structure  outline.DocumentStructure `json:"structure"`
+}
+
+// ManifestAttachment is a backed up attachment linked from at least one document.
+type ManifestAttachment struct {
+	ID          outline.AttachmentID `json:"id"`
+	ContentType string               `json:"contentType"`
+	Size        int                  `json:"size"`
+}
+
+// WriteOption configures [Write].
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	hosts []string
+}
+
+// WithHosts records the hosts e.g. wiki.example.com the server is reachable under in the manifest. Absolute links to
+// any of them are rewritten on restore just like links without host, which is how outline writes links to its own
+// documents and attachments.
+func WithHosts(hosts ...string) WriteOption {
+	return func(o *writeOptions) {
+		o.hosts = append(o.hosts, hosts...)
+	}
+}
+
+// Write writes the published documents of all collections the client has access to, along with the attachments linked
+// from them, as archive to w. Links to attachments which do not exist (anymore) are left as is. The manifest of the
+// written archive is returned.
+func Write(ctx context.Context, cl *outline.Client, w io.Writer, opts ...WriteOption) (*Manifest, error) {
+	o := writeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := &Manifest{
+		Version:     Version,
+		CreatedAt:   time.Now().UTC(),
+		Hosts:       o.hosts,
+		Collections: []ManifestCollection{},
+		Attachments: []ManifestAttachment{},
+	}
+	err := cl.Collections().List().Do(ctx, func(col *outline.Collection, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		m.Collections = append(m.Collections, ManifestCollection{Collection: *col})
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing collections: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	attachments := map[outline.AttachmentID]bool{}
+	for i := range m.Collections {
+		col := &m.Collections[i]
+		st, err := cl.Collections().DocumentStructure(col.Collection.ID).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting document structure of collection '%s': %w", col.Collection.ID, err)
+		}
+		col.Structure = st
+
+		ids := []outline.DocumentID{}
+		for _, doc := range st.Flatten() {
+			ids = append(ids, doc.ID)
+		}
+		for _, res := range cl.Documents().BatchGet(ctx, ids) {
+			if res.Err != nil {
+				return nil, fmt.Errorf("failed getting document: %w", res.Err)
+			}
+			doc := res.Value
+			if err := writeJSON(zw, documentPath(doc.ID), doc); err != nil {
+				return nil, fmt.Errorf("failed writing document '%s': %w", doc.ID, err)
+			}
+
+			for _, link := range markdown.Parse(doc.Text).Links() {
+				id, ok := link.AttachmentID()
+				if !ok || attachments[outline.AttachmentID(id)] {
+					continue
+				}
+				attachments[outline.AttachmentID(id)] = true
+
+				a, err := writeAttachment(ctx, cl, zw, outline.AttachmentID(id))
+				if err != nil {
+					return nil, fmt.Errorf("failed writing attachment '%s' of document '%s': %w", id, doc.ID, err)
+				}
+				if a != nil {
+					m.Attachments = append(m.Attachments, *a)
+				}
+			}
+		}
+	}
+
+	if err := writeJSON(zw, manifestFile, m); err != nil {
+		return nil, fmt.Errorf("failed writing manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed writing archive: %w", err)
+	}
+
+	return m, nil
+}
+
+// writeAttachment downloads the attachment identified by id into the archive. Nil is returned if there is no such
+// attachment.
+func writeAttachment(
+	ctx context.Context,
+	cl *outline.Client,
+	zw *zip.Writer,
+	id outline.AttachmentID,
+) (*ManifestAttachment, error) {
+	f, err := zw.Create(attachmentPath(id))
+	if err != nil {
+		return nil, err
+	}
+	cw := &countingWriter{w: f}
+	contentType, err := cl.Attachments().Download(id).Do(ctx, cw)
+	if outline.IsNotFound(err) {
+		// The entry was created already but is not part of the manifest hence it is ignored on restore.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ManifestAttachment{ID: id, ContentType: contentType, Size: cw.n}, nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func documentPath(id outline.DocumentID) string {
+	return path.Join(documentsDir, string(id)+".json")
+}
+
+func attachmentPath(id outline.AttachmentID) string {
+	return path.Join(attachmentsDir, string(id))
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
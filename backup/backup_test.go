@@ -0,0 +1,140 @@
+package backup_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/backup"
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+func TestWriteRestore(t *testing.T) {
+	src := outlinetest.NewServer(testApiKey)
+	defer src.Close()
+	ctx := context.Background()
+
+	ops := src.AddCollection(outline.Collection{Name: "Ops", Description: "Operations", Color: "#FF0000"})
+	db := src.AddDocument(outline.Document{CollectionID: ops.ID, Title: "DB"})
+	failover := src.AddDocument(outline.Document{CollectionID: ops.ID, ParentDocumentID: db.ID, Title: "Failover"})
+	img := src.AddAttachment("diagram.png", "image/png", []byte("png"), db.ID)
+	dev := src.AddCollection(outline.Collection{Name: "Dev"})
+	setup := src.AddDocument(outline.Document{
+		CollectionID: dev.ID,
+		Title:        "Setup",
+		Text: "See [failover](/doc/failover-" + failover.URLID + "#steps) by @[DB](mention://m1/document/" +
+			string(db.ID) + ").\n" +
+			"![diagram](" + src.URL + img + ")\n" +
+			"[absolute](https://wiki.example.com/doc/db-" + db.URLID + ") [other](https://example.com/doc/db-" +
+			db.URLID + ")\n" +
+			"[lost](/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b)",
+	})
+	// Links to documents restored later on are rewritten as well.
+	_, err := src.OutlineClient().Documents().Update(db.ID).Text("Back to [setup](/doc/setup-" + setup.URLID + ")").Do(ctx)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	srcURL, err := url.Parse(src.URL)
+	require.NoError(t, err)
+	m, err := backup.Write(ctx, src.OutlineClient(), buf, backup.WithHosts("wiki.example.com", srcURL.Hostname()))
+	require.NoError(t, err)
+	assert.Equal(t, backup.Version, m.Version)
+	assert.Equal(t, []string{"wiki.example.com", srcURL.Hostname()}, m.Hosts)
+	require.Len(t, m.Collections, 2)
+	assert.Equal(t, "Ops", m.Collections[0].Collection.Name)
+	require.Len(t, m.Collections[0].Structure, 1)
+	assert.Equal(t, failover.ID, m.Collections[0].Structure[0].Children[0].ID)
+	require.Len(t, m.Attachments, 1)
+	assert.Equal(t, backup.ManifestAttachment{ID: attachmentID(img), ContentType: "image/png", Size: 3}, m.Attachments[0])
+
+	// The archive holds every document as returned by the API.
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	f, err := zr.Open("documents/" + string(setup.ID) + ".json")
+	require.NoError(t, err)
+	doc := &outline.Document{}
+	require.NoError(t, json.NewDecoder(f).Decode(doc))
+	assert.Equal(t, setup.Text, doc.Text)
+	assert.Equal(t, setup.Revision, doc.Revision)
+
+	dst := outlinetest.NewServer(testApiKey)
+	defer dst.Close()
+	res, err := backup.Restore(ctx, dst.OutlineClient(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, res.Collections, 2)
+	require.Len(t, res.Documents, 3)
+	require.Len(t, res.Attachments, 1)
+
+	newOps, ok := dst.Collection(res.Collections[ops.ID])
+	require.True(t, ok)
+	assert.Equal(t, "Operations", newOps.Description)
+	assert.Equal(t, "#FF0000", newOps.Color)
+	st, err := dst.OutlineClient().Collections().DocumentStructure(newOps.ID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Equal(t, res.Documents[db.ID], st[0].ID)
+	require.Len(t, st[0].Children, 1)
+	assert.Equal(t, res.Documents[failover.ID], st[0].Children[0].ID)
+
+	newDB, ok := dst.Document(res.Documents[db.ID])
+	require.True(t, ok)
+	newFailover, ok := dst.Document(res.Documents[failover.ID])
+	require.True(t, ok)
+	newSetup, ok := dst.Document(res.Documents[setup.ID])
+	require.True(t, ok)
+	assert.Equal(t, "Back to [setup](/doc/setup-"+newSetup.URLID+")", newDB.Text)
+	assert.Equal(t,
+		"See [failover](/doc/failover-"+newFailover.URLID+"#steps) by @[DB](mention://m1/document/"+
+			string(newDB.ID)+").\n"+
+			"![diagram](/api/attachments.redirect?id="+string(res.Attachments[attachmentID(img)])+")\n"+
+			"[absolute](/doc/db-"+newDB.URLID+") [other](https://example.com/doc/db-"+
+			db.URLID+")\n"+
+			"[lost](/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b)",
+		newSetup.Text,
+	)
+	data, ok := dst.Attachment("/api/attachments.redirect?id=" + string(res.Attachments[attachmentID(img)]))
+	require.True(t, ok)
+	assert.Equal(t, []byte("png"), data)
+}
+
+func TestWrite_error(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	srv.InjectFault(outlinetest.Fault{Endpoint: common.CollectionsListEndpoint(), Status: http.StatusInternalServerError})
+
+	_, err := backup.Write(context.Background(), srv.OutlineClient(), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+}
+
+func TestRestore_unsupportedVersion(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	f, err := zw.Create("manifest.json")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"version": 2}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	_, err = backup.Restore(context.Background(), srv.OutlineClient(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.ErrorContains(t, err, "unsupported archive version 2")
+	assert.Zero(t, srv.RequestCount(common.CollectionsCreateEndpoint()))
+}
+
+// attachmentID returns the id of the attachment available at url.
+func attachmentID(url string) outline.AttachmentID {
+	return outline.AttachmentID(url[len(url)-36:])
+}
@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// RestoreResult maps the ids of the backed up collections, documents and attachments to the ids of their restored
+// counterparts.
+type RestoreResult struct {
+	Collections map[outline.CollectionID]outline.CollectionID `json:"collections"`
+	Documents   map[outline.DocumentID]outline.DocumentID     `json:"documents"`
+	Attachments map[outline.AttachmentID]outline.AttachmentID `json:"attachments"`
+}
+
+// Restore recreates the collections of the archive r of the given size, along with their documents and attachments,
+// using the client. The collections are always created anew hence restoring an archive twice duplicates them. The
+// hierarchy and order of the documents is kept. Links to documents and attachments of the archive, as well as mentions
+// of documents, are rewritten to point to the restored ones. All other links are left as is.
+func Restore(ctx context.Context, cl *outline.Client, r io.ReaderAt, size int64) (*RestoreResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading archive: %w", err)
+	}
+	m := &Manifest{}
+	if err := readJSON(zr, manifestFile, m); err != nil {
+		return nil, fmt.Errorf("failed reading manifest: %w", err)
+	}
+	if m.Version < 1 || m.Version > Version {
+		return nil, fmt.Errorf("unsupported archive version %d, supported up to %d", m.Version, Version)
+	}
+
+	res := &RestoreResult{
+		Collections: map[outline.CollectionID]outline.CollectionID{},
+		Documents:   map[outline.DocumentID]outline.DocumentID{},
+		Attachments: map[outline.AttachmentID]outline.AttachmentID{},
+	}
+	rs := &restorer{
+		cl:  cl,
+		zr:  zr,
+		res: res,
+		links: &outline.LinkRewriter{
+			Hosts:       m.Hosts,
+			Documents:   res.Documents,
+			URLs:        map[string]string{},
+			Attachments: res.Attachments,
+		},
+	}
+
+	for _, a := range m.Attachments {
+		if err := rs.restoreAttachment(ctx, a); err != nil {
+			return nil, fmt.Errorf("failed restoring attachment '%s': %w", a.ID, err)
+		}
+	}
+
+	// Documents are created in structure order with links to attachments rewritten. Links to documents can only be
+	// rewritten once all documents exist.
+	created := []*outline.Document{}
+	for _, col := range m.Collections {
+		docs, err := rs.restoreCollection(ctx, col)
+		if err != nil {
+			return nil, fmt.Errorf("failed restoring collection '%s': %w", col.Collection.ID, err)
+		}
+		created = append(created, docs...)
+	}
+	for _, doc := range created {
+		text := rs.links.Rewrite(doc.Text)
+		if text == doc.Text {
+			continue
+		}
+		if _, err := cl.Documents().Update(doc.ID).Text(text).Do(ctx); err != nil {
+			return nil, fmt.Errorf("failed updating links of document '%s': %w", doc.ID, err)
+		}
+	}
+
+	return rs.res, nil
+}
+
+// restorer holds the state of a single restore.
+type restorer struct {
+	cl  *outline.Client
+	zr  *zip.Reader
+	res *RestoreResult
+	// links rewrites links and mentions to point to the restored documents and attachments.
+	links *outline.LinkRewriter
+}
+
+func (rs *restorer) restoreAttachment(ctx context.Context, a ManifestAttachment) error {
+	f, err := rs.zr.Open(attachmentPath(a.ID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	name := string(a.ID) + outline.FileExtension(a.ContentType)
+	att, err := rs.cl.Attachments().Upload(name, a.ContentType, data).Do(ctx)
+	if err != nil {
+		return err
+	}
+	rs.res.Attachments[a.ID] = att.ID
+
+	return nil
+}
+
+// restoreCollection creates the collection col along with its documents and returns the created documents.
+func (rs *restorer) restoreCollection(ctx context.Context, col ManifestCollection) ([]*outline.Document, error) {
+	req := rs.cl.Collections().Create(col.Collection.Name).
+		Description(col.Collection.Description).
+		Color(col.Collection.Color).
+		Private(col.Collection.Private)
+	switch outline.Permission(col.Collection.Permission) {
+	case outline.PermissionRead:
+		req = req.PermissionRead()
+	case outline.PermissionReadWrite:
+		req = req.PermissionReadWrite()
+	}
+	restored, err := req.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating collection: %w", err)
+	}
+	rs.res.Collections[col.Collection.ID] = restored.ID
+
+	created := []*outline.Document{}
+	err = col.Structure.Walk(func(summary *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		doc := &outline.Document{}
+		if err := readJSON(rs.zr, documentPath(summary.ID), doc); err != nil {
+			return fmt.Errorf("failed reading document '%s': %w", summary.ID, err)
+		}
+
+		req := rs.cl.Documents().Create(doc.Title, restored.ID).Text(rs.links.Rewrite(doc.Text)).Publish(true)
+		if len(path) > 0 {
+			req = req.ParentDocumentID(rs.res.Documents[path[len(path)-1].ID])
+		}
+		newDoc, err := req.Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed creating document '%s': %w", doc.ID, err)
+		}
+		rs.res.Documents[doc.ID] = newDoc.ID
+		rs.links.URLs[doc.URLID] = newDoc.URL
+		created = append(created, newDoc)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func readJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
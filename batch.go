@@ -0,0 +1,111 @@
+package outline
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency is the number of requests a batch operation makes in parallel unless configured otherwise.
+const defaultBatchConcurrency = 4
+
+// BatchResult is the outcome of a single item of a batch operation. Exactly one of Value and Err is set, except for
+// operations without a value where only Err might be set.
+type BatchResult[T any] struct {
+	Value T
+	Err   error
+}
+
+// BatchOption configures a batch operation.
+type BatchOption func(*batchOptions)
+
+// batchOptions holds configuration applied by BatchOption(s).
+type batchOptions struct {
+	concurrency int
+}
+
+// WithConcurrency limits the number of requests a batch operation makes in parallel to n.
+func WithConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.concurrency = n
+	}
+}
+
+// BatchGet retrieves the documents identified by ids in parallel. The results are in the same order as ids.
+func (cl *DocumentsClient) BatchGet(
+	ctx context.Context, ids []DocumentID, opts ...BatchOption,
+) []BatchResult[*Document] {
+	return runBatch(ctx, ids, opts, func(ctx context.Context, id DocumentID) (*Document, error) {
+		return cl.Get().ByID(id).Do(ctx)
+	})
+}
+
+// BatchCreate makes the given create requests, prepared via [DocumentsClient.Create], in parallel. The results are in
+// the same order as creates. NOTE: Documents depending on each other, like a parent and its child, must be created in
+// separate batches.
+func (cl *DocumentsClient) BatchCreate(
+	ctx context.Context, creates []*DocumentsCreateClient, opts ...BatchOption,
+) []BatchResult[*Document] {
+	return runBatch(ctx, creates, opts, func(ctx context.Context, c *DocumentsCreateClient) (*Document, error) {
+		return c.Do(ctx)
+	})
+}
+
+// BatchUpdate makes the given update requests, prepared via [DocumentsClient.Update], in parallel. The results are in
+// the same order as updates.
+func (cl *DocumentsClient) BatchUpdate(
+	ctx context.Context, updates []*DocumentsUpdateClient, opts ...BatchOption,
+) []BatchResult[*Document] {
+	return runBatch(ctx, updates, opts, func(ctx context.Context, u *DocumentsUpdateClient) (*Document, error) {
+		return u.Do(ctx)
+	})
+}
+
+// BatchDelete deletes the documents identified by ids in parallel. The results are in the same order as ids and
+// only carry errors.
+func (cl *DocumentsClient) BatchDelete(
+	ctx context.Context, ids []DocumentID, opts ...BatchOption,
+) []BatchResult[struct{}] {
+	return runBatch(ctx, ids, opts, func(ctx context.Context, id DocumentID) (struct{}, error) {
+		return struct{}{}, cl.Delete(id).Do(ctx)
+	})
+}
+
+// runBatch calls fn for every item using a bounded pool of workers and returns the results in input order. Once ctx is
+// done no new items are started and the remaining ones fail with the context's error.
+func runBatch[T any, R any](
+	ctx context.Context, items []T, opts []BatchOption, fn func(context.Context, T) (R, error),
+) []BatchResult[R] {
+	o := &batchOptions{concurrency: defaultBatchConcurrency}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.concurrency < 1 {
+		o.concurrency = 1
+	}
+
+	results := make([]BatchResult[R], len(items))
+	indexes := make(chan int)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(o.concurrency, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Value, results[i].Err = fn(ctx, items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,489 @@
+package outline
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+)
+
+// Cache stores responses of idempotent read calls. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key and true, or false if there is no (unexpired) value.
+	Get(key string) ([]byte, bool)
+	// Set stores value for key. The value must not be returned after ttl has passed.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored for key, if any.
+	Delete(key string)
+}
+
+// WithCache caches successful responses of the collections.info, collections.documents, collections.list and
+// documents.info endpoints in c for ttl. A nil c means an in-memory LRU cache holding up to 1000 responses.
+// Successful changes made via the client e.g. creating, updating, moving or deleting documents remove the cached
+// responses of the changed resources right away. Note that this bookkeeping is local to the client: a cache shared
+// between processes only learns about changes made elsewhere once ttl has passed. Single calls can skip the cache via
+// [NoCache].
+//
+// Cached responses are only served to clients with the same credentials. The token of a client created via
+// [NewWithTokenSource] is not known before the request is sent hence such a client only shares cached responses with
+// itself.
+func WithCache(c Cache, ttl time.Duration) Option {
+	return func(o *options) {
+		if c == nil {
+			c = NewMemoryCache(1000)
+		}
+		o.cache = newResponseCache(c, ttl)
+	}
+}
+
+type noCacheKey struct{}
+
+// NoCache returns a copy of ctx which makes calls bypass the cache configured via [WithCache]. Such calls always hit
+// the server but their successful responses still refresh the cache.
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// responseCache is the middleware which serves cacheable calls from a Cache and invalidates cached responses on
+// updates.
+type responseCache struct {
+	c   Cache
+	ttl time.Duration
+	// id identifies the client the cache belongs to. It stands in for the credentials if they are not part of the
+	// request.
+	id string
+
+	mu      sync.Mutex
+	tags    map[string]map[string]struct{} // cache keys per tag e.g. "structure:<collection id>"
+	keys    map[string]*cachedKey          // tags per cache key
+	sweepAt int                            // number of keys at which expired keys are dropped
+	// Responses read while their resources are invalidated must not be stored as they might predate the change. gen
+	// is incremented by every invalidation and invalidated records the generation at which a tag, or all tags with
+	// a prefix e.g. "structure:*", was last invalidated. It is only kept while there are reads in flight.
+	gen         uint64
+	invalidated map[string]uint64
+	reading     int
+}
+
+// cachedKey is the bookkeeping of a cache key.
+type cachedKey struct {
+	tags    []string
+	expires time.Time
+}
+
+func newResponseCache(c Cache, ttl time.Duration) *responseCache {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("failed generating cache id: %v", err))
+	}
+	return &responseCache{
+		c:           c,
+		ttl:         ttl,
+		id:          hex.EncodeToString(id),
+		tags:        map[string]map[string]struct{}{},
+		keys:        map[string]*cachedKey{},
+		sweepAt:     1000,
+		invalidated: map[string]uint64{},
+	}
+}
+
+// cacheable are the endpoints whose responses are cached.
+var cacheable = map[string]bool{
+	common.CollectionsGetEndpoint():       true,
+	common.CollectionsStructureEndpoint(): true,
+	common.CollectionsListEndpoint():      true,
+	common.DocumentsGetEndpoint():         true,
+}
+
+// cachedResource is the part of a response needed to find out which resource it belongs to.
+type cachedResource struct {
+	Data struct {
+		ID           string `json:"id"`
+		CollectionID string `json:"collectionId"`
+	} `json:"data"`
+}
+
+// middleware returns the cache as a Middleware.
+func (rc *responseCache) middleware(next Invoker) Invoker {
+	return func(call *Call) *CallResult {
+		switch {
+		case cacheable[call.Endpoint]:
+			return rc.read(call, next)
+		case call.Endpoint == common.DocumentsCreateEndpoint() || call.Endpoint == common.DocumentsUpdateEndpoint() ||
+			call.Endpoint == common.DocumentsArchiveEndpoint() || call.Endpoint == common.CollectionsCreateEndpoint() ||
+			call.Endpoint == common.CollectionsUpdateEndpoint():
+			return rc.write(call, next)
+		case call.Endpoint == common.DocumentsMoveEndpoint():
+			return rc.move(call, next)
+		case call.Endpoint == common.DocumentsDeleteEndpoint():
+			return rc.delete(call, next)
+		default:
+			return next(call)
+		}
+	}
+}
+
+func (rc *responseCache) read(call *Call, next Invoker) *CallResult {
+	key := rc.key(call)
+	if bypass, _ := call.Request.Context().Value(noCacheKey{}).(bool); !bypass {
+		if body, ok := rc.c.Get(key); ok {
+			return &CallResult{Response: cachedResponse(call.Request, body), Status: http.StatusOK}
+		}
+		// The cache may have evicted the response on its own hence its tags are gone as well.
+		rc.untag(key)
+	}
+
+	gen := rc.startRead()
+	res := next(call)
+	if res.Response == nil || res.Status != http.StatusOK {
+		rc.endRead(gen, key, nil)
+		return res
+	}
+	body, err := peekBody(res.Response)
+	if err != nil {
+		rc.endRead(gen, key, nil)
+		return &CallResult{Status: res.Status, Duration: res.Duration, Err: err}
+	}
+	rc.endRead(gen, key, body, rc.tagsOf(call, body)...)
+
+	return res
+}
+
+func (rc *responseCache) write(call *Call, next Invoker) *CallResult {
+	res := next(call)
+	if res.Response == nil || res.Status != http.StatusOK {
+		return res
+	}
+	body, err := peekBody(res.Response)
+	if err != nil {
+		return &CallResult{Status: res.Status, Duration: res.Duration, Err: err}
+	}
+
+	r := cachedResource{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return res
+	}
+	if call.Endpoint == common.CollectionsCreateEndpoint() || call.Endpoint == common.CollectionsUpdateEndpoint() {
+		// The collection is part of any collections list.
+		rc.invalidate("collection:"+r.Data.ID, "collections")
+	} else {
+		// The document is part of its collection's structure.
+		rc.invalidate("document:"+r.Data.ID, "structure:"+r.Data.CollectionID)
+	}
+
+	return res
+}
+
+// move invalidates the moved documents. The collections the documents were moved from are not part of the response
+// hence all structures are invalidated.
+func (rc *responseCache) move(call *Call, next Invoker) *CallResult {
+	res := next(call)
+	if res.Response == nil || res.Status != http.StatusOK {
+		return res
+	}
+	body, err := peekBody(res.Response)
+	if err != nil {
+		return &CallResult{Status: res.Status, Duration: res.Duration, Err: err}
+	}
+
+	r := struct {
+		Data struct {
+			Documents []struct {
+				ID string `json:"id"`
+			} `json:"documents"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return res
+	}
+	tags := []string{"structure:*"}
+	for _, doc := range r.Data.Documents {
+		tags = append(tags, "document:"+doc.ID)
+	}
+	rc.invalidate(tags...)
+
+	return res
+}
+
+// delete invalidates the deleted document. The response does not tell which collection the document was part of hence
+// all structures are invalidated.
+func (rc *responseCache) delete(call *Call, next Invoker) *CallResult {
+	res := next(call)
+	if res.Response == nil || res.Status != http.StatusOK {
+		return res
+	}
+
+	params := struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(call.Body, &params); err != nil {
+		return res
+	}
+	rc.invalidate("structure:*", "document:"+params.ID)
+
+	return res
+}
+
+// tagsOf returns the tags of the resources a cacheable response belongs to.
+func (rc *responseCache) tagsOf(call *Call, body []byte) []string {
+	if call.Endpoint == common.CollectionsListEndpoint() {
+		return []string{"collections"}
+	}
+
+	if call.Endpoint == common.CollectionsStructureEndpoint() {
+		// The structure response does not contain the collection id hence take it from the request.
+		params := struct {
+			ID string `json:"id"`
+		}{}
+		if err := json.Unmarshal(call.Body, &params); err != nil || params.ID == "" {
+			return nil
+		}
+		return []string{"structure:" + params.ID}
+	}
+
+	// Documents can be fetched by id, url id or share id hence always use the id from the response.
+	r := cachedResource{}
+	if err := json.Unmarshal(body, &r); err != nil || r.Data.ID == "" {
+		return nil
+	}
+	if call.Endpoint == common.DocumentsGetEndpoint() {
+		return []string{"document:" + r.Data.ID}
+	}
+	return []string{"collection:" + r.Data.ID}
+}
+
+// startRead registers a read in flight and returns the current generation to be passed to endRead.
+func (rc *responseCache) startRead() uint64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.reading++
+	return rc.gen
+}
+
+// endRead ends a read started at generation gen. The response body is stored for key unless body is nil or any of the
+// tags were invalidated since gen.
+func (rc *responseCache) endRead(gen uint64, key string, body []byte, tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if body != nil && !rc.invalidatedSince(gen, tags) {
+		rc.c.Set(key, body, rc.ttl)
+		rc.tag(key, tags...)
+	}
+	if rc.reading--; rc.reading == 0 {
+		clear(rc.invalidated)
+	}
+}
+
+// invalidatedSince returns true if any of tags was invalidated after generation gen. rc.mu must be held.
+func (rc *responseCache) invalidatedSince(gen uint64, tags []string) bool {
+	for _, t := range tags {
+		if rc.invalidated[t] > gen {
+			return true
+		}
+		if prefix, _, ok := strings.Cut(t, ":"); ok && rc.invalidated[prefix+":*"] > gen {
+			return true
+		}
+	}
+	return false
+}
+
+// tag records the tags of key. rc.mu must be held.
+func (rc *responseCache) tag(key string, tags ...string) {
+	rc.removeKey(key)
+	for _, t := range tags {
+		if rc.tags[t] == nil {
+			rc.tags[t] = map[string]struct{}{}
+		}
+		rc.tags[t][key] = struct{}{}
+	}
+	rc.keys[key] = &cachedKey{tags: tags, expires: time.Now().Add(rc.ttl)}
+
+	// Keys which are never read again are only found missing once they expired.
+	if len(rc.keys) >= rc.sweepAt {
+		now := time.Now()
+		for key, k := range rc.keys {
+			if !now.Before(k.expires) {
+				rc.removeKey(key)
+			}
+		}
+		rc.sweepAt = max(2*len(rc.keys), 1000)
+	}
+}
+
+// untag drops the tags of key.
+func (rc *responseCache) untag(key string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.removeKey(key)
+}
+
+// invalidate removes the cached responses with any of tags. A tag ending in ":*" stands for all tags with that prefix.
+func (rc *responseCache) invalidate(tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.gen++
+	for _, t := range tags {
+		if rc.reading > 0 {
+			rc.invalidated[t] = rc.gen
+		}
+		matched := []string{t}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			matched = matched[:0]
+			for tag := range rc.tags {
+				if strings.HasPrefix(tag, prefix) {
+					matched = append(matched, tag)
+				}
+			}
+		}
+		for _, tag := range matched {
+			for key := range rc.tags[tag] {
+				rc.c.Delete(key)
+				rc.removeKey(key)
+			}
+		}
+	}
+}
+
+// removeKey drops the bookkeeping of key. rc.mu must be held.
+func (rc *responseCache) removeKey(key string) {
+	k, ok := rc.keys[key]
+	if !ok {
+		return
+	}
+	for _, t := range k.tags {
+		delete(rc.tags[t], key)
+		if len(rc.tags[t]) == 0 {
+			delete(rc.tags, t)
+		}
+	}
+	delete(rc.keys, key)
+}
+
+// key identifies a call by everything which can influence its response: the server, the credentials, the endpoint,
+// the query and the body. If the credentials are not part of the request the id of the client is used instead.
+func (rc *responseCache) key(call *Call) string {
+	credentials := call.Request.Header.Get(common.HdrKeyAuthorization)
+	if credentials == "" {
+		credentials = "client " + rc.id
+	}
+
+	h := sha256.New()
+	for _, s := range []string{
+		call.Request.URL.Host,
+		credentials,
+		call.Request.URL.Path,
+		call.Request.URL.RawQuery,
+	} {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+	h.Write(call.Body)
+
+	return call.Endpoint + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// peekBody reads the whole body of resp and replaces it with an unread copy.
+func peekBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func cachedResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{common.HdrKeyContentType: []string{common.HdrValueContentType}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// MemoryCache is an in-memory [Cache] which evicts the least recently used value once it is full.
+type MemoryCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding up to size values.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{size: max(size, 1), order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryCacheEntry)
+	if !time.Now().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+
+	return e.value, true
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *MemoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,134 @@
+package outline
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+
+	// Reading a makes b the least recently used value which is evicted first.
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	c.Set("c", []byte("3"), time.Minute)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	// Expired values are never returned.
+	c.Set("d", []byte("4"), -time.Second)
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+}
+
+func TestResponseCache_prunesTags(t *testing.T) {
+	rc := newResponseCache(NewMemoryCache(1), time.Minute)
+	status := http.StatusOK
+	next := func(call *Call) *CallResult {
+		body := fmt.Sprintf(`{"data": {"id": %q, "collectionId": "col1"}}`, call.Body)
+		return &CallResult{Response: &http.Response{Body: io.NopCloser(strings.NewReader(body))}, Status: status}
+	}
+	get := func(id string) {
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/api/documents.info", nil)
+		require.NoError(t, err)
+		rc.middleware(next)(&Call{Endpoint: common.DocumentsGetEndpoint(), Body: []byte(id), Request: req})
+	}
+
+	// Reading doc2 evicts doc1 from the cache. Its tags are dropped once it is found missing.
+	get("doc1")
+	get("doc2")
+	assert.Len(t, rc.keys, 2)
+	status = http.StatusNotFound
+	get("doc1")
+	assert.Len(t, rc.keys, 1)
+	assert.Equal(t, []string{"document:doc2"}, keys(rc.tags))
+
+	// Expired keys are dropped once there are too many.
+	status = http.StatusOK
+	rc.ttl = -time.Second
+	rc.sweepAt = 3
+	get("doc3")
+	get("doc4")
+	assert.Len(t, rc.keys, 1)
+	assert.Equal(t, []string{"document:doc2"}, keys(rc.tags))
+}
+
+func TestResponseCache_skipsResponsesInvalidatedWhileReading(t *testing.T) {
+	tests := map[string]struct {
+		endpoint string
+		body     string
+		// change is the call made while the read is in flight.
+		change string
+	}{
+		"document updated": {
+			endpoint: common.DocumentsGetEndpoint(),
+			body:     `{"id": "doc1"}`,
+			change:   common.DocumentsUpdateEndpoint(),
+		},
+		"document deleted": {
+			endpoint: common.CollectionsStructureEndpoint(),
+			body:     `{"id": "col1"}`,
+			change:   common.DocumentsDeleteEndpoint(),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rc := newResponseCache(NewMemoryCache(10), time.Minute)
+			var invoke Invoker
+			calls := 0
+			next := func(call *Call) *CallResult {
+				if call.Endpoint == tt.endpoint {
+					calls++
+					if calls == 1 {
+						// The change completes before the response predating it is received.
+						invoke(newTestCall(t, tt.change, `{"id": "doc1"}`))
+					}
+				}
+				body := `{"data": {"id": "doc1", "collectionId": "col1"}}`
+				return &CallResult{Response: &http.Response{Body: io.NopCloser(strings.NewReader(body))}, Status: http.StatusOK}
+			}
+			invoke = rc.middleware(next)
+
+			invoke(newTestCall(t, tt.endpoint, tt.body))
+			assert.Empty(t, rc.keys)
+			assert.Empty(t, rc.invalidated)
+
+			// The next read is stored as usual.
+			invoke(newTestCall(t, tt.endpoint, tt.body))
+			invoke(newTestCall(t, tt.endpoint, tt.body))
+			assert.Equal(t, 2, calls)
+			assert.Len(t, rc.keys, 1)
+		})
+	}
+}
+
+func newTestCall(t *testing.T, endpoint, body string) *Call {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/api/"+endpoint, nil)
+	require.NoError(t, err)
+	return &Call{Endpoint: endpoint, Body: []byte(body), Request: req}
+}
+
+func keys[V any](m map[string]V) []string {
+	ks := []string{}
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
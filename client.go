@@ -1,34 +1,156 @@
 package outline
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
-	"github.com/rsjethani/rsling"
 	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/rsjethani/rsling"
 )
 
 // Client is per server top level client which acts as entry point and stores common configuration (like base url) for
 // resource level clients. It is preferred to reuse same client while communicating to the same server as this makes
-// better utilization of resources.
+// better utilization of resources. The request builders returned by resource level clients are immutable i.e. every
+// setter returns a modified copy and leaves the builder it was called on as is. Hence a client as well as a builder can
+// be shared across goroutines and its Do method called as often as needed.
 type Client struct {
 	// base acts as the 'base' request on which various common properties like HTTP headers, server url etc. are
 	// configured. The resource level clients create their own customized request derived from this.
 	base *rsling.Sling
+	// server is the base url of the API of the server.
+	server *url.URL
+	// storage is the HTTP client given by the caller, without any authorization, used for requests to other hosts than
+	// the server e.g. uploads to the storage of attachments.
+	storage *http.Client
 }
 
-// New creates and returns a new (per server) client.
-func New(serverURL string, hc *http.Client, apiKey string) *Client {
-	sl := rsling.New().Client(hc).Base(common.BaseURL(serverURL))
+// New creates and returns a new (per server) client. Optional behaviour can be configured via opts.
+func New(serverURL string, hc *http.Client, apiKey string, opts ...Option) *Client {
+	sl := newBase(serverURL, hc, opts)
 	sl.Set(common.HdrKeyAuthorization, common.HdrValueAuthorization(apiKey))
+
+	return newClient(serverURL, sl, hc)
+}
+
+// newClient creates a client using the base request sl for calls to the server and hc for calls to other hosts.
+func newClient(serverURL string, sl *rsling.Sling, hc *http.Client) *Client {
+	if hc == nil {
+		hc = &http.Client{}
+	}
+	server, err := url.Parse(common.BaseURL(serverURL))
+	if err != nil {
+		server = &url.URL{}
+	}
+
+	return &Client{base: sl, server: server, storage: hc}
+}
+
+// TokenSource supplies the token used for authorizing requests. Unlike a static API key the token can change over the
+// lifetime of a client e.g. an OAuth access token which gets refreshed once expired.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// NewWithTokenSource creates and returns a new (per server) client which authorizes every request to the server with a
+// token obtained from ts at the time the request is made. Requests to other hosts e.g. the storage an attachment
+// download is redirected to are sent without token. The given hc is not modified. Optional behaviour can be configured
+// via opts.
+func NewWithTokenSource(serverURL string, hc *http.Client, ts TokenSource, opts ...Option) *Client {
+	if hc == nil {
+		hc = &http.Client{}
+	}
+	host := ""
+	if u, err := url.Parse(serverURL); err == nil {
+		host = u.Host
+	}
+	copy := *hc
+	copy.Transport = &tokenTransport{base: hc.Transport, ts: ts, host: host}
+
+	return newClient(serverURL, newBase(serverURL, &copy, opts), hc)
+}
+
+// newBase creates the base request with all common properties except authorization configured.
+func newBase(serverURL string, hc *http.Client, opts []Option) *rsling.Sling {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// The given client is copied as its redirect policy is extended.
+	copy := http.Client{}
+	if hc != nil {
+		copy = *hc
+	}
+	copy.CheckRedirect = checkRedirect(copy.CheckRedirect)
+	hc = &copy
+
+	sl := rsling.New().Client(hc).Base(common.BaseURL(serverURL))
+	if mws := o.chain(); len(mws) > 0 {
+		sl.Doer(newCallDoer(hc, mws))
+	}
 	sl.Set(common.HdrKeyContentType, common.HdrValueContentType)
 	sl.Set(common.HdrKeyAccept, common.HdrValueAccept)
 
-	return &Client{base: sl}
+	return sl
+}
+
+type noRedirectKey struct{}
+
+// checkRedirect returns a redirect policy which stops at the first redirect of requests made with a context marked via
+// noRedirectKey. All other requests are subject to policy, or the default policy of [http.Client] if nil.
+func checkRedirect(policy func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if stop, _ := req.Context().Value(noRedirectKey{}).(bool); stop {
+			return http.ErrUseLastResponse
+		}
+		if policy != nil {
+			return policy(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// tokenTransport is an [http.RoundTripper] which sets authorization header of every request to host using a token
+// from ts before handing the request over to base.
+type tokenTransport struct {
+	base http.RoundTripper
+	ts   TokenSource
+	// host is the host, including the port if any, of the server the token is meant for.
+	host string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// The token must not leak to other hosts e.g. when following a redirect to the storage of attachments.
+	if !strings.EqualFold(req.URL.Host, t.host) {
+		return base.RoundTrip(req)
+	}
+
+	token, err := t.ts.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed getting token: %w", err)
+	}
+
+	// A round tripper must not modify the original request hence we work on a clone.
+	clone := req.Clone(req.Context())
+	clone.Header.Set(common.HdrKeyAuthorization, common.HdrValueAuthorization(token))
+
+	return base.RoundTrip(clone)
 }
 
 // Attachments creates a client for operating on attachments.
 func (cl *Client) Attachments() *AttachmentsClient {
-	return newAttachmentsClient(cl.base)
+	return newAttachmentsClient(cl.base, cl.server, cl.storage)
 }
 
 // Documents creates a client for operating on documents.
@@ -40,3 +162,23 @@ func (cl *Client) Documents() *DocumentsClient {
 func (cl *Client) Collections() *CollectionsClient {
 	return newCollectionsClient(cl.base)
 }
+
+// Views creates a client for operating on document views.
+func (cl *Client) Views() *ViewsClient {
+	return newViewsClient(cl.base)
+}
+
+// Users creates a client for operating on users.
+func (cl *Client) Users() *UsersClient {
+	return newUsersClient(cl.base)
+}
+
+// APIKeys creates a client for operating on API keys.
+func (cl *Client) APIKeys() *APIKeysClient {
+	return newAPIKeysClient(cl.base)
+}
+
+// OAuthClients creates a client for operating on OAuth clients.
+func (cl *Client) OAuthClients() *OAuthClientsClient {
+	return newOAuthClientsClient(cl.base)
+}
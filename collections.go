@@ -43,6 +43,24 @@ func (cl *CollectionsClient) Update(id CollectionID) *CollectionsUpdateClient {
 	return newCollectionsUpdateClient(cl.sl, id)
 }
 
+// Memberships returns a client for listing users that were given access to the collection identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Collections/paths/~1collections.memberships/post
+func (cl *CollectionsClient) Memberships(id CollectionID) *CollectionsMembershipsClient {
+	return newCollectionsMembershipsClient(cl.sl, id)
+}
+
+// AddUser returns a client for giving the user identified by userID access to the collection identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Collections/paths/~1collections.add_user/post
+func (cl *CollectionsClient) AddUser(id CollectionID, userID UserID, permission Permission) *CollectionsAddUserClient {
+	return newCollectionsAddUserClient(cl.sl, id, userID, permission)
+}
+
+// GroupMemberships returns a client for listing groups that were given access to the collection identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Collections/paths/~1collections.group_memberships/post
+func (cl *CollectionsClient) GroupMemberships(id CollectionID) *CollectionsGroupMembershipsClient {
+	return newCollectionsGroupMembershipsClient(cl.sl, id)
+}
+
 type CollectionsDocumentStructureClient struct {
 	sl *rsling.Sling
 }
@@ -125,8 +143,8 @@ type CollectionsListFn func(*Collection, error) (bool, error)
 
 // Do makes the actual request for listing all collections. If the request is successful then fn is called sequentially
 // with every collection received. But if there is some error/bad response then fn is called with the error. If fn
-// returns false then the whole process is aborted otherwise the request is retried. NOTE: Policies if any returned are
-// ignored as of now. Later if we find them important then we can include them too.
+// returns false then the whole process is aborted otherwise the request is retried. Policies returned along with the
+// collections can be collected via [CollectPolicies].
 func (cl *CollectionsListClient) Do(ctx context.Context, fn CollectionsListFn) error {
 	success := &struct {
 		Data       []*Collection `json:"data"`
@@ -194,39 +212,44 @@ func newCollectionsCreateClient(sl *rsling.Sling, name string) *CollectionsCreat
 }
 
 func (cl *CollectionsCreateClient) Description(desc string) *CollectionsCreateClient {
-	cl.params.Description = desc
-	return cl
+	c := *cl
+	c.params.Description = desc
+	return &c
 }
 
 func (cl *CollectionsCreateClient) PermissionRead() *CollectionsCreateClient {
-	cl.params.Permission = "read"
-	return cl
+	c := *cl
+	c.params.Permission = "read"
+	return &c
 }
 
 func (cl *CollectionsCreateClient) PermissionReadWrite() *CollectionsCreateClient {
-	cl.params.Permission = "read_write"
-	return cl
+	c := *cl
+	c.params.Permission = "read_write"
+	return &c
 }
 
 func (cl *CollectionsCreateClient) Color(color string) *CollectionsCreateClient {
-	cl.params.Color = color
-	return cl
+	c := *cl
+	c.params.Color = color
+	return &c
 }
 
 func (cl *CollectionsCreateClient) Private(private bool) *CollectionsCreateClient {
-	cl.params.Private = private
-	return cl
+	c := *cl
+	c.params.Private = private
+	return &c
 }
 
 // Do make the actual request to create a collection.
 func (cl *CollectionsCreateClient) Do(ctx context.Context) (*Collection, error) {
-	cl.sl.Post(common.CollectionsCreateEndpoint()).BodyJSON(&cl.params)
+	req := cl.sl.New().Post(common.CollectionsCreateEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Collection `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -237,13 +260,14 @@ func (cl *CollectionsCreateClient) Do(ctx context.Context) (*Collection, error)
 	return success.Data, nil
 }
 
-// collectionsUpdateParams represents the Outline Collections.update parameters
+// collectionsUpdateParams represents the Outline Collections.update parameters. Only parameters which were set are
+// sent hence all other properties of the collection are left as is.
 type collectionsUpdateParams struct {
-	ID          CollectionID `json:"id"`
-	Name        string       `json:"name"`
-	Permission  string       `json:"permission,omitempty"`
-	Description string       `json:"description,omitempty"`
-	Color       string       `json:"color,omitempty"`
+	ID          CollectionID      `json:"id"`
+	Name        *optional[string] `json:"name,omitempty"`
+	Permission  *optional[string] `json:"permission,omitempty"`
+	Description *optional[string] `json:"description,omitempty"`
+	Color       *optional[string] `json:"color,omitempty"`
 }
 
 type CollectionsUpdateClient struct {
@@ -258,39 +282,52 @@ func newCollectionsUpdateClient(sl *rsling.Sling, id CollectionID) *CollectionsU
 }
 
 func (cl *CollectionsUpdateClient) Name(name string) *CollectionsUpdateClient {
-	cl.params.Name = name
-	return cl
+	c := *cl
+	c.params.Name = some(name)
+	return &c
 }
 
 func (cl *CollectionsUpdateClient) PermissionRead() *CollectionsUpdateClient {
-	cl.params.Permission = "read"
-	return cl
+	c := *cl
+	c.params.Permission = some("read")
+	return &c
 }
 
 func (cl *CollectionsUpdateClient) PermissionReadWrite() *CollectionsUpdateClient {
-	cl.params.Permission = "read_write"
-	return cl
+	c := *cl
+	c.params.Permission = some("read_write")
+	return &c
 }
 
 func (cl *CollectionsUpdateClient) Color(color string) *CollectionsUpdateClient {
-	cl.params.Color = color
-	return cl
+	c := *cl
+	c.params.Color = some(color)
+	return &c
+}
+
+// ClearColor removes the color of the collection so that the default color is used.
+func (cl *CollectionsUpdateClient) ClearColor() *CollectionsUpdateClient {
+	c := *cl
+	c.params.Color = null[string]()
+	return &c
 }
 
+// Description sets the description of the collection. An empty desc clears the description.
 func (cl *CollectionsUpdateClient) Description(desc string) *CollectionsUpdateClient {
-	cl.params.Description = desc
-	return cl
+	c := *cl
+	c.params.Description = some(desc)
+	return &c
 }
 
 // Do makes the actual request for updating the collection.
 func (cl *CollectionsUpdateClient) Do(ctx context.Context) (*Collection, error) {
-	cl.sl.Post(common.CollectionsUpdateEndpoint()).BodyJSON(&cl.params)
+	req := cl.sl.New().Post(common.CollectionsUpdateEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Collection `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -300,3 +337,248 @@ func (cl *CollectionsUpdateClient) Do(ctx context.Context) (*Collection, error)
 
 	return success.Data, nil
 }
+
+// collectionMemberships is the data returned by the server for membership related requests. The users are returned
+// separately from memberships hence join fills in the user of every membership.
+type collectionMemberships struct {
+	Users       []User                 `json:"users"`
+	Memberships []CollectionMembership `json:"memberships"`
+}
+
+func (cm *collectionMemberships) join() []CollectionMembership {
+	users := make(map[UserID]User, len(cm.Users))
+	for _, u := range cm.Users {
+		users[UserID(u.ID)] = u
+	}
+
+	memberships := make([]CollectionMembership, 0, len(cm.Memberships))
+	for _, m := range cm.Memberships {
+		if u, ok := users[m.UserID]; ok {
+			m.User = &u
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships
+}
+
+// collectionsMembershipsParams represents the Outline Collections.memberships and Collections.group_memberships
+// parameters
+type collectionsMembershipsParams struct {
+	ID         CollectionID `json:"id"`
+	Query      string       `json:"query,omitempty"`
+	Permission Permission   `json:"permission,omitempty"`
+}
+
+// CollectionsMembershipsClient is a client for listing user memberships of a collection.
+type CollectionsMembershipsClient struct {
+	sl     *rsling.Sling
+	params collectionsMembershipsParams
+}
+
+func newCollectionsMembershipsClient(sl *rsling.Sling, id CollectionID) *CollectionsMembershipsClient {
+	copy := sl.New()
+	params := collectionsMembershipsParams{ID: id}
+	return &CollectionsMembershipsClient{sl: copy, params: params}
+}
+
+// Query selects only memberships of users whose name matches query.
+func (cl *CollectionsMembershipsClient) Query(query string) *CollectionsMembershipsClient {
+	c := *cl
+	c.params.Query = query
+	return &c
+}
+
+// Permission selects only memberships with the given permission.
+func (cl *CollectionsMembershipsClient) Permission(permission Permission) *CollectionsMembershipsClient {
+	c := *cl
+	c.params.Permission = permission
+	return &c
+}
+
+// CollectionsMembershipsFn is the type of function called by [CollectionsMembershipsClient.Do] for every membership it
+// finds.
+type CollectionsMembershipsFn func(*CollectionMembership, error) (bool, error)
+
+// Do makes the actual request for listing memberships. If the request is successful then fn is called sequentially
+// with every membership received. But if there is some error/bad response then fn is called with the error. If fn
+// returns false then the whole process is aborted otherwise the request is retried.
+func (cl *CollectionsMembershipsClient) Do(ctx context.Context, fn CollectionsMembershipsFn) error {
+	params := &paginationQueryParams{}
+	for {
+		success := &struct {
+			Data       collectionMemberships `json:"data"`
+			Pagination pagination            `json:"pagination"`
+		}{}
+
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.CollectionsMembershipsEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		memberships := success.Data.join()
+		for i := range memberships {
+			if ok, e := fn(&memberships[i], nil); !ok {
+				return e
+			}
+		}
+
+		if len(memberships) <= 1 {
+			return nil
+		}
+		params.Offset += len(memberships)
+	}
+}
+
+// collectionsAddUserParams represents the Outline Collections.add_user parameters
+type collectionsAddUserParams struct {
+	ID         CollectionID `json:"id"`
+	UserID     UserID       `json:"userId"`
+	Permission Permission   `json:"permission,omitempty"`
+}
+
+// CollectionsAddUserClient is a client for giving a single user access to a collection.
+type CollectionsAddUserClient struct {
+	sl     *rsling.Sling
+	params collectionsAddUserParams
+}
+
+func newCollectionsAddUserClient(
+	sl *rsling.Sling, id CollectionID, userID UserID, permission Permission,
+) *CollectionsAddUserClient {
+	copy := sl.New()
+	params := collectionsAddUserParams{ID: id, UserID: userID, Permission: permission}
+	return &CollectionsAddUserClient{sl: copy, params: params}
+}
+
+// Do makes the actual request to add the user and returns the resulting membership.
+func (cl *CollectionsAddUserClient) Do(ctx context.Context) (*CollectionMembership, error) {
+	req := cl.sl.New().Post(common.CollectionsAddUserEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data collectionMemberships `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	memberships := success.Data.join()
+	if len(memberships) == 0 {
+		return nil, fmt.Errorf("no membership returned for user '%s'", cl.params.UserID)
+	}
+
+	return &memberships[0], nil
+}
+
+// collectionGroupMemberships is the data returned by the server for group membership related requests. The groups are
+// returned separately from memberships hence join fills in the group of every membership.
+type collectionGroupMemberships struct {
+	Groups      []Group                     `json:"groups"`
+	Memberships []CollectionGroupMembership `json:"groupMemberships"`
+}
+
+func (cm *collectionGroupMemberships) join() []CollectionGroupMembership {
+	groups := make(map[GroupID]Group, len(cm.Groups))
+	for _, g := range cm.Groups {
+		groups[g.ID] = g
+	}
+
+	memberships := make([]CollectionGroupMembership, 0, len(cm.Memberships))
+	for _, m := range cm.Memberships {
+		if g, ok := groups[m.GroupID]; ok {
+			m.Group = &g
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships
+}
+
+// CollectionsGroupMembershipsClient is a client for listing group memberships of a collection.
+type CollectionsGroupMembershipsClient struct {
+	sl     *rsling.Sling
+	params collectionsMembershipsParams
+}
+
+func newCollectionsGroupMembershipsClient(sl *rsling.Sling, id CollectionID) *CollectionsGroupMembershipsClient {
+	copy := sl.New()
+	params := collectionsMembershipsParams{ID: id}
+	return &CollectionsGroupMembershipsClient{sl: copy, params: params}
+}
+
+// Query selects only memberships of groups whose name matches query.
+func (cl *CollectionsGroupMembershipsClient) Query(query string) *CollectionsGroupMembershipsClient {
+	c := *cl
+	c.params.Query = query
+	return &c
+}
+
+// Permission selects only memberships with the given permission.
+func (cl *CollectionsGroupMembershipsClient) Permission(permission Permission) *CollectionsGroupMembershipsClient {
+	c := *cl
+	c.params.Permission = permission
+	return &c
+}
+
+// CollectionsGroupMembershipsFn is the type of function called by [CollectionsGroupMembershipsClient.Do] for every
+// membership it finds.
+type CollectionsGroupMembershipsFn func(*CollectionGroupMembership, error) (bool, error)
+
+// Do makes the actual request for listing group memberships. If the request is successful then fn is called
+// sequentially with every membership received. But if there is some error/bad response then fn is called with the
+// error. If fn returns false then the whole process is aborted otherwise the request is retried.
+func (cl *CollectionsGroupMembershipsClient) Do(ctx context.Context, fn CollectionsGroupMembershipsFn) error {
+	params := &paginationQueryParams{}
+	for {
+		success := &struct {
+			Data       collectionGroupMemberships `json:"data"`
+			Pagination pagination                 `json:"pagination"`
+		}{}
+
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.CollectionsGroupMembershipsEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		memberships := success.Data.join()
+		for i := range memberships {
+			if ok, e := fn(&memberships[i], nil); !ok {
+				return e
+			}
+		}
+
+		if len(memberships) <= 1 {
+			return nil
+		}
+		params.Offset += len(memberships)
+	}
+}
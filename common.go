@@ -3,8 +3,10 @@ package outline
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/rsjethani/rsling"
@@ -20,19 +22,43 @@ type badResponse struct {
 	url       string
 }
 
-// request adds failure decoder to req and then makes the request bound by ctx. If everything goes fine then success
-// would contain decoded response. If HTTP request did not complete normally then an error is returned. If request did
-// complete but response was bad then badResponse would contain details. NOTE: Apart from adding failure decoder the
-// req is used as is hence the caller must pass fully prepared req.
+// request adds failure decoder to a copy of req and then makes the request bound by ctx. If everything goes fine then
+// success would contain decoded response. If HTTP request did not complete normally then an error is returned. If
+// request did complete but response was bad then badResponse would contain details. NOTE: Apart from adding failure
+// decoder the req is used as is hence the caller must pass fully prepared req. The req itself is never modified which
+// allows making the same request concurrently. Policies part of the response are collected if ctx asks for it, see
+// [CollectPolicies].
 func request(ctx context.Context, req *rsling.Sling, success any) (*badResponse, error) {
+	req = req.New().FailureDecoder(rsling.ByteStreamer{})
+	if ps, ok := ctx.Value(policiesKey{}).(*Policies); ok {
+		req.SuccessDecoder(policiesDecoder{ps: ps})
+	}
+
 	buf := &bytes.Buffer{}
-	resp, err := req.FailureDecoder(rsling.ByteStreamer{}).ReceiveWithContext(ctx, success, buf)
+	resp, err := req.ReceiveWithContext(ctx, success, buf)
 	if err != nil {
 		return nil, err
 	}
 
+	return newBadResponse(resp, buf), nil
+}
+
+// requestRaw is like request but copies the body of a successful response as is to w. The response is returned to let
+// the caller inspect e.g. its headers.
+func requestRaw(ctx context.Context, req *rsling.Sling, w io.Writer) (*http.Response, *badResponse, error) {
+	buf := &bytes.Buffer{}
+	resp, err := req.New().ResponseDecoder(rsling.ByteStreamer{}).ReceiveWithContext(ctx, w, buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, newBadResponse(resp, buf), nil
+}
+
+// newBadResponse returns the details of resp if it is bad or nil otherwise. The body holds the response data.
+func newBadResponse(resp *http.Response, body *bytes.Buffer) *badResponse {
 	if resp.StatusCode < http.StatusBadRequest {
-		return nil, nil
+		return nil
 	}
 
 	br := &badResponse{
@@ -41,12 +67,12 @@ func request(ctx context.Context, req *rsling.Sling, success any) (*badResponse,
 	}
 
 	if resp.StatusCode >= http.StatusInternalServerError {
-		br.serverErr = buf.String()
+		br.serverErr = body.String()
 	} else if resp.StatusCode >= http.StatusBadRequest {
-		br.clientErr = buf.String()
+		br.clientErr = body.String()
 	}
 
-	return br, nil
+	return br
 }
 
 // apiError wraps a bad HTTP response into an [error]. This allows bad response to be logged or chained to
@@ -72,6 +98,19 @@ func IsTemporary(err error) bool {
 	return errors.As(err, &e) && e.Temporary()
 }
 
+// IsNotFound returns true if err is caused by the server not finding the requested resource e.g. a document which
+// never existed or was deleted for good.
+func IsNotFound(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.br.status == http.StatusNotFound
+}
+
+// IsForbidden returns true if err is caused by the server denying access to the requested resource.
+func IsForbidden(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.br.status == http.StatusForbidden
+}
+
 // temporary is supposed to be implemented by [error]s that want to indicate their temporary nature to the user. The
 // user can then use [outline.IsTemporary] to check this. Reference from standard library:
 // https://cs.opensource.google/go/go/+/refs/tags/go1.20.5:src/net/net.go;l=507
@@ -92,3 +131,28 @@ type paginationQueryParams struct {
 	Limit  int `url:"limit,omitempty"`
 	Offset int `url:"offset,omitempty"`
 }
+
+// optional is a request parameter which, unlike a plain value, can tell apart not being set from being set to the zero
+// value. Parameters of type *optional[T] tagged with omitempty are only sent once set, and then either as null or as
+// their value.
+type optional[T any] struct {
+	value T
+	null  bool
+}
+
+// some returns an optional set to v.
+func some[T any](v T) *optional[T] {
+	return &optional[T]{value: v}
+}
+
+// null returns an optional set to null.
+func null[T any]() *optional[T] {
+	return &optional[T]{null: true}
+}
+
+func (o optional[T]) MarshalJSON() ([]byte, error) {
+	if o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
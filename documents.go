@@ -20,13 +20,15 @@ func newDocumentsClient(sl *rsling.Sling) *DocumentsClient {
 
 // Get returns a client for retrieving a single document.
 func (cl *DocumentsClient) Get() *DocumentsClientGet {
+	copy := cl.sl.New()
 	params := documentsGetParams{}
-	return &DocumentsClientGet{sl: cl.sl, params: params}
+	return &DocumentsClientGet{sl: copy, params: params}
 }
 
 // GetAll returns a client for retrieving multiple documents at once.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.list/post
 func (cl *DocumentsClient) GetAll() *DocumentsClientGetAll {
-	return nil
+	return newDocumentsClientGetAll(cl.sl)
 }
 
 // Create returns a client for creating a single document in the specified collection.
@@ -41,6 +43,69 @@ func (cl *DocumentsClient) Update(id DocumentID) *DocumentsUpdateClient {
 	return newDocumentsUpdateClient(cl.sl, id)
 }
 
+// Delete returns a client for deleting the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.delete/post
+func (cl *DocumentsClient) Delete(id DocumentID) *DocumentsDeleteClient {
+	return newDocumentsDeleteClient(cl.sl, id)
+}
+
+// Move returns a client for moving the document identified by id, along with its children, within its collection or
+// to another collection.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.move/post
+func (cl *DocumentsClient) Move(id DocumentID) *DocumentsMoveClient {
+	return newDocumentsMoveClient(cl.sl, id)
+}
+
+// Archive returns a client for archiving the document identified by id along with its children.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.archive/post
+func (cl *DocumentsClient) Archive(id DocumentID) *DocumentsArchiveClient {
+	return newDocumentsArchiveClient(cl.sl, id)
+}
+
+// Viewed returns a client for listing documents recently viewed by the current user.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.viewed/post
+func (cl *DocumentsClient) Viewed() *DocumentsViewedClient {
+	return newDocumentsViewedClient(cl.sl)
+}
+
+// AddUser returns a client for giving the user identified by userID access to the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.add_user/post
+func (cl *DocumentsClient) AddUser(id DocumentID, userID UserID, permission Permission) *DocumentsAddUserClient {
+	return newDocumentsAddUserClient(cl.sl, id, userID, permission)
+}
+
+// RemoveUser returns a client for revoking access of the user identified by userID to the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.remove_user/post
+func (cl *DocumentsClient) RemoveUser(id DocumentID, userID UserID) *DocumentsRemoveUserClient {
+	return newDocumentsRemoveUserClient(cl.sl, id, userID)
+}
+
+// Memberships returns a client for listing users that were given direct access to the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.memberships/post
+func (cl *DocumentsClient) Memberships(id DocumentID) *DocumentsMembershipsClient {
+	return newDocumentsMembershipsClient(cl.sl, id)
+}
+
+// Users returns a client for listing all users having access to the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.users/post
+func (cl *DocumentsClient) Users(id DocumentID) *DocumentsUsersClient {
+	return newDocumentsUsersClient(cl.sl, id)
+}
+
+// AnswerQuestion returns a client for getting an answer to the natural language query based on documents content.
+// NOTE: The server must have AI answers enabled for this to work.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.answerQuestion/post
+func (cl *DocumentsClient) AnswerQuestion(query string) *DocumentsAnswerQuestionClient {
+	return newDocumentsAnswerQuestionClient(cl.sl, query)
+}
+
+// Related returns a client for listing documents related to the document identified by id i.e. documents which
+// link to it.
+// API reference: https://www.getoutline.com/developers#tag/Documents/paths/~1documents.list/post
+func (cl *DocumentsClient) Related(id DocumentID) *DocumentsRelatedClient {
+	return newDocumentsRelatedClient(cl.sl, id)
+}
+
 // documentsCreateParams represents the Outline Documents.create parameters
 type documentsGetParams struct {
 	DocumentId DocumentID      `json:"id,omitempty"`
@@ -55,25 +120,34 @@ type DocumentsClientGet struct {
 
 // ByID configures that document be retrieved by its id.
 func (cl *DocumentsClientGet) ByID(id DocumentID) *DocumentsClientGet {
-	cl.params.DocumentId = id
-	return cl
+	c := *cl
+	c.params.DocumentId = id
+	return &c
+}
+
+// ByURLID configures that document be retrieved by its url id i.e. the last part of the document's url.
+func (cl *DocumentsClientGet) ByURLID(id DocumentUrlID) *DocumentsClientGet {
+	c := *cl
+	c.params.DocumentId = DocumentID(id)
+	return &c
 }
 
 // ByShareID configures that document be retrieved by its share id.
 func (cl *DocumentsClientGet) ByShareID(id DocumentShareID) *DocumentsClientGet {
-	cl.params.ShareId = id
-	return cl
+	c := *cl
+	c.params.ShareId = id
+	return &c
 }
 
 // Do makes the actual request and returns the document.
 func (cl *DocumentsClientGet) Do(ctx context.Context) (*Document, error) {
-	cl.sl.Post(common.DocumentsGetEndpoint()).BodyJSON(&cl.params)
+	req := cl.sl.New().Post(common.DocumentsGetEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Document `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -84,21 +158,85 @@ func (cl *DocumentsClientGet) Do(ctx context.Context) (*Document, error) {
 	return success.Data, nil
 }
 
+// documentsListParams represents the Outline Documents.list parameters
+type documentsListParams struct {
+	CollectionID     CollectionID `json:"collectionId,omitempty"`
+	ParentDocumentID DocumentID   `json:"parentDocumentId,omitempty"`
+	Template         bool         `json:"template,omitempty"`
+}
+
 // DocumentsClientGetAll can be used to retrieve more than one document. Use available configuration options to select
 // the documents you want to retrieve then finally call [DocumentsClientGetAll.Do].
-type DocumentsClientGetAll struct{}
+type DocumentsClientGetAll struct {
+	sl     *rsling.Sling
+	params documentsListParams
+}
+
+func newDocumentsClientGetAll(sl *rsling.Sling) *DocumentsClientGetAll {
+	return &DocumentsClientGetAll{sl: sl.New()}
+}
 
 // Collection selects documents belonging to the collection identified by id.
-func (cl *DocumentsClientGetAll) Collection(id CollectionID) *DocumentsClientGetAll { return nil }
+func (cl *DocumentsClientGetAll) Collection(id CollectionID) *DocumentsClientGetAll {
+	c := *cl
+	c.params.CollectionID = id
+	return &c
+}
 
 // Parent selects documents having the parent document identified by id.
-func (cl *DocumentsClientGetAll) Parent(id DocumentID) *DocumentsClientGetAll { return nil }
+func (cl *DocumentsClientGetAll) Parent(id DocumentID) *DocumentsClientGetAll {
+	c := *cl
+	c.params.ParentDocumentID = id
+	return &c
+}
+
+// Template selects templates instead of documents if template is true.
+func (cl *DocumentsClientGetAll) Template(template bool) *DocumentsClientGetAll {
+	c := *cl
+	c.params.Template = template
+	return &c
+}
 
 // Do makes the actual request and retrieves selected documents. The user provided callback fn is called for every such
 // document. If there is any error during the process then fn is given the error so that it can decide whether to
-// continue or not. The callback can return false in case it wants to abort getting documents.
+// continue or not. The callback can return false in case it wants to abort getting documents. The error it was given
+// last, if any, is returned then.
 func (cl *DocumentsClientGetAll) Do(ctx context.Context, fn func(*Document, error) bool) error {
-	return nil
+	params := &paginationQueryParams{}
+	for {
+		success := &struct {
+			Data       []*Document `json:"data"`
+			Pagination pagination  `json:"pagination"`
+		}{}
+
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.DocumentsListEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if !fn(nil, err) {
+				return err
+			}
+			continue
+		}
+
+		for _, doc := range success.Data {
+			if !fn(doc, nil) {
+				return nil
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
 }
 
 // documentsCreateParams represents the Outline Documents.create parameters
@@ -125,39 +263,44 @@ func newDocumentsCreateClient(sl *rsling.Sling, title string, id CollectionID) *
 }
 
 func (cl *DocumentsCreateClient) Publish(publish bool) *DocumentsCreateClient {
-	cl.params.Publish = publish
-	return cl
+	c := *cl
+	c.params.Publish = publish
+	return &c
 }
 
 func (cl *DocumentsCreateClient) Text(text string) *DocumentsCreateClient {
-	cl.params.Text = text
-	return cl
+	c := *cl
+	c.params.Text = text
+	return &c
 }
 
 func (cl *DocumentsCreateClient) ParentDocumentID(id DocumentID) *DocumentsCreateClient {
-	cl.params.ParentDocumentId = id
-	return cl
+	c := *cl
+	c.params.ParentDocumentId = id
+	return &c
 }
 
 func (cl *DocumentsCreateClient) TemplateID(id TemplateID) *DocumentsCreateClient {
-	cl.params.TemplateID = id
-	return cl
+	c := *cl
+	c.params.TemplateID = id
+	return &c
 }
 
 func (cl *DocumentsCreateClient) Template(template bool) *DocumentsCreateClient {
-	cl.params.Template = template
-	return cl
+	c := *cl
+	c.params.Template = template
+	return &c
 }
 
 // Do makes the actual request to create a document.
 func (cl *DocumentsCreateClient) Do(ctx context.Context) (*Document, error) {
-	cl.sl.Post(common.DocumentsCreateEndpoint()).BodyJSON(&cl.params)
+	req := cl.sl.New().Post(common.DocumentsCreateEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Document `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -168,20 +311,22 @@ func (cl *DocumentsCreateClient) Do(ctx context.Context) (*Document, error) {
 	return success.Data, nil
 }
 
-// documentsUpdateParams represents the Outline Documents.update parameters
+// documentsUpdateParams represents the Outline Documents.update parameters. Only parameters which were set are sent
+// hence all other properties of the document are left as is.
 type documentsUpdateParams struct {
-	Id      DocumentID `json:"id"`
-	Title   string     `json:"title,omitempty"`
-	Text    string     `json:"text,omitempty"`
-	Append  bool       `json:"append,omitempty"`
-	Publish bool       `json:"publish,omitempty"`
-	Done    bool       `json:"done,omitempty"`
+	Id      DocumentID        `json:"id"`
+	Title   *optional[string] `json:"title,omitempty"`
+	Text    *optional[string] `json:"text,omitempty"`
+	Append  *optional[bool]   `json:"append,omitempty"`
+	Publish *optional[bool]   `json:"publish,omitempty"`
+	Done    *optional[bool]   `json:"done,omitempty"`
 }
 
 // DocumentsUpdateClient is a client for updating a single document.
 type DocumentsUpdateClient struct {
-	sl     *rsling.Sling
-	params documentsUpdateParams
+	sl         *rsling.Sling
+	params     documentsUpdateParams
+	ifRevision *int
 }
 
 func newDocumentsUpdateClient(sl *rsling.Sling, id DocumentID) *DocumentsUpdateClient {
@@ -191,39 +336,203 @@ func newDocumentsUpdateClient(sl *rsling.Sling, id DocumentID) *DocumentsUpdateC
 }
 
 func (cl *DocumentsUpdateClient) Title(title string) *DocumentsUpdateClient {
-	cl.params.Title = title
-	return cl
+	c := *cl
+	c.params.Title = some(title)
+	return &c
 }
 
+// Text sets the text of the document. An empty text clears the document unless appending.
 func (cl *DocumentsUpdateClient) Text(text string) *DocumentsUpdateClient {
-	cl.params.Text = text
-	return cl
+	c := *cl
+	c.params.Text = some(text)
+	return &c
 }
 
 func (cl *DocumentsUpdateClient) Publish(publish bool) *DocumentsUpdateClient {
-	cl.params.Publish = publish
-	return cl
+	c := *cl
+	c.params.Publish = some(publish)
+	return &c
 }
 
 func (cl *DocumentsUpdateClient) Append(append bool) *DocumentsUpdateClient {
-	cl.params.Append = append
-	return cl
+	c := *cl
+	c.params.Append = some(append)
+	return &c
 }
 
 func (cl *DocumentsUpdateClient) Done(done bool) *DocumentsUpdateClient {
-	cl.params.Done = done
-	return cl
+	c := *cl
+	c.params.Done = some(done)
+	return &c
+}
+
+// IfRevision configures that the document is only updated if it is still at the given revision, otherwise a
+// [*ConflictError] is returned. The server has no such precondition hence the revision is checked right before the
+// update, which narrows but does not close the window for concurrent updates.
+func (cl *DocumentsUpdateClient) IfRevision(revision int) *DocumentsUpdateClient {
+	c := *cl
+	c.ifRevision = &revision
+	return &c
 }
 
 // Do makes the actual request to update a document.
 func (cl *DocumentsUpdateClient) Do(ctx context.Context) (*Document, error) {
-	cl.sl.Post(common.DocumentsUpdateEndpoint()).BodyJSON(&cl.params)
+	if cl.ifRevision != nil {
+		current, err := newDocumentsClient(cl.sl).Get().ByID(cl.params.Id).Do(NoCache(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed checking revision: %w", err)
+		}
+		if current.Revision != *cl.ifRevision {
+			return nil, &ConflictError{Revision: *cl.ifRevision, Current: current}
+		}
+	}
+
+	req := cl.sl.New().Post(common.DocumentsUpdateEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data *Document `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// documentsDeleteParams represents the Outline Documents.delete parameters
+type documentsDeleteParams struct {
+	ID        DocumentID `json:"id"`
+	Permanent bool       `json:"permanent,omitempty"`
+}
+
+// DocumentsDeleteClient is a client for deleting a single document.
+type DocumentsDeleteClient struct {
+	sl     *rsling.Sling
+	params documentsDeleteParams
+}
+
+func newDocumentsDeleteClient(sl *rsling.Sling, id DocumentID) *DocumentsDeleteClient {
+	copy := sl.New()
+	params := documentsDeleteParams{ID: id}
+	return &DocumentsDeleteClient{sl: copy, params: params}
+}
+
+// Permanent configures whether the document is deleted for good instead of being moved to trash.
+func (cl *DocumentsDeleteClient) Permanent(permanent bool) *DocumentsDeleteClient {
+	c := *cl
+	c.params.Permanent = permanent
+	return &c
+}
+
+// Do makes the actual request to delete the document.
+func (cl *DocumentsDeleteClient) Do(ctx context.Context) error {
+	req := cl.sl.New().Post(common.DocumentsDeleteEndpoint()).BodyJSON(&cl.params)
+
+	br, err := request(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return nil
+}
+
+// documentsMoveParams represents the Outline Documents.move parameters
+type documentsMoveParams struct {
+	ID               DocumentID   `json:"id"`
+	CollectionID     CollectionID `json:"collectionId,omitempty"`
+	ParentDocumentID DocumentID   `json:"parentDocumentId,omitempty"`
+	Index            *int         `json:"index,omitempty"`
+}
+
+// DocumentsMoveClient is a client for moving a single document.
+type DocumentsMoveClient struct {
+	sl     *rsling.Sling
+	params documentsMoveParams
+}
+
+func newDocumentsMoveClient(sl *rsling.Sling, id DocumentID) *DocumentsMoveClient {
+	copy := sl.New()
+	params := documentsMoveParams{ID: id}
+	return &DocumentsMoveClient{sl: copy, params: params}
+}
+
+// CollectionID configures the collection the document is moved to. By default the document stays in its collection.
+func (cl *DocumentsMoveClient) CollectionID(id CollectionID) *DocumentsMoveClient {
+	c := *cl
+	c.params.CollectionID = id
+	return &c
+}
+
+// ParentDocumentID configures the new parent of the document. By default the document becomes a top level document.
+func (cl *DocumentsMoveClient) ParentDocumentID(id DocumentID) *DocumentsMoveClient {
+	c := *cl
+	c.params.ParentDocumentID = id
+	return &c
+}
+
+// Index configures the position of the document among its new siblings. By default it is placed last.
+func (cl *DocumentsMoveClient) Index(index int) *DocumentsMoveClient {
+	c := *cl
+	c.params.Index = &index
+	return &c
+}
+
+// Do makes the actual request to move the document and returns all documents which moved i.e. the document and its
+// children.
+func (cl *DocumentsMoveClient) Do(ctx context.Context) ([]Document, error) {
+	req := cl.sl.New().Post(common.DocumentsMoveEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data struct {
+			Documents []Document `json:"documents"`
+		} `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data.Documents, nil
+}
+
+// documentsArchiveParams represents the Outline Documents.archive parameters
+type documentsArchiveParams struct {
+	ID DocumentID `json:"id"`
+}
+
+// DocumentsArchiveClient is a client for archiving a single document.
+type DocumentsArchiveClient struct {
+	sl     *rsling.Sling
+	params documentsArchiveParams
+}
+
+func newDocumentsArchiveClient(sl *rsling.Sling, id DocumentID) *DocumentsArchiveClient {
+	copy := sl.New()
+	params := documentsArchiveParams{ID: id}
+	return &DocumentsArchiveClient{sl: copy, params: params}
+}
+
+// Do makes the actual request to archive the document and returns the archived document.
+func (cl *DocumentsArchiveClient) Do(ctx context.Context) (*Document, error) {
+	req := cl.sl.New().Post(common.DocumentsArchiveEndpoint()).BodyJSON(&cl.params)
 
 	success := &struct {
 		Data *Document `json:"data"`
 	}{}
 
-	br, err := request(ctx, cl.sl, success)
+	br, err := request(ctx, req, success)
 	if err != nil {
 		return nil, fmt.Errorf("failed making HTTP request: %w", err)
 	}
@@ -233,3 +542,427 @@ func (cl *DocumentsUpdateClient) Do(ctx context.Context) (*Document, error) {
 
 	return success.Data, nil
 }
+
+// DocumentsViewedClient is a client for listing documents recently viewed by the current user.
+type DocumentsViewedClient struct {
+	sl *rsling.Sling
+}
+
+func newDocumentsViewedClient(sl *rsling.Sling) *DocumentsViewedClient {
+	copy := sl.New()
+	copy.Post(common.DocumentsViewedEndpoint())
+
+	return &DocumentsViewedClient{sl: copy}
+}
+
+// DocumentsViewedFn is the type of function called by [DocumentsViewedClient.Do] for every viewed document it finds.
+type DocumentsViewedFn func(*Document, error) (bool, error)
+
+// Do makes the actual request for listing recently viewed documents, most recent first. If the request is successful
+// then fn is called sequentially with every document received. But if there is some error/bad response then fn is
+// called with the error. If fn returns false then the whole process is aborted otherwise the request is retried.
+func (cl *DocumentsViewedClient) Do(ctx context.Context, fn DocumentsViewedFn) error {
+	success := &struct {
+		Data       []*Document `json:"data"`
+		Pagination pagination  `json:"pagination"`
+	}{}
+
+	params := &paginationQueryParams{}
+	for {
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, doc := range success.Data {
+			if ok, e := fn(doc, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
+
+// documentMemberships is the data returned by the server for membership related requests. The users are returned
+// separately from memberships hence join fills in the user of every membership.
+type documentMemberships struct {
+	Users       []User               `json:"users"`
+	Memberships []DocumentMembership `json:"memberships"`
+}
+
+func (dm *documentMemberships) join() []DocumentMembership {
+	users := make(map[UserID]User, len(dm.Users))
+	for _, u := range dm.Users {
+		users[UserID(u.ID)] = u
+	}
+
+	memberships := make([]DocumentMembership, 0, len(dm.Memberships))
+	for _, m := range dm.Memberships {
+		if u, ok := users[m.UserID]; ok {
+			m.User = &u
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships
+}
+
+// documentsAddUserParams represents the Outline Documents.add_user parameters
+type documentsAddUserParams struct {
+	ID         DocumentID `json:"id"`
+	UserID     UserID     `json:"userId"`
+	Permission Permission `json:"permission,omitempty"`
+}
+
+// DocumentsAddUserClient is a client for giving a single user access to a document.
+type DocumentsAddUserClient struct {
+	sl     *rsling.Sling
+	params documentsAddUserParams
+}
+
+func newDocumentsAddUserClient(
+	sl *rsling.Sling, id DocumentID, userID UserID, permission Permission,
+) *DocumentsAddUserClient {
+	copy := sl.New()
+	params := documentsAddUserParams{ID: id, UserID: userID, Permission: permission}
+	return &DocumentsAddUserClient{sl: copy, params: params}
+}
+
+// Do makes the actual request to add the user and returns the resulting membership.
+func (cl *DocumentsAddUserClient) Do(ctx context.Context) (*DocumentMembership, error) {
+	req := cl.sl.New().Post(common.DocumentsAddUserEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data documentMemberships `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	memberships := success.Data.join()
+	if len(memberships) == 0 {
+		return nil, fmt.Errorf("no membership returned for user '%s'", cl.params.UserID)
+	}
+
+	return &memberships[0], nil
+}
+
+// DocumentsRemoveUserClient is a client for revoking access of a single user to a document.
+type DocumentsRemoveUserClient struct {
+	sl *rsling.Sling
+}
+
+func newDocumentsRemoveUserClient(sl *rsling.Sling, id DocumentID, userID UserID) *DocumentsRemoveUserClient {
+	data := struct {
+		ID     DocumentID `json:"id"`
+		UserID UserID     `json:"userId"`
+	}{ID: id, UserID: userID}
+
+	copy := sl.New()
+	copy.Post(common.DocumentsRemoveUserEndpoint()).BodyJSON(&data)
+
+	return &DocumentsRemoveUserClient{sl: copy}
+}
+
+// Do makes the actual request to remove the user from the document.
+func (cl *DocumentsRemoveUserClient) Do(ctx context.Context) error {
+	br, err := request(ctx, cl.sl, nil)
+	if err != nil {
+		return fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return nil
+}
+
+// documentsMembershipsParams represents the Outline Documents.memberships parameters
+type documentsMembershipsParams struct {
+	ID         DocumentID `json:"id"`
+	Query      string     `json:"query,omitempty"`
+	Permission Permission `json:"permission,omitempty"`
+}
+
+// DocumentsMembershipsClient is a client for listing direct memberships of a document.
+type DocumentsMembershipsClient struct {
+	sl     *rsling.Sling
+	params documentsMembershipsParams
+}
+
+func newDocumentsMembershipsClient(sl *rsling.Sling, id DocumentID) *DocumentsMembershipsClient {
+	copy := sl.New()
+	params := documentsMembershipsParams{ID: id}
+	return &DocumentsMembershipsClient{sl: copy, params: params}
+}
+
+// Query selects only memberships of users whose name matches query.
+func (cl *DocumentsMembershipsClient) Query(query string) *DocumentsMembershipsClient {
+	c := *cl
+	c.params.Query = query
+	return &c
+}
+
+// Permission selects only memberships with the given permission.
+func (cl *DocumentsMembershipsClient) Permission(permission Permission) *DocumentsMembershipsClient {
+	c := *cl
+	c.params.Permission = permission
+	return &c
+}
+
+// DocumentsMembershipsFn is the type of function called by [DocumentsMembershipsClient.Do] for every membership it
+// finds.
+type DocumentsMembershipsFn func(*DocumentMembership, error) (bool, error)
+
+// Do makes the actual request for listing memberships. If the request is successful then fn is called sequentially
+// with every membership received. But if there is some error/bad response then fn is called with the error. If fn
+// returns false then the whole process is aborted otherwise the request is retried.
+func (cl *DocumentsMembershipsClient) Do(ctx context.Context, fn DocumentsMembershipsFn) error {
+	params := &paginationQueryParams{}
+	for {
+		success := &struct {
+			Data       documentMemberships `json:"data"`
+			Pagination pagination          `json:"pagination"`
+		}{}
+
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.DocumentsMembershipsEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		memberships := success.Data.join()
+		for i := range memberships {
+			if ok, e := fn(&memberships[i], nil); !ok {
+				return e
+			}
+		}
+
+		if len(memberships) <= 1 {
+			return nil
+		}
+		params.Offset += len(memberships)
+	}
+}
+
+// documentsUsersParams represents the Outline Documents.users parameters
+type documentsUsersParams struct {
+	ID    DocumentID `json:"id"`
+	Query string     `json:"query,omitempty"`
+}
+
+// DocumentsUsersClient is a client for listing all users having access to a document.
+type DocumentsUsersClient struct {
+	sl     *rsling.Sling
+	params documentsUsersParams
+}
+
+func newDocumentsUsersClient(sl *rsling.Sling, id DocumentID) *DocumentsUsersClient {
+	copy := sl.New()
+	params := documentsUsersParams{ID: id}
+	return &DocumentsUsersClient{sl: copy, params: params}
+}
+
+// Query selects only users whose name matches query.
+func (cl *DocumentsUsersClient) Query(query string) *DocumentsUsersClient {
+	c := *cl
+	c.params.Query = query
+	return &c
+}
+
+// DocumentsUsersFn is the type of function called by [DocumentsUsersClient.Do] for every user it finds.
+type DocumentsUsersFn func(*User, error) (bool, error)
+
+// Do makes the actual request for listing users. If the request is successful then fn is called sequentially with
+// every user received. But if there is some error/bad response then fn is called with the error. If fn returns false
+// then the whole process is aborted otherwise the request is retried.
+func (cl *DocumentsUsersClient) Do(ctx context.Context, fn DocumentsUsersFn) error {
+	success := &struct {
+		Data       []*User    `json:"data"`
+		Pagination pagination `json:"pagination"`
+	}{}
+
+	params := &paginationQueryParams{}
+	for {
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.DocumentsUsersEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, u := range success.Data {
+			if ok, e := fn(u, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
+
+// documentsAnswerQuestionParams represents the Outline Documents.answerQuestion parameters
+type documentsAnswerQuestionParams struct {
+	Query        string       `json:"query"`
+	CollectionID CollectionID `json:"collectionId,omitempty"`
+	DocumentID   DocumentID   `json:"documentId,omitempty"`
+}
+
+// DocumentsAnswerQuestionClient is a client for answering a single question.
+type DocumentsAnswerQuestionClient struct {
+	sl     *rsling.Sling
+	params documentsAnswerQuestionParams
+}
+
+func newDocumentsAnswerQuestionClient(sl *rsling.Sling, query string) *DocumentsAnswerQuestionClient {
+	copy := sl.New()
+	params := documentsAnswerQuestionParams{Query: query}
+	return &DocumentsAnswerQuestionClient{sl: copy, params: params}
+}
+
+// Collection limits the documents considered for the answer to the collection identified by id.
+func (cl *DocumentsAnswerQuestionClient) Collection(id CollectionID) *DocumentsAnswerQuestionClient {
+	c := *cl
+	c.params.CollectionID = id
+	return &c
+}
+
+// Document limits the documents considered for the answer to the document identified by id and its children.
+func (cl *DocumentsAnswerQuestionClient) Document(id DocumentID) *DocumentsAnswerQuestionClient {
+	c := *cl
+	c.params.DocumentID = id
+	return &c
+}
+
+// Do makes the actual request and returns the answer along with the documents it was derived from.
+func (cl *DocumentsAnswerQuestionClient) Do(ctx context.Context) (*DocumentsAnswer, error) {
+	req := cl.sl.New().Post(common.DocumentsAnswerQuestionEndpoint()).BodyJSON(&cl.params)
+
+	// NOTE: Unlike other endpoints the response is not wrapped inside a data object.
+	success := &struct {
+		Documents []DocumentSummary `json:"documents"`
+		Search    struct {
+			Query  string `json:"query"`
+			Answer string `json:"answer"`
+		} `json:"search"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return &DocumentsAnswer{
+		Query:   success.Search.Query,
+		Answer:  success.Search.Answer,
+		Sources: success.Documents,
+	}, nil
+}
+
+// DocumentsRelatedClient is a client for listing documents related to a single document.
+type DocumentsRelatedClient struct {
+	sl *rsling.Sling
+}
+
+func newDocumentsRelatedClient(sl *rsling.Sling, id DocumentID) *DocumentsRelatedClient {
+	data := struct {
+		BacklinkDocumentID DocumentID `json:"backlinkDocumentId"`
+	}{BacklinkDocumentID: id}
+
+	copy := sl.New()
+	copy.Post(common.DocumentsListEndpoint()).BodyJSON(&data)
+
+	return &DocumentsRelatedClient{sl: copy}
+}
+
+// DocumentsRelatedFn is the type of function called by [DocumentsRelatedClient.Do] for every related document it finds.
+type DocumentsRelatedFn func(*Document, error) (bool, error)
+
+// Do makes the actual request for listing related documents. If the request is successful then fn is called
+// sequentially with every document received. But if there is some error/bad response then fn is called with the error.
+// If fn returns false then the whole process is aborted otherwise the request is retried.
+func (cl *DocumentsRelatedClient) Do(ctx context.Context, fn DocumentsRelatedFn) error {
+	success := &struct {
+		Data       []*Document `json:"data"`
+		Pagination pagination  `json:"pagination"`
+	}{}
+
+	params := &paginationQueryParams{}
+	for {
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, doc := range success.Data {
+			if ok, e := fn(doc, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
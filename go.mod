@@ -1,6 +1,6 @@
 module github.com/ioki-mobility/go-outline
 
-go 1.20
+go 1.21
 
 require (
 	github.com/rsjethani/rsling v0.2.0
@@ -4,12 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/backup"
 	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/lint"
+	"github.com/ioki-mobility/go-outline/migrate"
+	"github.com/ioki-mobility/go-outline/site"
 	"github.com/spf13/cobra"
 )
 
@@ -116,16 +122,37 @@ func Command() *cobra.Command {
 	}
 	documentGetCmd.Flags().BoolVar(&isShareID, "share", false, "Treat the argument as document share iD")
 
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Find problems in documents",
+		Long:  "Check the documents of all collections for problems like broken links",
+		Args:  cobra.MinimumNArgs(1),
+	}
+
+	siteCmd := &cobra.Command{
+		Use:   "site",
+		Short: "Publish collections as static website",
+		Long:  "Render collections into a static website e.g. to host it on GitHub Pages",
+		Args:  cobra.MinimumNArgs(1),
+	}
+
 	rootCmd.AddCommand(collectionCmd)
 	collectionCmd.AddCommand(collectionInfoCmd)
 	collectionCmd.AddCommand(collectionCreateCmd)
 	collectionCmd.AddCommand(collectionDocumentsCmd)
 	collectionCmd.AddCommand(collectionListCmd)
 	collectionCmd.AddCommand(collectionUpdate())
+	collectionCmd.AddCommand(collectionCopy())
 	rootCmd.AddCommand(documentCmd)
 	documentCmd.AddCommand(documentCreateCmd)
 	documentCmd.AddCommand(documentGetCmd)
 	documentCmd.AddCommand(documentUpdate())
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.AddCommand(lintLinks())
+	rootCmd.AddCommand(backupCmd())
+	rootCmd.AddCommand(restoreCmd())
+	rootCmd.AddCommand(siteCmd)
+	siteCmd.AddCommand(siteBuild())
 
 	return rootCmd
 }
@@ -224,18 +251,26 @@ func collectionUpdate() *cobra.Command {
 				return fmt.Errorf("%s: %w", errBase, err)
 			}
 
-			cl := outline.New(url, &http.Client{}, key).
-				Collections().
-				Update(outline.CollectionID(id)).
-				Name(name).
-				Description(description).
-				Color(color)
-
+			// Only send properties given on the command line so that all others are left as is.
+			cl := outline.New(url, &http.Client{}, key).Collections().Update(outline.CollectionID(id))
+			if c.Flags().Changed("name") {
+				cl = cl.Name(name)
+			}
+			if c.Flags().Changed("description") {
+				cl = cl.Description(description)
+			}
+			if c.Flags().Changed("color") {
+				if color == "" {
+					cl = cl.ClearColor()
+				} else {
+					cl = cl.Color(color)
+				}
+			}
 			if permissionRead {
-				cl.PermissionRead()
+				cl = cl.PermissionRead()
 			}
 			if permissionReadWrite {
-				cl.PermissionReadWrite()
+				cl = cl.PermissionReadWrite()
 			}
 
 			doc, err := cl.Do(context.Background())
@@ -255,7 +290,9 @@ func collectionUpdate() *cobra.Command {
 
 	cmd.Flags().StringVar(&name, "name", "", "The name of the collection")
 	cmd.Flags().StringVar(&description, "description", "", "The description of the collection")
-	cmd.Flags().StringVar(&color, "color", "", "The color of the collection. Should be in the format of #AABBCC")
+	cmd.Flags().StringVar(&color, "color", "",
+		"The color of the collection. Should be in the format of #AABBCC, empty removes the color",
+	)
 	cmd.Flags().BoolVar(&permissionRead, "permission-read", false, "Change the permission to read only")
 	cmd.Flags().BoolVar(&permissionReadWrite, "permission-read-write", false, "Change the permission to read write")
 	cmd.MarkFlagsMutuallyExclusive("permission-read", "permission-read-write")
@@ -263,6 +300,66 @@ func collectionUpdate() *cobra.Command {
 	return cmd
 }
 
+func collectionCopy() *cobra.Command {
+	var targetServerURL, targetKey, checkpoint string
+
+	cmd := &cobra.Command{
+		Use:   "copy <collection id>",
+		Short: "Copy a collection to another server",
+		Long: "Copy a collection along with its documents, templates, attachments and document memberships from the " +
+			"server to the target server. Links between the copied documents are rewritten to the copies. With a " +
+			"checkpoint file an interrupted copy is resumed when running the command again. The ids of the copies " +
+			"are printed as json to stdout, keyed by the source ids.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id := args[0]
+			errBase := fmt.Sprintf("failed copying collection with ID '%s'", id)
+
+			// Extract value of global flags
+			key, err := c.Flags().GetString(flagApiKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			serverURL, err := c.Flags().GetString(flagServerURL)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			opts := []migrate.Option{}
+			// Absolute links to the source server are rewritten as well.
+			if u, err := url.Parse(serverURL); err == nil && u.Hostname() != "" {
+				opts = append(opts, migrate.WithHosts(u.Hostname()))
+			}
+			if checkpoint != "" {
+				opts = append(opts, migrate.WithCheckpoint(checkpoint))
+			}
+
+			src := outline.New(serverURL, &http.Client{}, key)
+			dst := outline.New(targetServerURL, &http.Client{}, targetKey)
+			res, err := migrate.CopyCollection(context.Background(), src, dst, outline.CollectionID(id), opts...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&targetServerURL, "target-server", "", "The outline API server url to copy to")
+	cmd.Flags().StringVar(&targetKey, "target-key", "", "The outline api key of the target server")
+	cmd.Flags().StringVar(&checkpoint, "checkpoint", "", "File to record the progress of the copy in for resuming it")
+	cmd.MarkFlagRequired("target-server")
+	cmd.MarkFlagRequired("target-key")
+
+	return cmd
+}
+
 func documentCreate(serverUrl string, apiKey string, name string, collectionId outline.CollectionID) error {
 	oc := outline.New(serverUrl, &http.Client{}, apiKey)
 	doc, err := oc.Documents().Create(name, collectionId).Do(context.Background())
@@ -328,15 +425,23 @@ func documentUpdate() *cobra.Command {
 				return fmt.Errorf("%s: %w", errBase, err)
 			}
 
-			cl := outline.New(url, &http.Client{}, key).Documents().Update(outline.DocumentID(id)).
-				Append(append).Publish(publish).Title(title)
-
+			// Only send properties given on the command line so that all others are left as is.
+			cl := outline.New(url, &http.Client{}, key).Documents().Update(outline.DocumentID(id))
+			if c.Flags().Changed("title") {
+				cl = cl.Title(title)
+			}
+			if c.Flags().Changed("append") {
+				cl = cl.Append(append)
+			}
+			if c.Flags().Changed("publish") {
+				cl = cl.Publish(publish)
+			}
 			if readText {
 				b, err := io.ReadAll(os.Stdin)
 				if err != nil {
 					return fmt.Errorf("%s: %w", errBase, err)
 				}
-				cl.Text(string(b))
+				cl = cl.Text(string(b))
 			}
 
 			doc, err := cl.Do(context.Background())
@@ -363,3 +468,230 @@ func documentUpdate() *cobra.Command {
 
 	return cmd
 }
+
+func lintLinks() *cobra.Command {
+	var hosts []string
+
+	cmd := &cobra.Command{
+		Use:   "links",
+		Short: "Find broken links",
+		Long: "Find links to deleted, archived or inaccessible documents, shares and attachments in all collections. " +
+			"Every broken link is printed along with the document and line containing it. Exits with an error if " +
+			"any broken link was found.",
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			errBase := "failed checking links"
+
+			// Extract value of global flags
+			key, err := c.Flags().GetString(flagApiKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			serverURL, err := c.Flags().GetString(flagServerURL)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			// Absolute links to the server itself are internal links as well.
+			if u, err := url.Parse(serverURL); err == nil && u.Hostname() != "" {
+				hosts = append(hosts, u.Hostname())
+			}
+
+			cl := outline.New(serverURL, &http.Client{}, key)
+			broken, err := lint.CheckLinks(context.Background(), cl, lint.WithHosts(hosts...))
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			for _, bl := range broken {
+				fmt.Println(bl)
+			}
+			if len(broken) > 0 {
+				return fmt.Errorf("found %d broken links", len(broken))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&hosts, "host", nil,
+		"Additional host under which the wiki is reachable, absolute links to it are checked as well",
+	)
+
+	return cmd
+}
+
+func backupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup <file>",
+		Short: "Back up all collections",
+		Long: "Write all collections along with their documents and attachments to the archive file. The archive can " +
+			"be restored on the same or a different server with the restore command.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			errBase := "failed backing up"
+
+			// Extract value of global flags
+			key, err := c.Flags().GetString(flagApiKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			serverURL, err := c.Flags().GetString(flagServerURL)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			// Absolute links to the server itself are rewritten on restore as well.
+			hosts := []string{}
+			if u, err := url.Parse(serverURL); err == nil && u.Hostname() != "" {
+				hosts = append(hosts, u.Hostname())
+			}
+
+			f, err := os.Create(args[0])
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			defer f.Close()
+
+			cl := outline.New(serverURL, &http.Client{}, key)
+			m, err := backup.Write(context.Background(), cl, f, backup.WithHosts(hosts...))
+			if err != nil {
+				// Do not leave an incomplete archive behind.
+				f.Close()
+				os.Remove(args[0])
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			documents := 0
+			for _, col := range m.Collections {
+				documents += len(col.Structure.Flatten())
+			}
+			fmt.Printf("Backed up %d collections, %d documents and %d attachments to %s\n",
+				len(m.Collections), documents, len(m.Attachments), args[0])
+
+			return nil
+		},
+	}
+}
+
+func restoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Restore collections from a backup",
+		Long: "Create the collections of the archive file written by the backup command along with their documents " +
+			"and attachments. Links between documents are rewritten to the restored documents. The ids of the " +
+			"restored collections, documents and attachments are printed as json to stdout, keyed by the backed up ids.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			errBase := "failed restoring"
+
+			// Extract value of global flags
+			key, err := c.Flags().GetString(flagApiKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			serverURL, err := c.Flags().GetString(flagServerURL)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			cl := outline.New(serverURL, &http.Client{}, key)
+			res, err := backup.Restore(context.Background(), cl, f, info.Size())
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+}
+
+func siteBuild() *cobra.Command {
+	var out, title, themeFile string
+	var hugo bool
+	var hosts []string
+
+	cmd := &cobra.Command{
+		Use:   "build [collection id...]",
+		Short: "Build a static website",
+		Long: "Render the given collections, or all collections if none are given, into a static website following " +
+			"their document structure. Attachments are copied to the website and links between documents point to " +
+			"their pages. The website can be served from any path e.g. GitHub Pages. Alternatively a content tree for " +
+			"Hugo is written.",
+		RunE: func(c *cobra.Command, args []string) error {
+			errBase := "failed building site"
+
+			// Extract value of global flags
+			key, err := c.Flags().GetString(flagApiKey)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			serverURL, err := c.Flags().GetString(flagServerURL)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+
+			// Absolute links to the server itself are rewritten as well.
+			if u, err := url.Parse(serverURL); err == nil && u.Hostname() != "" {
+				hosts = append(hosts, u.Hostname())
+			}
+			opts := []site.Option{site.WithHosts(hosts...)}
+			if title != "" {
+				opts = append(opts, site.WithTitle(title))
+			}
+			if hugo {
+				opts = append(opts, site.WithFormat(site.FormatHugo))
+			}
+			if themeFile != "" {
+				theme, err := template.ParseFiles(themeFile)
+				if err != nil {
+					return fmt.Errorf("%s: %w", errBase, err)
+				}
+				opts = append(opts, site.WithTheme(theme))
+			}
+
+			ids := []outline.CollectionID{}
+			for _, id := range args {
+				ids = append(ids, outline.CollectionID(id))
+			}
+			cl := outline.New(serverURL, &http.Client{}, key)
+			res, err := site.Build(context.Background(), cl, out, ids, opts...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", errBase, err)
+			}
+			fmt.Printf("Built %d pages and %d attachments in %s\n", len(res.Pages), len(res.Attachments), out)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "public", "The directory to write the website to")
+	cmd.Flags().StringVar(&title, "title", "", "The title of the website, defaults to Documentation")
+	cmd.Flags().StringVar(&themeFile, "theme", "",
+		"Go html/template file to render pages with instead of the default theme",
+	)
+	cmd.Flags().BoolVar(&hugo, "hugo", false, "Write a Hugo content tree instead of HTML pages")
+	cmd.Flags().StringSliceVar(&hosts, "host", nil,
+		"Additional host under which the wiki is reachable, absolute links to it are rewritten as well",
+	)
+
+	return cmd
+}
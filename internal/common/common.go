@@ -53,3 +53,103 @@ func DocumentsUpdateEndpoint() string {
 func AttachmentsCreateEndpoint() string {
 	return "attachments.create"
 }
+
+func AttachmentsRedirectEndpoint() string {
+	return "attachments.redirect"
+}
+
+func DocumentsViewedEndpoint() string {
+	return "documents.viewed"
+}
+
+func ViewsListEndpoint() string {
+	return "views.list"
+}
+
+func ViewsCreateEndpoint() string {
+	return "views.create"
+}
+
+func DocumentsAddUserEndpoint() string {
+	return "documents.add_user"
+}
+
+func DocumentsRemoveUserEndpoint() string {
+	return "documents.remove_user"
+}
+
+func DocumentsMembershipsEndpoint() string {
+	return "documents.memberships"
+}
+
+func DocumentsUsersEndpoint() string {
+	return "documents.users"
+}
+
+func DocumentsAnswerQuestionEndpoint() string {
+	return "documents.answerQuestion"
+}
+
+func DocumentsListEndpoint() string {
+	return "documents.list"
+}
+
+func APIKeysListEndpoint() string {
+	return "apiKeys.list"
+}
+
+func APIKeysCreateEndpoint() string {
+	return "apiKeys.create"
+}
+
+func APIKeysRevokeEndpoint() string {
+	return "apiKeys.delete"
+}
+
+func OAuthClientsListEndpoint() string {
+	return "oauthClients.list"
+}
+
+func OAuthClientsGetEndpoint() string {
+	return "oauthClients.info"
+}
+
+func OAuthClientsCreateEndpoint() string {
+	return "oauthClients.create"
+}
+
+func OAuthClientsUpdateEndpoint() string {
+	return "oauthClients.update"
+}
+
+func OAuthClientsDeleteEndpoint() string {
+	return "oauthClients.delete"
+}
+
+func DocumentsDeleteEndpoint() string {
+	return "documents.delete"
+}
+
+func DocumentsMoveEndpoint() string {
+	return "documents.move"
+}
+
+func DocumentsArchiveEndpoint() string {
+	return "documents.archive"
+}
+
+func CollectionsMembershipsEndpoint() string {
+	return "collections.memberships"
+}
+
+func CollectionsAddUserEndpoint() string {
+	return "collections.add_user"
+}
+
+func CollectionsGroupMembershipsEndpoint() string {
+	return "collections.group_memberships"
+}
+
+func UsersListEndpoint() string {
+	return "users.list"
+}
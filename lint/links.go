@@ -0,0 +1,222 @@
+// Package lint finds problems in the documents of an outline workspace.
+package lint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// Reason tells why a link is broken.
+type Reason string
+
+const (
+	// ReasonNotFound is used for targets which do not exist (anymore) e.g. documents deleted for good or revoked
+	// shares.
+	ReasonNotFound Reason = "not found"
+	// ReasonDeleted is used for documents which were moved to trash.
+	ReasonDeleted Reason = "deleted"
+	// ReasonArchived is used for archived documents.
+	ReasonArchived Reason = "archived"
+	// ReasonInaccessible is used for targets the user of the client has no access to.
+	ReasonInaccessible Reason = "inaccessible"
+)
+
+// BrokenLink is a link to a document, share or attachment which cannot be followed.
+type BrokenLink struct {
+	CollectionID outline.CollectionID
+	// DocumentID and DocumentTitle identify the document containing the link.
+	DocumentID    outline.DocumentID
+	DocumentTitle string
+	// Line is the line of the link in the text of the document, starting at 1.
+	Line   int
+	URL    string
+	Reason Reason
+}
+
+func (bl BrokenLink) String() string {
+	return fmt.Sprintf("%s (%s):%d: %s: %s", bl.DocumentTitle, bl.DocumentID, bl.Line, bl.URL, bl.Reason)
+}
+
+// LinksOption configures [CheckLinks].
+type LinksOption func(*linksOptions)
+
+type linksOptions struct {
+	hosts       map[string]bool
+	concurrency int
+}
+
+// WithHosts makes [CheckLinks] check absolute links to any of hosts e.g. wiki.example.com as well. By default only
+// links without host are checked, which is how outline writes links to its own documents and attachments.
+func WithHosts(hosts ...string) LinksOption {
+	return func(o *linksOptions) {
+		for _, h := range hosts {
+			o.hosts[strings.ToLower(h)] = true
+		}
+	}
+}
+
+// WithConcurrency limits the number of documents fetched in parallel to n.
+func WithConcurrency(n int) LinksOption {
+	return func(o *linksOptions) {
+		o.concurrency = n
+	}
+}
+
+// CheckLinks walks the published documents of all collections the client has access to and returns the links to
+// documents (/doc/…), shares (/s/…) and attachments (/api/attachments.redirect?id=…) which are broken. The broken links
+// are ordered by collection, then by document in structure order and then by line. Links to other sites are ignored.
+func CheckLinks(ctx context.Context, cl *outline.Client, opts ...LinksOption) ([]BrokenLink, error) {
+	o := linksOptions{hosts: map[string]bool{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	batchOpts := []outline.BatchOption{}
+	if o.concurrency > 0 {
+		batchOpts = append(batchOpts, outline.WithConcurrency(o.concurrency))
+	}
+
+	cols := []*outline.Collection{}
+	err := cl.Collections().List().Do(ctx, func(col *outline.Collection, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		cols = append(cols, col)
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing collections: %w", err)
+	}
+
+	// The documents of all structures are published and accessible hence links to them need no further check.
+	c := &linkChecker{cl: cl, hosts: o.hosts, results: map[string]Reason{}}
+	structures := make([]outline.DocumentStructure, len(cols))
+	for i, col := range cols {
+		st, err := cl.Collections().DocumentStructure(col.ID).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting document structure of collection '%s': %w", col.ID, err)
+		}
+		structures[i] = st
+		for _, doc := range st.Flatten() {
+			if urlID, ok := outline.ParseURLID(doc.URL); ok {
+				c.results["doc:"+string(urlID)] = ""
+			}
+		}
+	}
+
+	broken := []BrokenLink{}
+	for i, col := range cols {
+		ids := []outline.DocumentID{}
+		for _, doc := range structures[i].Flatten() {
+			ids = append(ids, doc.ID)
+		}
+
+		for _, res := range cl.Documents().BatchGet(ctx, ids, batchOpts...) {
+			if res.Err != nil {
+				return nil, fmt.Errorf("failed getting document: %w", res.Err)
+			}
+			doc := res.Value
+			for _, link := range markdown.Parse(doc.Text).Links() {
+				reason, err := c.check(ctx, link.URL)
+				if err != nil {
+					return nil, fmt.Errorf("failed checking link '%s' of document '%s': %w", link.URL, doc.ID, err)
+				}
+				if reason == "" {
+					continue
+				}
+				broken = append(broken, BrokenLink{
+					CollectionID:  col.ID,
+					DocumentID:    doc.ID,
+					DocumentTitle: doc.Title,
+					Line:          link.Line,
+					URL:           link.URL,
+					Reason:        reason,
+				})
+			}
+		}
+	}
+
+	return broken, nil
+}
+
+// linkChecker checks links and remembers the results.
+type linkChecker struct {
+	cl    *outline.Client
+	hosts map[string]bool
+	// results holds the reason a target is broken by e.g. doc:<url id>, empty for targets which are fine.
+	results map[string]Reason
+}
+
+// check returns why the target of the link is broken, or an empty reason if it is fine or not checked.
+func (c *linkChecker) check(ctx context.Context, link string) (Reason, error) {
+	u, err := url.Parse(link)
+	if err != nil || (u.Host != "" && !c.hosts[strings.ToLower(u.Hostname())]) || !strings.HasPrefix(u.Path, "/") {
+		return "", nil
+	}
+
+	var key string
+	var fetch func() (*outline.Document, error)
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case u.Path == "/api/attachments.redirect" && u.Query().Get("id") != "":
+		id := outline.AttachmentID(u.Query().Get("id"))
+		key = "attachment:" + string(id)
+		fetch = func() (*outline.Document, error) {
+			// It is enough to know that the server redirects to the storage, the content need not be downloaded.
+			_, err := c.cl.Attachments().Redirect(id).Do(ctx)
+			return nil, err
+		}
+	case len(segments) >= 2 && segments[0] == "doc":
+		urlID, ok := outline.ParseURLID(u.Path)
+		if !ok {
+			return "", nil
+		}
+		key = "doc:" + string(urlID)
+		fetch = func() (*outline.Document, error) {
+			return c.cl.Documents().Get().ByURLID(urlID).Do(ctx)
+		}
+	case len(segments) >= 2 && (segments[0] == "s" || segments[0] == "share"):
+		id := outline.DocumentShareID(segments[1])
+		key = "share:" + string(id)
+		fetch = func() (*outline.Document, error) {
+			return c.cl.Documents().Get().ByShareID(id).Do(ctx)
+		}
+	default:
+		return "", nil
+	}
+
+	if reason, ok := c.results[key]; ok {
+		return reason, nil
+	}
+	reason, err := reasonOf(fetch())
+	if err != nil {
+		return "", err
+	}
+	c.results[key] = reason
+
+	return reason, nil
+}
+
+// reasonOf returns why the outcome of fetching a link's target makes the link broken.
+func reasonOf(doc *outline.Document, err error) (Reason, error) {
+	switch {
+	case outline.IsNotFound(err):
+		return ReasonNotFound, nil
+	case outline.IsForbidden(err):
+		return ReasonInaccessible, nil
+	case err != nil:
+		return "", err
+	case doc == nil:
+		return "", nil
+	case !doc.DeletedAt.IsZero():
+		return ReasonDeleted, nil
+	case !doc.ArchivedAt.IsZero():
+		return ReasonArchived, nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,84 @@
+package lint_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/lint"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+func TestCheckLinks(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	ops := srv.AddCollection(outline.Collection{Name: "Ops"})
+	dev := srv.AddCollection(outline.Collection{Name: "Dev"})
+	fine := srv.AddDocument(outline.Document{CollectionID: dev.ID, Title: "Fine"})
+	archived := srv.AddDocument(outline.Document{CollectionID: dev.ID, Title: "Archived"})
+	_, err := cl.Documents().Archive(archived.ID).Do(ctx)
+	require.NoError(t, err)
+	deleted := srv.AddDocument(outline.Document{CollectionID: dev.ID, Title: "Deleted"})
+	require.NoError(t, cl.Documents().Delete(deleted.ID).Do(ctx))
+	shareID := srv.ShareDocument(fine.ID)
+	img := srv.AddAttachment("diagram.png", "image/png", []byte("png"), fine.ID)
+
+	source := srv.AddDocument(outline.Document{
+		CollectionID: ops.ID,
+		Title:        "Runbook",
+		Text: "See [fine](/doc/fine-" + fine.URLID + ") and [archived](/doc/archived-" + archived.URLID + ").\n" +
+			"![diagram](" + img + ")\n" +
+			"[gone](https://wiki.example.com/doc/deleted-" + deleted.URLID + "#setup)\n" +
+			"[share](/s/" + string(shareID) + ") [revoked](/s/2f1c9a5e-0b7d-4e8f-a6c3-9d2e1f0b4a7c)\n" +
+			"![lost](/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b)\n" +
+			"[external](https://example.com/doc/other-aB3dE5gH7j) `[code](/doc/x-aB3dE5gH7j)`\n" +
+			"[again](/doc/archived-" + archived.URLID + ")",
+	})
+
+	broken, err := lint.CheckLinks(ctx, cl, lint.WithHosts("WIKI.example.com"))
+	require.NoError(t, err)
+
+	want := []lint.BrokenLink{
+		{Line: 1, URL: "/doc/archived-" + archived.URLID, Reason: lint.ReasonArchived},
+		{Line: 3, URL: "https://wiki.example.com/doc/deleted-" + deleted.URLID + "#setup", Reason: lint.ReasonNotFound},
+		{Line: 4, URL: "/s/2f1c9a5e-0b7d-4e8f-a6c3-9d2e1f0b4a7c", Reason: lint.ReasonNotFound},
+		{Line: 5, URL: "/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b", Reason: lint.ReasonNotFound},
+		{Line: 7, URL: "/doc/archived-" + archived.URLID, Reason: lint.ReasonArchived},
+	}
+	for i := range want {
+		want[i].CollectionID = ops.ID
+		want[i].DocumentID = source.ID
+		want[i].DocumentTitle = "Runbook"
+	}
+	assert.Equal(t, want, broken)
+	assert.Equal(t, "Runbook ("+string(source.ID)+"):1: /doc/archived-"+archived.URLID+": archived", broken[0].String())
+
+	// Both published documents are fetched, documents of the structures are not checked again and every other target
+	// only once.
+	assert.Equal(t, 2+4, srv.RequestCount(common.DocumentsGetEndpoint()))
+
+	// Without the host absolute links are not checked.
+	broken, err = lint.CheckLinks(ctx, cl)
+	require.NoError(t, err)
+	assert.Len(t, broken, 4)
+}
+
+func TestCheckLinks_error(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	srv.InjectFault(outlinetest.Fault{Endpoint: common.CollectionsListEndpoint(), Status: http.StatusInternalServerError})
+
+	_, err := lint.CheckLinks(context.Background(), srv.OutlineClient())
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+}
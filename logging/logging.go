@@ -0,0 +1,54 @@
+// Package logging provides an [outline.Middleware] which logs every call made by an outline client using
+// [log/slog].
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// Middleware returns a middleware logging every call with logger. Successful calls are logged at info level, calls
+// rejected by the server (4XX) at warn level and failed calls (5XX or no response at all) at error level. The request
+// body is included only if logger has debug level enabled since it might contain the whole document text.
+func Middleware(logger *slog.Logger) outline.Middleware {
+	return func(next outline.Invoker) outline.Invoker {
+		return func(call *outline.Call) *outline.CallResult {
+			res := next(call)
+
+			ctx := call.Request.Context()
+			level := levelOf(res)
+			if !logger.Enabled(ctx, level) {
+				return res
+			}
+
+			attrs := []slog.Attr{
+				slog.String("endpoint", call.Endpoint),
+				slog.Int("status", res.Status),
+				slog.Duration("duration", res.Duration),
+				slog.Int("body_size", len(call.Body)),
+			}
+			if logger.Enabled(ctx, slog.LevelDebug) && len(call.Body) > 0 {
+				attrs = append(attrs, slog.String("body", string(call.Body)))
+			}
+			if res.Err != nil {
+				attrs = append(attrs, slog.String("error", res.Err.Error()))
+			}
+			logger.LogAttrs(ctx, level, "outline call", attrs...)
+
+			return res
+		}
+	}
+}
+
+// levelOf returns the level at which res should be logged.
+func levelOf(res *outline.CallResult) slog.Level {
+	switch {
+	case res.Err != nil || res.Status >= 500:
+		return slog.LevelError
+	case res.Status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,80 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/testutils"
+	"github.com/ioki-mobility/go-outline/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(level slog.Level, status int) (*outline.Client, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level}))
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:       r,
+			StatusCode:    status,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {}}`)),
+		}, nil
+	}}
+
+	return outline.New("https://wiki.example.com", hc, "api key", outline.WithMiddleware(logging.Middleware(logger))), buf
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		status        int
+		expectedLevel string
+	}{
+		"success":      {status: http.StatusOK, expectedLevel: "INFO"},
+		"client error": {status: http.StatusNotFound, expectedLevel: "WARN"},
+		"server error": {status: http.StatusBadGateway, expectedLevel: "ERROR"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl, buf := testClient(slog.LevelInfo, test.status)
+			_, _ = cl.Documents().Update("doc1").Text("secret text").Do(context.Background())
+
+			entry := map[string]any{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, test.expectedLevel, entry["level"])
+			assert.Equal(t, "outline call", entry["msg"])
+			assert.Equal(t, "documents.update", entry["endpoint"])
+			assert.Equal(t, float64(test.status), entry["status"])
+			assert.Contains(t, entry, "duration")
+			assert.NotContains(t, entry, "body")
+		})
+	}
+}
+
+func TestMiddleware_debugIncludesBody(t *testing.T) {
+	cl, buf := testClient(slog.LevelDebug, http.StatusOK)
+	_, err := cl.Documents().Update("doc1").Text("some text").Do(context.Background())
+	require.NoError(t, err)
+
+	entry := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.JSONEq(t, `{"id":"doc1","text":"some text"}`, entry["body"].(string))
+}
+
+func TestMiddleware_disabledLevel(t *testing.T) {
+	cl, buf := testClient(slog.LevelError, http.StatusOK)
+	_, err := cl.Documents().Update("doc1").Do(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,494 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// objectRune stands in for inlines other than text when telling whether a delimiter run opens or closes emphasis.
+const objectRune = '\uFFFC'
+
+var (
+	delimiterRowPattern = regexp.MustCompile(`^\|?(?:\s*:?-+:?\s*\|)*\s*:?-+:?\s*\|?$`)
+	// safeSchemes are the schemes of links which are rendered, other links e.g. javascript: could run code.
+	safeSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true}
+)
+
+// HTML renders the document as HTML fragment e.g. to publish it outside of outline. Lists, tables, emphasis and
+// the other constructs of the dialect are rendered as their HTML counterparts:
+//   - notices become <div class="notice notice-info"> and alike
+//   - math becomes <span class="math"> and <div class="math"> holding the LaTeX, which is left to e.g. KaTeX
+//   - mentions become <span class="mention"> holding the name
+//   - embeds become a paragraph with class embed holding a link
+//
+// Headings get the ids outline uses as anchors e.g. h-setup hence links to headings keep working. HTML within the
+// text is escaped, as are links with a scheme other than http, https, mailto and tel.
+func (d *Document) HTML() string {
+	w := &htmlWriter{b: &strings.Builder{}, ids: map[string]int{}}
+	w.blocks(d.Blocks)
+	return w.b.String()
+}
+
+type htmlWriter struct {
+	b *strings.Builder
+	// ids counts the headings by anchor to tell headings with the same text apart.
+	ids map[string]int
+}
+
+func (w *htmlWriter) blocks(blocks []Block) {
+	for i := 0; i < len(blocks); i++ {
+		switch b := blocks[i].(type) {
+		case *Paragraph:
+			w.b.WriteString("<p>" + inlinesHTML(b.Content) + "</p>\n")
+		case *Heading:
+			fmt.Fprintf(w.b, "<h%d id=\"%s\">%s</h%d>\n", b.Level, w.headingID(b), inlinesHTML(b.Content), b.Level)
+		case *ListItem:
+			// Consecutive items form a list, blank lines between items do not end it.
+			items := []*ListItem{b}
+			for j := i + 1; j < len(blocks); j++ {
+				if li, ok := blocks[j].(*ListItem); ok {
+					items = append(items, li)
+					i = j
+				} else if _, ok := blocks[j].(*BlankLine); !ok {
+					break
+				}
+			}
+			w.list(items)
+		case *CodeBlock:
+			class := ""
+			if lang, _, _ := strings.Cut(strings.TrimSpace(b.Info), " "); lang != "" {
+				class = ` class="language-` + html.EscapeString(lang) + `"`
+			}
+			w.b.WriteString("<pre><code" + class + ">" + html.EscapeString(b.Code) + "</code></pre>\n")
+		case *MathBlock:
+			w.b.WriteString(`<div class="math">` + html.EscapeString(b.Math) + "</div>\n")
+		case *Notice:
+			w.b.WriteString(`<div class="notice notice-` + html.EscapeString(b.Style) + "\">\n")
+			w.blocks(b.Blocks)
+			w.b.WriteString("</div>\n")
+		case *Quote:
+			w.b.WriteString("<blockquote>\n")
+			w.blocks(b.Blocks)
+			w.b.WriteString("</blockquote>\n")
+		case *Table:
+			w.table(b)
+		case *HorizontalRule:
+			w.b.WriteString("<hr>\n")
+		case *Embed:
+			u := safeURL(b.URL)
+			w.b.WriteString(`<p class="embed"><a href="` + u + `">` + u + "</a></p>\n")
+		}
+	}
+}
+
+// headingID returns the anchor of h which is its text in lower case with runs of other characters than letters and
+// digits replaced by dashes, prefixed by h-. Repeated anchors get a counter appended e.g. h-setup-1.
+func (w *htmlWriter) headingID(h *Heading) string {
+	id := "h-" + slug(h.Text())
+	n := w.ids[id]
+	w.ids[id]++
+	if n > 0 {
+		id += fmt.Sprintf("-%d", n)
+	}
+	return html.EscapeString(id)
+}
+
+// list renders the items of a list, nested lists are opened within the item they follow.
+func (w *htmlWriter) list(items []*ListItem) {
+	type level struct {
+		depth int
+		tag   string
+	}
+	open := []level{}
+	for _, li := range items {
+		depth, tag := li.Depth(), "ul"
+		if strings.IndexFunc(li.Marker, unicode.IsDigit) == 0 {
+			tag = "ol"
+		}
+		for len(open) > 0 {
+			top := open[len(open)-1]
+			if top.depth < depth || (top.depth == depth && top.tag == tag) {
+				break
+			}
+			w.b.WriteString("</li>\n</" + top.tag + ">\n")
+			open = open[:len(open)-1]
+		}
+
+		if len(open) > 0 && open[len(open)-1].depth == depth {
+			w.b.WriteString("</li>\n")
+		} else {
+			attrs := ""
+			if start := strings.TrimRight(li.Marker, ".)"); tag == "ol" && start != "1" {
+				attrs = ` start="` + strings.TrimLeft(start, "0") + `"`
+			}
+			if li.IsTask() {
+				attrs = ` class="checklist"`
+			}
+			if len(open) > 0 {
+				w.b.WriteString("\n")
+			}
+			w.b.WriteString("<" + tag + attrs + ">\n")
+			open = append(open, level{depth: depth, tag: tag})
+		}
+
+		w.b.WriteString("<li>")
+		if li.Done() {
+			w.b.WriteString(`<input type="checkbox" disabled checked> `)
+		} else if li.IsTask() {
+			w.b.WriteString(`<input type="checkbox" disabled> `)
+		}
+		w.b.WriteString(inlinesHTML(li.Content))
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		w.b.WriteString("</li>\n</" + open[i].tag + ">\n")
+	}
+}
+
+// table renders t with its first row as header if the second row is the delimiter row.
+func (w *htmlWriter) table(t *Table) {
+	rows := tableRows(t.Content)
+	var align []string
+	if len(rows) > 1 && delimiterRowPattern.MatchString(strings.TrimSpace(rows[1].String())) {
+		for _, cell := range splitCells(rows[1]) {
+			s := strings.TrimSpace(cell.String())
+			switch {
+			case strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":"):
+				align = append(align, "center")
+			case strings.HasSuffix(s, ":"):
+				align = append(align, "right")
+			case strings.HasPrefix(s, ":"):
+				align = append(align, "left")
+			default:
+				align = append(align, "")
+			}
+		}
+		rows = append(rows[:1:1], rows[2:]...)
+	}
+
+	w.b.WriteString("<table>\n")
+	if align != nil {
+		w.b.WriteString("<thead>\n")
+		w.row(rows[0], "th", align)
+		w.b.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	if len(rows) > 0 {
+		w.b.WriteString("<tbody>\n")
+		for _, row := range rows {
+			w.row(row, "td", align)
+		}
+		w.b.WriteString("</tbody>\n")
+	}
+	w.b.WriteString("</table>\n")
+}
+
+// row renders a row of a table using tag for its cells.
+func (w *htmlWriter) row(row Inlines, tag string, align []string) {
+	w.b.WriteString("<tr>")
+	for i, cell := range splitCells(row) {
+		attrs := ""
+		if i < len(align) && align[i] != "" {
+			attrs = ` style="text-align: ` + align[i] + `"`
+		}
+		w.b.WriteString("<" + tag + attrs + ">" + strings.TrimSpace(inlinesHTML(cell)) + "</" + tag + ">")
+	}
+	w.b.WriteString("</tr>\n")
+}
+
+// tableRows splits the content of a table into its lines.
+func tableRows(content Inlines) []Inlines {
+	rows := []Inlines{{}}
+	for _, in := range content {
+		t, ok := in.(*Text)
+		if !ok {
+			rows[len(rows)-1] = append(rows[len(rows)-1], in)
+			continue
+		}
+		for i, part := range strings.Split(t.Value, "\n") {
+			if i > 0 {
+				rows = append(rows, Inlines{})
+			}
+			if part != "" {
+				rows[len(rows)-1] = append(rows[len(rows)-1], &Text{Value: part, Line: t.Line + i})
+			}
+		}
+	}
+	return rows
+}
+
+// splitCells splits a row of a table at pipes which are not escaped. The pipes at the start and end of the row are
+// not taken as separators.
+func splitCells(row Inlines) []Inlines {
+	cells := []Inlines{{}}
+	for _, in := range row {
+		t, ok := in.(*Text)
+		if !ok {
+			cells[len(cells)-1] = append(cells[len(cells)-1], in)
+			continue
+		}
+		start := 0
+		for i := 0; i < len(t.Value); i++ {
+			switch t.Value[i] {
+			case '\\':
+				i++
+			case '|':
+				if i > start {
+					cells[len(cells)-1] = append(cells[len(cells)-1], &Text{Value: t.Value[start:i], Line: t.Line})
+				}
+				cells = append(cells, Inlines{})
+				start = i + 1
+			}
+		}
+		if start < len(t.Value) {
+			cells[len(cells)-1] = append(cells[len(cells)-1], &Text{Value: t.Value[start:], Line: t.Line})
+		}
+	}
+
+	if len(cells) > 1 && strings.TrimSpace(cells[0].String()) == "" {
+		cells = cells[1:]
+	}
+	if len(cells) > 1 && strings.TrimSpace(cells[len(cells)-1].String()) == "" {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
+
+// inlinesHTML renders ins as HTML. Emphasis is matched via a delimiter stack, see matchEmphasis, hence it can span
+// e.g. links.
+func inlinesHTML(ins Inlines) string {
+	tokens := []*htmlToken{}
+	for _, in := range ins {
+		if t, ok := in.(*Text); ok {
+			tokens = append(tokens, delimiterRuns(textHTML(t.Value))...)
+			continue
+		}
+		tokens = append(tokens, &htmlToken{html: inlineHTML(in), inline: true})
+	}
+	matchEmphasis(tokens)
+
+	b := &strings.Builder{}
+	for _, t := range tokens {
+		for _, tag := range t.closes {
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString(t.html)
+		b.WriteString(strings.Repeat(string(t.delim), t.n))
+		for i := len(t.opens) - 1; i >= 0; i-- {
+			b.WriteString("<" + t.opens[i] + ">")
+		}
+	}
+	return b.String()
+}
+
+// htmlToken is a piece of rendered inlines: HTML or a run of emphasis delimiters.
+type htmlToken struct {
+	html string
+	// inline is true for the HTML of inlines other than text.
+	inline bool
+	// delim is the character of a run of delimiters, the number of delimiters not used for emphasis is n.
+	delim byte
+	n     int
+	// opens and closes are the tags of the emphasis opened and closed by the run, innermost first.
+	opens, closes []string
+}
+
+// delimiterRuns splits the escaped text s into HTML and runs of emphasis delimiters. Escaped delimiters are character
+// references hence they are not taken as delimiters. Tildes and equal signs only delimit in pairs.
+func delimiterRuns(s string) []*htmlToken {
+	tokens := []*htmlToken{}
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c != '*' && c != '_' && c != '~' && c != '=' {
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(s) && s[i+n] == c {
+			n++
+		}
+		if (c == '~' || c == '=') && n != 2 {
+			i += n
+			continue
+		}
+		if i > start {
+			tokens = append(tokens, &htmlToken{html: s[start:i]})
+		}
+		tokens = append(tokens, &htmlToken{delim: c, n: n})
+		i += n
+		start = i
+	}
+	if start < len(s) {
+		tokens = append(tokens, &htmlToken{html: s[start:]})
+	}
+	return tokens
+}
+
+// matchEmphasis matches the delimiter runs of tokens similar to CommonMark. A run opens emphasis if it is followed by
+// other than whitespace, and closes it if it is preceded by other than whitespace. Underscores additionally must not
+// be within a word. A closing run is matched with the closest opening run of the same character. Runs in between are
+// taken literally hence emphasis of different kinds never overlaps e.g. in *a ~~b* c~~. Emphasis does not span lines.
+func matchEmphasis(tokens []*htmlToken) {
+	openers := []*htmlToken{}
+	for i, t := range tokens {
+		if t.delim == 0 {
+			if strings.Contains(t.html, "\n") {
+				openers = openers[:0]
+			}
+			continue
+		}
+
+		prev, next := ' ', ' '
+		if i > 0 {
+			prev = lastRune(tokens[i-1])
+		}
+		if i+1 < len(tokens) {
+			next = firstRune(tokens[i+1])
+		}
+		canOpen := !unicode.IsSpace(next) && (t.delim != '_' || !isWordRune(prev))
+		canClose := !unicode.IsSpace(prev) && (t.delim != '_' || !isWordRune(next))
+
+		for canClose && t.n > 0 {
+			j := len(openers) - 1
+			for j >= 0 && openers[j].delim != t.delim {
+				j--
+			}
+			if j < 0 {
+				break
+			}
+			o := openers[j]
+			n := min(o.n, t.n, 2)
+			tag := emphasisTag(t.delim, n)
+			o.n -= n
+			t.n -= n
+			o.opens = append(o.opens, tag)
+			t.closes = append(t.closes, tag)
+
+			// Runs between the opener and the closer can no longer be matched.
+			openers = openers[:j+1]
+			if o.n == 0 {
+				openers = openers[:j]
+			}
+		}
+		if canOpen && t.n > 0 {
+			openers = append(openers, t)
+		}
+	}
+}
+
+// emphasisTag returns the tag of emphasis delimited by n times c.
+func emphasisTag(c byte, n int) string {
+	switch {
+	case c == '~':
+		return "del"
+	case c == '=':
+		return "mark"
+	case n == 2:
+		return "strong"
+	default:
+		return "em"
+	}
+}
+
+func firstRune(t *htmlToken) rune {
+	if t.inline {
+		return objectRune
+	}
+	if t.delim != 0 {
+		return rune(t.delim)
+	}
+	r, _ := utf8.DecodeRuneInString(t.html)
+	return r
+}
+
+func lastRune(t *htmlToken) rune {
+	if t.inline {
+		return objectRune
+	}
+	if t.delim != 0 {
+		return rune(t.delim)
+	}
+	r, _ := utf8.DecodeLastRuneInString(t.html)
+	return r
+}
+
+// isWordRune returns true for the characters of words as far as underscores are concerned i.e. ASCII letters, digits
+// and underscores.
+func isWordRune(r rune) bool {
+	return r == '_' || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
+
+func inlineHTML(in Inline) string {
+	switch in := in.(type) {
+	case *Code:
+		return "<code>" + html.EscapeString(in.Value) + "</code>"
+	case *Math:
+		return `<span class="math">` + html.EscapeString(in.Value) + "</span>"
+	case *Mention:
+		return `<span class="mention">@` + textHTML(in.Name) + "</span>"
+	case *Link:
+		title := ""
+		if in.Title != "" {
+			title = ` title="` + html.EscapeString(unescape(in.Title)) + `"`
+		}
+		if in.Image {
+			alt := html.EscapeString(parseInlines(in.Text, in.Line).PlainText())
+			return `<img src="` + safeURL(in.URL) + `" alt="` + alt + `"` + title + ">"
+		}
+		return `<a href="` + safeURL(in.URL) + `"` + title + ">" + inlinesHTML(parseInlines(in.Text, in.Line)) + "</a>"
+	}
+	return ""
+}
+
+// textHTML escapes s. Escaped punctuation is turned into character references hence it is not taken as emphasis, and
+// a backslash at the end of a line into a line break.
+func textHTML(s string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\\' && strings.HasPrefix(s[i+1:], "\n"):
+			b.WriteString("<br>\n")
+			n++
+		case r == '\\' && i+1 < len(s) && escapePattern.MatchString(s[i:i+2]):
+			fmt.Fprintf(b, "&#%d;", s[i+1])
+			n++
+		case r != '\r':
+			b.WriteString(html.EscapeString(s[i : i+n]))
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// safeURL returns the escaped url u, or # for urls with a scheme which could run code e.g. javascript.
+func safeURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "" && !safeSchemes[strings.ToLower(parsed.Scheme)]) {
+		return "#"
+	}
+	return html.EscapeString(u)
+}
+
+// slug returns s in lower case with runs of other characters than letters and digits replaced by single dashes.
+func slug(s string) string {
+	b := &strings.Builder{}
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,232 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	linkPattern = regexp.MustCompile(
+		`^(!?)\[((?:\\.|[^\\\]\n])*)\]\(([^\s()]*)(?: "((?:\\.|[^"\\\n])+)")?\)`,
+	)
+	mentionPattern = regexp.MustCompile(`^@\[((?:\\.|[^\\\]\n])*)\]\((mention://[^\s()]*)\)`)
+	mathPattern    = regexp.MustCompile(`^\$([^\s$](?:[^$\n]*[^\s$])?)\$`)
+)
+
+// Inlines is the content of a block.
+type Inlines []Inline
+
+func (ins Inlines) String() string {
+	b := &strings.Builder{}
+	for _, in := range ins {
+		b.WriteString(in.String())
+	}
+	return b.String()
+}
+
+// PlainText returns the content without markup e.g. the text of links instead of the links.
+func (ins Inlines) PlainText() string {
+	b := &strings.Builder{}
+	for _, in := range ins {
+		switch in := in.(type) {
+		case *Text:
+			b.WriteString(unescape(in.Value))
+		case *Code:
+			b.WriteString(in.Value)
+		case *Math:
+			b.WriteString(in.Value)
+		case *Link:
+			b.WriteString(unescape(in.Text))
+		case *Mention:
+			b.WriteString(unescape(in.Name))
+		}
+	}
+	return b.String()
+}
+
+// Text is text without markup known to this package. Emphasis, escapes and the like are kept as is.
+type Text struct {
+	Value string
+	Line  int
+}
+
+func (t *Text) String() string {
+	return t.Value
+}
+
+// Code is a code span e.g. `go test`
+type Code struct {
+	Value string
+	// Ticks is the number of backticks enclosing the code.
+	Ticks int
+	Line  int
+}
+
+func (c *Code) String() string {
+	ticks := strings.Repeat("`", max(c.Ticks, 1))
+	return ticks + c.Value + ticks
+}
+
+// Math is inline LaTeX e.g. $e^{i\pi}$
+type Math struct {
+	Value string
+	Line  int
+}
+
+func (m *Math) String() string {
+	return "$" + m.Value + "$"
+}
+
+// Link is a link e.g. [Setup](/doc/setup-hDYep1TPAM) or an image e.g. ![Diagram](/api/attachments.redirect?id=…).
+type Link struct {
+	Image bool
+	// Text is the text of a link, or the alternative text of an image, as is i.e. including markup.
+	Text  string
+	URL   string
+	Title string
+	Line  int
+}
+
+func (l *Link) String() string {
+	s := "[" + l.Text + "](" + l.URL
+	if l.Image {
+		s = "!" + s
+	}
+	if l.Title != "" {
+		s += ` "` + l.Title + `"`
+	}
+	return s + ")"
+}
+
+// AttachmentID returns the id of the attachment the link points to. False is returned for links to anything else.
+func (l *Link) AttachmentID() (string, bool) {
+	m := attachmentURLPattern.FindStringSubmatch(l.URL)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Mention is a reference to a user or document e.g. @[Jane](mention://9a7f…/user/3c1e…)
+type Mention struct {
+	Name string
+	URL  string
+	Line int
+}
+
+func (m *Mention) String() string {
+	return "@[" + m.Name + "](" + m.URL + ")"
+}
+
+// Type returns the type of the mentioned model e.g. user or document.
+func (m *Mention) Type() string {
+	_, typ, _ := m.parts()
+	return typ
+}
+
+// ModelID returns the id of the mentioned model e.g. the id of the user.
+func (m *Mention) ModelID() string {
+	_, _, id := m.parts()
+	return id
+}
+
+// parts splits the url mention://<mention id>/<type>/<model id> into its parts.
+func (m *Mention) parts() (string, string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(m.URL, "mention://"), "/", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+func (*Text) node()    {}
+func (*Code) node()    {}
+func (*Math) node()    {}
+func (*Link) node()    {}
+func (*Mention) node() {}
+
+func (*Text) inline()    {}
+func (*Code) inline()    {}
+func (*Math) inline()    {}
+func (*Link) inline()    {}
+func (*Mention) inline() {}
+
+// parseInlines parses s which starts at line.
+func parseInlines(s string, line int) Inlines {
+	ins := Inlines{}
+	text := &strings.Builder{}
+	textLine := line
+	flush := func() {
+		if text.Len() > 0 {
+			ins = append(ins, &Text{Value: text.String(), Line: textLine})
+			text.Reset()
+		}
+		textLine = line
+	}
+
+	for i := 0; i < len(s); {
+		var in Inline
+		n := 0
+		switch rest := s[i:]; s[i] {
+		case '\\':
+			n = min(2, len(rest))
+		case '`':
+			in, n = parseCode(rest, line)
+		case '$':
+			if m := mathPattern.FindStringSubmatch(rest); m != nil {
+				in, n = &Math{Value: m[1], Line: line}, len(m[0])
+			}
+		case '@':
+			if m := mentionPattern.FindStringSubmatch(rest); m != nil {
+				in, n = &Mention{Name: m[1], URL: m[2], Line: line}, len(m[0])
+			}
+		case '!', '[':
+			if m := linkPattern.FindStringSubmatch(rest); m != nil {
+				in, n = &Link{Image: m[1] == "!", Text: m[2], URL: m[3], Title: m[4], Line: line}, len(m[0])
+			}
+		}
+
+		if in == nil {
+			// Escapes and unmatched markup are text, a backtick run must be kept whole to not start a code span.
+			n = max(n, 1)
+			text.WriteString(s[i : i+n])
+			line += strings.Count(s[i:i+n], "\n")
+		} else {
+			flush()
+			ins = append(ins, in)
+			line += strings.Count(s[i:i+n], "\n")
+			textLine = line
+		}
+		i += n
+	}
+	flush()
+
+	return ins
+}
+
+// parseCode parses the code span at the start of s. If there is none the length of the backtick run is returned hence
+// it is taken as text.
+func parseCode(s string, line int) (Inline, int) {
+	ticks := len(s) - len(strings.TrimLeft(s, "`"))
+	rest := s[ticks:]
+	for i := 0; i < len(rest); {
+		j := strings.Index(rest[i:], "`")
+		if j < 0 {
+			break
+		}
+		start := i + j
+		end := start + len(rest[start:]) - len(strings.TrimLeft(rest[start:], "`"))
+		if end-start == ticks {
+			return &Code{Value: rest[:start], Ticks: ticks, Line: line}, ticks + end
+		}
+		i = end
+	}
+	return nil, ticks
+}
+
+var escapePattern = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+
+// unescape removes backslashes escaping punctuation.
+func unescape(s string) string {
+	return escapePattern.ReplaceAllString(s, "$1")
+}
@@ -0,0 +1,358 @@
+// Package markdown parses the Markdown dialect outline uses for the text of documents, see [outline.Document].
+//
+// Besides common Markdown the dialect has notices (:::info … :::), checklists (- [x] done), mentions
+// (@[Jane](mention://…)), math ($x$ and $$ blocks) and embeds, which are links on a line of their own whose text is
+// their url. Attachments are plain links and images pointing to /api/attachments.redirect.
+//
+// Parsing is lossless: rendering a parsed text via [Document.String] returns the text as is. Constructs not covered by
+// the node types, e.g. emphasis or html, are kept as text. Nodes can be changed before rendering which allows e.g.
+// rewriting links without touching anything else. [Document.HTML] renders a text as HTML e.g. to publish it outside of
+// outline.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Node is a part of a parsed text. The String method renders the node as Markdown.
+type Node interface {
+	String() string
+	node()
+}
+
+// Block is a node made of whole lines.
+type Block interface {
+	Node
+	block()
+}
+
+// Inline is a node within the text of a block.
+type Inline interface {
+	Node
+	inline()
+}
+
+// Document is a parsed text.
+type Document struct {
+	Blocks []Block
+	// TrailingNewline is true if the text ends with a line break.
+	TrailingNewline bool
+}
+
+func (d *Document) String() string {
+	s := joinBlocks(d.Blocks)
+	if d.TrailingNewline {
+		s += "\n"
+	}
+	return s
+}
+
+// BlankLine is an empty line, or one made of white space only.
+type BlankLine struct {
+	Raw  string
+	Line int
+}
+
+func (b *BlankLine) String() string {
+	return b.Raw
+}
+
+// Paragraph is a run of lines not belonging to any other block.
+type Paragraph struct {
+	Content Inlines
+	Line    int
+}
+
+func (p *Paragraph) String() string {
+	return p.Content.String()
+}
+
+// Heading is an ATX heading e.g. ## Setup
+type Heading struct {
+	Level   int
+	Content Inlines
+	Line    int
+}
+
+func (h *Heading) String() string {
+	return strings.Repeat("#", h.Level) + " " + h.Content.String()
+}
+
+// Text returns the text of the heading without markup.
+func (h *Heading) Text() string {
+	return h.Content.PlainText()
+}
+
+// ListItem is an item of a bullet, ordered or check list. Items of nested lists are indented.
+type ListItem struct {
+	Indent string
+	// Marker is the bullet e.g. "-" or the number e.g. "1."
+	Marker string
+	// Task is the check box of checklist items i.e. "[ ]", "[x]" or "[X]". It is empty for other items.
+	Task    string
+	Content Inlines
+	Line    int
+}
+
+func (li *ListItem) String() string {
+	s := li.Indent + li.Marker + " "
+	if li.Task != "" {
+		s += li.Task + " "
+	}
+	return s + li.Content.String()
+}
+
+// IsTask returns true if the item is part of a checklist.
+func (li *ListItem) IsTask() bool {
+	return li.Task != ""
+}
+
+// Done returns true if the item is a checked checklist item.
+func (li *ListItem) Done() bool {
+	return li.Task == "[x]" || li.Task == "[X]"
+}
+
+// SetDone turns the item into a checklist item which is checked or not.
+func (li *ListItem) SetDone(done bool) {
+	li.Task = "[ ]"
+	if done {
+		li.Task = "[x]"
+	}
+}
+
+// Depth returns the nesting level of the item, top level items have depth 0. Every two spaces or tab of indentation
+// are one level.
+func (li *ListItem) Depth() int {
+	return (len(strings.ReplaceAll(li.Indent, "\t", "  ")) + 1) / 2
+}
+
+// CodeBlock is a fenced code block.
+type CodeBlock struct {
+	// Fence is the line of backticks or tildes the block starts and ends with.
+	Fence string
+	Info  string
+	// Code holds the lines of the block, each followed by a line break.
+	Code string
+	// Unclosed is true for blocks which run until the end of the text without closing fence.
+	Unclosed bool
+	Line     int
+}
+
+func (c *CodeBlock) String() string {
+	return fenced(c.Fence+c.Info, c.Code, c.Fence, c.Unclosed)
+}
+
+// MathBlock is a block of LaTeX enclosed by lines of $$.
+type MathBlock struct {
+	// Math holds the lines of the block, each followed by a line break.
+	Math     string
+	Unclosed bool
+	Line     int
+}
+
+func (m *MathBlock) String() string {
+	return fenced(mathFence, m.Math, mathFence, m.Unclosed)
+}
+
+// Notice is a highlighted block e.g. :::info … ::: holding other blocks.
+type Notice struct {
+	// Style is e.g. info, warning, tip or success.
+	Style    string
+	Blocks   []Block
+	Unclosed bool
+	Line     int
+}
+
+func (n *Notice) String() string {
+	s := noticeFence + n.Style
+	if len(n.Blocks) > 0 {
+		s += "\n" + joinBlocks(n.Blocks)
+	}
+	if !n.Unclosed {
+		s += "\n" + noticeFence
+	}
+	return s
+}
+
+// Quote is a block quote holding other blocks.
+type Quote struct {
+	Blocks []Block
+	Line   int
+}
+
+func (q *Quote) String() string {
+	lines := strings.Split(joinBlocks(q.Blocks), "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Table is a table, its content holds all rows including the pipes separating the cells.
+type Table struct {
+	Content Inlines
+	Line    int
+}
+
+func (t *Table) String() string {
+	return t.Content.String()
+}
+
+// HorizontalRule is a thematic break e.g. ---
+type HorizontalRule struct {
+	Raw  string
+	Line int
+}
+
+func (hr *HorizontalRule) String() string {
+	return hr.Raw
+}
+
+// Embed is a link to external content shown inline e.g. a video. It is written as a link on a line of its own whose
+// text is its url.
+type Embed struct {
+	URL  string
+	Line int
+}
+
+func (e *Embed) String() string {
+	return "[" + e.URL + "](" + e.URL + ")"
+}
+
+func (*Document) node()       {}
+func (*BlankLine) node()      {}
+func (*Paragraph) node()      {}
+func (*Heading) node()        {}
+func (*ListItem) node()       {}
+func (*CodeBlock) node()      {}
+func (*MathBlock) node()      {}
+func (*Notice) node()         {}
+func (*Quote) node()          {}
+func (*Table) node()          {}
+func (*HorizontalRule) node() {}
+func (*Embed) node()          {}
+
+func (*BlankLine) block()      {}
+func (*Paragraph) block()      {}
+func (*Heading) block()        {}
+func (*ListItem) block()       {}
+func (*CodeBlock) block()      {}
+func (*MathBlock) block()      {}
+func (*Notice) block()         {}
+func (*Quote) block()          {}
+func (*Table) block()          {}
+func (*HorizontalRule) block() {}
+func (*Embed) block()          {}
+
+func joinBlocks(blocks []Block) string {
+	lines := make([]string, len(blocks))
+	for i, b := range blocks {
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fenced renders a block made of an opening line, content lines each followed by a line break and a closing line.
+func fenced(open string, content string, close string, unclosed bool) string {
+	s := open + "\n" + content
+	if unclosed {
+		return strings.TrimSuffix(s, "\n")
+	}
+	return s + close
+}
+
+// Walk calls fn for n and, as long as fn returns true, for the children of n depth first.
+func Walk(n Node, fn func(Node) bool) {
+	if !fn(n) {
+		return
+	}
+
+	var children []Node
+	switch n := n.(type) {
+	case *Document:
+		children = blockNodes(n.Blocks)
+	case *Notice:
+		children = blockNodes(n.Blocks)
+	case *Quote:
+		children = blockNodes(n.Blocks)
+	case *Paragraph:
+		children = inlineNodes(n.Content)
+	case *Heading:
+		children = inlineNodes(n.Content)
+	case *ListItem:
+		children = inlineNodes(n.Content)
+	case *Table:
+		children = inlineNodes(n.Content)
+	}
+	for _, c := range children {
+		Walk(c, fn)
+	}
+}
+
+func blockNodes(blocks []Block) []Node {
+	nodes := make([]Node, len(blocks))
+	for i, b := range blocks {
+		nodes[i] = b
+	}
+	return nodes
+}
+
+func inlineNodes(inlines Inlines) []Node {
+	nodes := make([]Node, len(inlines))
+	for i, in := range inlines {
+		nodes[i] = in
+	}
+	return nodes
+}
+
+// collect returns all nodes of type T below n in the order visited by [Walk].
+func collect[T Node](n Node) []T {
+	found := []T{}
+	Walk(n, func(n Node) bool {
+		if t, ok := n.(T); ok {
+			found = append(found, t)
+		}
+		return true
+	})
+	return found
+}
+
+// Headings returns all headings e.g. to build a table of contents.
+func (d *Document) Headings() []*Heading {
+	return collect[*Heading](d)
+}
+
+// Links returns all links and images, including the ones pointing to attachments. Embeds are not included, see
+// [Document.Embeds].
+func (d *Document) Links() []*Link {
+	return collect[*Link](d)
+}
+
+// Mentions returns all mentions.
+func (d *Document) Mentions() []*Mention {
+	return collect[*Mention](d)
+}
+
+// Embeds returns all embeds.
+func (d *Document) Embeds() []*Embed {
+	return collect[*Embed](d)
+}
+
+// Tasks returns the items of all checklists.
+func (d *Document) Tasks() []*ListItem {
+	tasks := []*ListItem{}
+	for _, li := range collect[*ListItem](d) {
+		if li.IsTask() {
+			tasks = append(tasks, li)
+		}
+	}
+	return tasks
+}
+
+// attachmentURLPattern matches the url of an attachment with or without the server's origin.
+var attachmentURLPattern = regexp.MustCompile(`^(?:https?://[^/]+)?/api/attachments\.redirect\?id=([0-9a-fA-F-]{36})$`)
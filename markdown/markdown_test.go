@@ -0,0 +1,253 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline/markdown"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testText = `# Runbook
+
+Ask @[Jane Doe](mention://4b1d2c3e/user/9f8e7d6c) before a [failover](/doc/failover-hDYep1TPAM "Failover").
+Use ` + "`pg_ctl promote`" + ` and check $x^2$ costs \$5.
+
+:::warning
+Do **not** skip this.
+
+- [ ] Stop writes
+- [x] Take backup
+  - Nested [link](https://example.com)
+:::
+
+![Diagram](/api/attachments.redirect?id=0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10 "Overview")
+[Report.pdf](https://wiki.example.com/api/attachments.redirect?id=1f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10)
+
+[https://www.youtube.com/watch?v=dQw4w9WgXcQ](https://www.youtube.com/watch?v=dQw4w9WgXcQ)
+
+` + "```sh" + `
+psql -c 'select [not](a link)'
+` + "```" + `
+
+$$
+\int_0^1 x\,dx
+$$
+
+> Quoted [source](/doc/source-aB3dE5gH7j)
+>
+> more
+
+| Name | Link |
+|------|------|
+| DB   | [DB](/doc/db-Zz9Yy8Xx7W) |
+
+---
+1. First
+2) Second
+## Unclosed
+` + "```" + `
+never closed`
+
+func TestParse_roundTrip(t *testing.T) {
+	tests := map[string]string{
+		"example":                  testText,
+		"empty":                    "",
+		"line break":               "\n",
+		"blank lines":              "a\n\n\n  \nb\n\n",
+		"windows line breaks":      "# Title\r\n\r\n- item\r\n",
+		"unclosed notice":          ":::info\ntext",
+		"empty notice":             ":::tip\n:::",
+		"stray closing":            ":::\ntext\n:::",
+		"unclosed math":            "$$\nx",
+		"empty code block":         "```\n```\n",
+		"code block with one line": "```go\n\n```",
+		"empty heading":            "# ",
+		"escaped link":             `\[not](a link) [a](b\)c)`,
+		"broken markup":            "[a](b c) ![x( `` ` $ $a $b $ @[x](y)",
+		"code span with ticks":     "``a ` b`` and ```c```",
+		"quote with blank padding": ">   indented\n>\n>    \n> end",
+		"not a quote":              ">no space\n> ",
+		"task without text":        "- [ ] \n- [x]",
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, text, markdown.Parse(text).String())
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	doc := markdown.Parse(testText)
+
+	headings := doc.Headings()
+	require.Len(t, headings, 2)
+	assert.Equal(t, 1, headings[0].Level)
+	assert.Equal(t, "Runbook", headings[0].Text())
+	assert.Equal(t, 1, headings[0].Line)
+	assert.Equal(t, "Unclosed", headings[1].Text())
+
+	mentions := doc.Mentions()
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "Jane Doe", mentions[0].Name)
+	assert.Equal(t, "user", mentions[0].Type())
+	assert.Equal(t, "9f8e7d6c", mentions[0].ModelID())
+	assert.Equal(t, 3, mentions[0].Line)
+
+	links := doc.Links()
+	urls := []string{}
+	for _, l := range links {
+		urls = append(urls, l.URL)
+	}
+	assert.Equal(t, []string{
+		"/doc/failover-hDYep1TPAM",
+		"https://example.com",
+		"/api/attachments.redirect?id=0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10",
+		"https://wiki.example.com/api/attachments.redirect?id=1f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10",
+		"/doc/source-aB3dE5gH7j",
+		"/doc/db-Zz9Yy8Xx7W",
+	}, urls)
+	assert.Equal(t, "Failover", links[0].Title)
+	assert.Equal(t, 3, links[0].Line)
+	assert.Equal(t, 11, links[1].Line)
+	assert.True(t, links[2].Image)
+	id, ok := links[2].AttachmentID()
+	assert.True(t, ok)
+	assert.Equal(t, "0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10", id)
+	id, ok = links[3].AttachmentID()
+	assert.True(t, ok)
+	assert.Equal(t, "1f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10", id)
+	_, ok = links[0].AttachmentID()
+	assert.False(t, ok)
+	assert.Equal(t, 27, links[4].Line)
+	assert.Equal(t, 33, links[5].Line)
+
+	embeds := doc.Embeds()
+	require.Len(t, embeds, 1)
+	assert.Equal(t, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", embeds[0].URL)
+
+	tasks := doc.Tasks()
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "Stop writes", tasks[0].Content.PlainText())
+	assert.False(t, tasks[0].Done())
+	assert.True(t, tasks[1].Done())
+
+	var notice *markdown.Notice
+	var math *markdown.MathBlock
+	var code *markdown.CodeBlock
+	markdown.Walk(doc, func(n markdown.Node) bool {
+		switch n := n.(type) {
+		case *markdown.Notice:
+			notice = n
+		case *markdown.MathBlock:
+			math = n
+		case *markdown.CodeBlock:
+			if code == nil {
+				code = n
+			}
+		}
+		return true
+	})
+	require.NotNil(t, notice)
+	assert.Equal(t, "warning", notice.Style)
+	assert.Equal(t, 6, notice.Line)
+	require.NotNil(t, math)
+	assert.Equal(t, "\\int_0^1 x\\,dx\n", math.Math)
+	require.NotNil(t, code)
+	assert.Equal(t, "sh", code.Info)
+	assert.Equal(t, "psql -c 'select [not](a link)'\n", code.Code)
+}
+
+func TestDocument_modify(t *testing.T) {
+	doc := markdown.Parse("- [ ] Deploy [docs](/doc/old-aB3dE5gH7j)\n\nSee `x`.\n")
+	for _, l := range doc.Links() {
+		l.URL = "/doc/new-aB3dE5gH7j"
+	}
+	for _, task := range doc.Tasks() {
+		task.SetDone(true)
+	}
+	assert.Equal(t, "- [x] Deploy [docs](/doc/new-aB3dE5gH7j)\n\nSee `x`.\n", doc.String())
+}
+
+func TestInlines_PlainText(t *testing.T) {
+	doc := markdown.Parse(`## Setup \[v2\] of [the *DB*](/doc/db) with ` + "`psql`" + ` for @[Jane](mention://a/user/b)`)
+	assert.Equal(t, "Setup [v2] of the *DB* with psql for Jane", doc.Headings()[0].Text())
+}
+
+func TestDocument_HTML(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want string
+	}{
+		"empty": {text: "", want: ""},
+		"paragraph": {
+			text: "Some **bold**, *em*, _em_ ~~gone~~ ==marked== and snake_case_name \\*not em\\* <b>&",
+			want: "<p>Some <strong>bold</strong>, <em>em</em>, <em>em</em> <del>gone</del> <mark>marked</mark> and " +
+				"snake_case_name &#42;not em&#42; &lt;b&gt;&amp;</p>\n",
+		},
+		"line break": {text: "one\\\ntwo", want: "<p>one<br>\ntwo</p>\n"},
+		"nested emphasis": {
+			text: "***all*** **bold *em* bold** *em **bold*** **[link](/doc/db)**",
+			want: "<p><em><strong>all</strong></em> <strong>bold <em>em</em> bold</strong> " +
+				"<em>em <strong>bold</strong></em> <strong><a href=\"/doc/db\">link</a></strong></p>\n",
+		},
+		"crossing emphasis": {
+			text: "*a ~~b* c~~ *a **b* c**",
+			want: "<p><em>a ~~b</em> c~~ <em>a <em><em>b</em> c</em></em></p>\n",
+		},
+		"many inlines": {
+			text: strings.Repeat("`x` ", 7000) + "*end*",
+			want: "<p>" + strings.Repeat("<code>x</code> ", 7000) + "<em>end</em></p>\n",
+		},
+		"headings": {
+			text: "# Setup\n## Setup\n### The *DB* (v2)",
+			want: "<h1 id=\"h-setup\">Setup</h1>\n<h2 id=\"h-setup-1\">Setup</h2>\n" +
+				"<h3 id=\"h-the-db-v2\">The <em>DB</em> (v2)</h3>\n",
+		},
+		"inlines": {
+			text: "**See [the *DB*](/doc/db-hDYep1TPAM \"DB\")** `a<b` $x^2$ @[Jane](mention://a/user/b) " +
+				"![A \\[diagram\\]](/api/attachments.redirect?id=x) [click](javascript:void)",
+			want: "<p><strong>See <a href=\"/doc/db-hDYep1TPAM\" title=\"DB\">the <em>DB</em></a></strong> " +
+				"<code>a&lt;b</code> <span class=\"math\">x^2</span> <span class=\"mention\">@Jane</span> " +
+				"<img src=\"/api/attachments.redirect?id=x\" alt=\"A [diagram]\"> <a href=\"#\">click</a></p>\n",
+		},
+		"lists": {
+			text: "- one\n  1. nested\n  2. nested\n- two\n\n- three\n\n3. third\n4. fourth",
+			want: "<ul>\n<li>one\n<ol>\n<li>nested</li>\n<li>nested</li>\n</ol>\n</li>\n<li>two</li>\n" +
+				"<li>three</li>\n</ul>\n<ol start=\"3\">\n<li>third</li>\n<li>fourth</li>\n</ol>\n",
+		},
+		"checklist": {
+			text: "- [x] done\n- [ ] todo",
+			want: "<ul class=\"checklist\">\n<li><input type=\"checkbox\" disabled checked> done</li>\n" +
+				"<li><input type=\"checkbox\" disabled> todo</li>\n</ul>\n",
+		},
+		"blocks": {
+			text: ":::warning\nDo **not**\n:::\n> quoted\n\n```go\nif a < b {}\n```\n$$\n\\int x\n$$\n---\n" +
+				"[https://example.com/v](https://example.com/v)",
+			want: "<div class=\"notice notice-warning\">\n<p>Do <strong>not</strong></p>\n</div>\n" +
+				"<blockquote>\n<p>quoted</p>\n</blockquote>\n" +
+				"<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>\n<div class=\"math\">\\int x\n</div>\n" +
+				"<hr>\n<p class=\"embed\"><a href=\"https://example.com/v\">https://example.com/v</a></p>\n",
+		},
+		"table": {
+			text: "| Name | Link |\n|:-----|:----:|\n| DB \\| primary | [DB](/doc/db) |",
+			want: "<table>\n<thead>\n<tr><th style=\"text-align: left\">Name</th>" +
+				"<th style=\"text-align: center\">Link</th></tr>\n</thead>\n<tbody>\n" +
+				"<tr><td style=\"text-align: left\">DB &#124; primary</td>" +
+				"<td style=\"text-align: center\"><a href=\"/doc/db\">DB</a></td></tr>\n</tbody>\n</table>\n",
+		},
+		"table without header": {
+			text: "| a | b |",
+			want: "<table>\n<tbody>\n<tr><td>a</td><td>b</td></tr>\n</tbody>\n</table>\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, markdown.Parse(tc.text).HTML())
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	mathFence   = "$$"
+	noticeFence = ":::"
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6}) (.*)$`)
+	fencePattern    = regexp.MustCompile("^(`{3,}|~{3,})(.*)$")
+	noticePattern   = regexp.MustCompile(`^:::([a-zA-Z]+)$`)
+	listItemPattern = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)]) (?:(\[[ xX]\]) )?(.*)$`)
+	rulePattern     = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+)
+
+// Parse parses text. Any text is valid Markdown hence there is no error, text which is not understood is kept as is.
+func Parse(text string) *Document {
+	d := &Document{}
+	if text == "" {
+		return d
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		d.TrailingNewline = true
+	}
+	d.Blocks = parseBlocks(lines, 1)
+
+	return d
+}
+
+// parseBlocks parses lines which start at line first.
+func parseBlocks(lines []string, first int) []Block {
+	blocks := []Block{}
+	for i := 0; i < len(lines); {
+		b, n := parseBlock(lines[i:], first+i)
+		blocks = append(blocks, b)
+		i += n
+	}
+	return blocks
+}
+
+// parseBlock parses the block at the start of lines and returns it along with the number of lines it spans.
+func parseBlock(lines []string, line int) (Block, int) {
+	l := lines[0]
+
+	if strings.TrimSpace(l) == "" {
+		return &BlankLine{Raw: l, Line: line}, 1
+	}
+	if m := fencePattern.FindStringSubmatch(l); m != nil && !strings.Contains(m[2], "`") {
+		code, n, unclosed := fencedLines(lines, m[1])
+		return &CodeBlock{Fence: m[1], Info: m[2], Code: code, Unclosed: unclosed, Line: line}, n
+	}
+	if l == mathFence {
+		math, n, unclosed := fencedLines(lines, mathFence)
+		return &MathBlock{Math: math, Unclosed: unclosed, Line: line}, n
+	}
+	if m := noticePattern.FindStringSubmatch(l); m != nil {
+		end := 1
+		for end < len(lines) && lines[end] != noticeFence {
+			end++
+		}
+		notice := &Notice{Style: m[1], Blocks: parseBlocks(lines[1:end], line+1), Unclosed: end == len(lines), Line: line}
+		return notice, min(end+1, len(lines))
+	}
+	if m := headingPattern.FindStringSubmatch(l); m != nil {
+		return &Heading{Level: len(m[1]), Content: parseInlines(m[2], line), Line: line}, 1
+	}
+	if rulePattern.MatchString(l) {
+		return &HorizontalRule{Raw: l, Line: line}, 1
+	}
+	if m := listItemPattern.FindStringSubmatch(l); m != nil {
+		item := &ListItem{Indent: m[1], Marker: m[2], Task: m[3], Content: parseInlines(m[4], line), Line: line}
+		return item, 1
+	}
+	if isQuoteLine(l) {
+		n := 1
+		for n < len(lines) && isQuoteLine(lines[n]) {
+			n++
+		}
+		inner := make([]string, n)
+		for i := range inner {
+			inner[i] = strings.TrimPrefix(strings.TrimPrefix(lines[i], ">"), " ")
+		}
+		return &Quote{Blocks: parseBlocks(inner, line), Line: line}, n
+	}
+	if strings.HasPrefix(l, "|") {
+		n := 1
+		for n < len(lines) && strings.HasPrefix(lines[n], "|") {
+			n++
+		}
+		return &Table{Content: parseInlines(strings.Join(lines[:n], "\n"), line), Line: line}, n
+	}
+
+	n := 1
+	for n < len(lines) && !startsBlock(lines[n]) {
+		n++
+	}
+	content := parseInlines(strings.Join(lines[:n], "\n"), line)
+	if link, ok := content[0].(*Link); ok && len(content) == 1 && !link.Image && link.Title == "" &&
+		link.Text == link.URL && link.URL != "" {
+		return &Embed{URL: link.URL, Line: line}, n
+	}
+	return &Paragraph{Content: content, Line: line}, n
+}
+
+// fencedLines returns the lines following the opening line up to the closing line, each followed by a line break. It
+// also returns the number of lines spanned including the opening and closing line, and whether the closing line is
+// missing.
+func fencedLines(lines []string, fence string) (string, int, bool) {
+	b := &strings.Builder{}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == fence {
+			return b.String(), i + 1, false
+		}
+		b.WriteString(lines[i] + "\n")
+	}
+	return b.String(), len(lines), true
+}
+
+// isQuoteLine returns true for lines of a block quote which are rendered as is by [Quote.String].
+func isQuoteLine(l string) bool {
+	return l == ">" || (strings.HasPrefix(l, "> ") && l != "> ")
+}
+
+// startsBlock returns true if l ends a paragraph by starting another block.
+func startsBlock(l string) bool {
+	return strings.TrimSpace(l) == "" ||
+		fencePattern.MatchString(l) ||
+		l == mathFence ||
+		noticePattern.MatchString(l) ||
+		l == noticeFence ||
+		headingPattern.MatchString(l) ||
+		rulePattern.MatchString(l) ||
+		listItemPattern.MatchString(l) ||
+		isQuoteLine(l) ||
+		strings.HasPrefix(l, "|")
+}
@@ -0,0 +1,120 @@
+package outline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+// Call describes a single API call made by any of the resource level clients.
+type Call struct {
+	// Endpoint is the name of the called API endpoint e.g. "documents.update".
+	Endpoint string
+	// Body is the JSON body sent with the request, if any. It must not be modified.
+	Body []byte
+	// Request is the HTTP request about to be made. A middleware may replace it e.g. to add values to its context.
+	Request *http.Request
+}
+
+// CallResult describes the outcome of a [Call].
+type CallResult struct {
+	// Response is the response received from the server. It is nil if Err is set. Its body must be left unread.
+	Response *http.Response
+	// Status is the HTTP status code of the response or 0 if no response was received.
+	Status int
+	// Duration is the time it took for the HTTP request to complete.
+	Duration time.Duration
+	// Err is set if the HTTP request could not be completed. Unsuccessful responses are only indicated via Status.
+	Err error
+}
+
+// Invoker makes a [Call] and returns its result.
+type Invoker func(call *Call) *CallResult
+
+// Middleware wraps every call made through a [Client]. It is given the next invoker in the chain which it must call
+// to let the call proceed. This allows a middleware to observe calls e.g. for logging, metrics and tracing but also to
+// delay, short circuit or retry them.
+type Middleware func(next Invoker) Invoker
+
+// Option configures optional behaviour of a [Client].
+type Option func(*options)
+
+// options holds configuration applied by Option(s).
+type options struct {
+	middlewares []Middleware
+	cache       *responseCache
+	limiter     *rateLimiter
+}
+
+// chain returns all middlewares to be applied, user provided ones first followed by internal ones.
+func (o *options) chain() []Middleware {
+	mws := append([]Middleware{}, o.middlewares...)
+	// Cached responses must not use up the rate limit budget.
+	if o.cache != nil {
+		mws = append(mws, o.cache.middleware)
+	}
+	if o.limiter != nil {
+		mws = append(mws, o.limiter.middleware)
+	}
+	return mws
+}
+
+// WithMiddleware adds mw to the chain of middlewares wrapping every call. Middlewares are run in the order they are
+// given i.e. the first one sees a call first and its result last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, mw...)
+	}
+}
+
+// callDoer implements [rsling.Doer] by passing every request through a chain of middlewares before handing it over to
+// the HTTP client.
+type callDoer struct {
+	invoke Invoker
+}
+
+func newCallDoer(hc *http.Client, middlewares []Middleware) *callDoer {
+	// Like rsling we fall back to the default client if none is given.
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	invoke := func(call *Call) *CallResult {
+		start := time.Now()
+		resp, err := hc.Do(call.Request)
+		res := &CallResult{Response: resp, Duration: time.Since(start), Err: err}
+		if resp != nil {
+			res.Status = resp.StatusCode
+		}
+		return res
+	}
+
+	// Wrap in reverse order so that the first middleware ends up being the outermost one.
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoke = middlewares[i](invoke)
+	}
+
+	return &callDoer{invoke: invoke}
+}
+
+func (d *callDoer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading request body: %w", err)
+		}
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// All endpoints live directly below the API base path hence the last path element is the endpoint name.
+	res := d.invoke(&Call{Endpoint: path.Base(req.URL.Path), Body: body, Request: req})
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return res.Response, nil
+}
@@ -0,0 +1,644 @@
+// Package migrate copies collections from one outline workspace to another e.g. from staging to production.
+//
+// A copy includes the nested documents, the templates and the attachments of a collection as well as its settings and
+// the user memberships of the collection and its documents. Links to copied documents and attachments, and mentions of
+// copied documents and of users matched in the target workspace, are rewritten to point to the copies. A copy can be
+// resumed after an interruption by keeping track of its progress in a checkpoint file, see [WithCheckpoint].
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// Result maps the ids of the source workspace to the ids of their copies in the target workspace.
+type Result struct {
+	CollectionID outline.CollectionID `json:"collectionId"`
+	// Documents holds the templates as well.
+	Documents   map[outline.DocumentID]outline.DocumentID     `json:"documents"`
+	Attachments map[outline.AttachmentID]outline.AttachmentID `json:"attachments"`
+	// Unmapped are the memberships of source documents which were not copied as their user is not part of the target
+	// workspace. Users are matched by email, or by name if the email is not visible to the source client.
+	Unmapped []outline.DocumentMembership `json:"unmapped"`
+	// UnmappedCollection are the memberships of the source collection which were not copied for the same reason.
+	UnmappedCollection []outline.CollectionMembership `json:"unmappedCollection"`
+	// Groups are the group memberships of the source collection. They are not copied as groups cannot be matched
+	// between workspaces, grant them access to the copy by hand.
+	Groups []outline.CollectionGroupMembership `json:"groups"`
+	// UnmappedMentions are the users mentioned in source documents, by document, which are not part of the target
+	// workspace. Their mentions are left as is hence they point to no one.
+	UnmappedMentions map[outline.DocumentID][]outline.UserID `json:"unmappedMentions"`
+}
+
+// Option configures [CopyCollection].
+type Option func(*options)
+
+type options struct {
+	checkpoint string
+	hosts      []string
+}
+
+// WithCheckpoint makes [CopyCollection] record its progress in the file at path after every step. If the file exists
+// the copy is resumed from the recorded progress. Running a finished copy again with its checkpoint does nothing.
+func WithCheckpoint(path string) Option {
+	return func(o *options) {
+		o.checkpoint = path
+	}
+}
+
+// WithHosts makes [CopyCollection] rewrite absolute links to any of hosts e.g. staging.wiki.example.com as well. These
+// are the hosts the source workspace is reachable under. By default only links without host are rewritten, which is
+// how outline writes links to its own documents and attachments.
+func WithHosts(hosts ...string) Option {
+	return func(o *options) {
+		o.hosts = append(o.hosts, hosts...)
+	}
+}
+
+// checkpoint is the progress of a copy.
+type checkpoint struct {
+	Source outline.CollectionID `json:"source"`
+	Result
+	// URLs maps the url ids of source documents to the urls of their copies.
+	URLs map[string]string `json:"urls"`
+	// Linked are the source documents whose copies have their links rewritten.
+	Linked map[outline.DocumentID]bool `json:"linked"`
+	// Members are the source documents whose memberships were copied.
+	Members map[outline.DocumentID]bool `json:"members"`
+	// CollectionMembers is set once the memberships of the collection were copied.
+	CollectionMembers bool `json:"collectionMembers"`
+	// Creating is set while the collection, or a document, is created. The copy might exist already if the creation
+	// was interrupted, hence it is looked up before creating it again.
+	Creating *creation `json:"creating,omitempty"`
+}
+
+// creation is the collection, or a document, being created.
+type creation struct {
+	// Document is the source document being copied, empty for the collection.
+	Document outline.DocumentID `json:"document,omitempty"`
+	// Since is when the creation started.
+	Since time.Time `json:"since"`
+}
+
+// maxClockSkew is the difference between the local clock and the one of the target server tolerated when looking for a
+// collection created by an interrupted copy.
+const maxClockSkew = time.Minute
+
+// CopyCollection copies the collection identified by id, using the client src, to the workspace of the client dst. The
+// copied documents are published, drafts of the source collection are not copied. The order of the documents is kept.
+// The memberships of the collection are copied first, then the ones of the documents. Memberships are only copied for
+// users which are part of the target workspace, the others are reported in the result along with the group memberships
+// of the collection.
+func CopyCollection(
+	ctx context.Context,
+	src *outline.Client,
+	dst *outline.Client,
+	id outline.CollectionID,
+	opts ...Option,
+) (*Result, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cp, err := readCheckpoint(o.checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading checkpoint: %w", err)
+	}
+	if cp.Source != "" && cp.Source != id {
+		return nil, fmt.Errorf("checkpoint belongs to a copy of collection '%s'", cp.Source)
+	}
+	cp.Source = id
+	users := map[outline.UserID]outline.UserID{}
+	c := &copier{
+		src:  src,
+		dst:  dst,
+		path: o.checkpoint,
+		cp:   cp,
+		links: &outline.LinkRewriter{
+			Hosts:       o.hosts,
+			Documents:   cp.Documents,
+			URLs:        cp.URLs,
+			Attachments: cp.Attachments,
+			Users:       users,
+		},
+		users: users,
+	}
+
+	if err := c.copyCollection(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.copyCollectionMemberships(ctx); err != nil {
+		return nil, fmt.Errorf("failed copying memberships of collection: %w", err)
+	}
+
+	// Templates are copied first, they are not part of the structure but documents might link to them.
+	order := []outline.DocumentID{}
+	err = src.Documents().GetAll().Collection(id).Template(true).Do(ctx, func(doc *outline.Document, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		order = append(order, doc.ID)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing templates: %w", err)
+	}
+	for _, tplID := range order {
+		if err := c.copyDocument(ctx, tplID, ""); err != nil {
+			return nil, fmt.Errorf("failed copying template '%s': %w", tplID, err)
+		}
+	}
+
+	st, err := src.Collections().DocumentStructure(id).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting document structure: %w", err)
+	}
+	err = st.Walk(func(doc *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		order = append(order, doc.ID)
+		parent := outline.DocumentID("")
+		if len(path) > 0 {
+			parent = path[len(path)-1].ID
+		}
+		if err := c.copyDocument(ctx, doc.ID, parent); err != nil {
+			return fmt.Errorf("failed copying document '%s': %w", doc.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Links to documents can only be rewritten once all documents were copied.
+	for _, docID := range order {
+		if err := c.rewriteLinks(ctx, docID); err != nil {
+			return nil, fmt.Errorf("failed rewriting links of document '%s': %w", docID, err)
+		}
+	}
+	for _, docID := range order {
+		if err := c.copyMemberships(ctx, docID); err != nil {
+			return nil, fmt.Errorf("failed copying memberships of document '%s': %w", docID, err)
+		}
+	}
+
+	return &cp.Result, nil
+}
+
+// copier holds the state of a single copy.
+type copier struct {
+	src  *outline.Client
+	dst  *outline.Client
+	path string
+	cp   *checkpoint
+	// links rewrites links and mentions to point to the copies made so far.
+	links *outline.LinkRewriter
+	// users maps the ids of source users to the ids of target users, empty for users not part of the target.
+	users map[outline.UserID]outline.UserID
+}
+
+// copyCollection creates the target collection with the settings of the source collection, unless done already.
+func (c *copier) copyCollection(ctx context.Context) error {
+	if c.cp.CollectionID != "" {
+		return nil
+	}
+
+	col, err := c.src.Collections().Get(c.cp.Source).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting collection: %w", err)
+	}
+	if c.cp.Creating != nil && c.cp.Creating.Document == "" {
+		id, err := c.createdCollection(ctx, col.Name, c.cp.Creating.Since)
+		if err != nil {
+			return fmt.Errorf("failed looking up collection of interrupted copy: %w", err)
+		}
+		if id != "" {
+			c.cp.CollectionID = id
+			c.cp.Creating = nil
+			return c.save()
+		}
+	}
+	if err := c.creating(""); err != nil {
+		return err
+	}
+
+	req := c.dst.Collections().Create(col.Name).Description(col.Description).Color(col.Color).Private(col.Private)
+	switch outline.Permission(col.Permission) {
+	case outline.PermissionRead:
+		req = req.PermissionRead()
+	case outline.PermissionReadWrite:
+		req = req.PermissionReadWrite()
+	}
+	created, err := req.Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed creating collection: %w", err)
+	}
+	c.cp.CollectionID = created.ID
+	c.cp.Creating = nil
+
+	return c.save()
+}
+
+// creating marks the collection, or the document identified by id, as being created and saves the checkpoint.
+func (c *copier) creating(id outline.DocumentID) error {
+	c.cp.Creating = &creation{Document: id, Since: time.Now()}
+	return c.save()
+}
+
+// createdCollection returns the id of the collection named name created in the target workspace since the given
+// time, the latest one if there are several. It is empty if there is none.
+func (c *copier) createdCollection(ctx context.Context, name string, since time.Time) (outline.CollectionID, error) {
+	var found *outline.Collection
+	err := c.dst.Collections().List().Do(ctx, func(col *outline.Collection, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		if col.Name != name || col.CreatedAt.Before(since.Add(-maxClockSkew)) {
+			return true, nil
+		}
+		if found == nil || col.CreatedAt.After(found.CreatedAt) {
+			found = col
+		}
+		return true, nil
+	})
+	if err != nil || found == nil {
+		return "", err
+	}
+	return found.ID, nil
+}
+
+// createdDocument returns the copy of doc created by an interrupted copy, which is a child of the copy of parent titled
+// like doc that is no copy of any other document. It is nil if there is none.
+func (c *copier) createdDocument(
+	ctx context.Context, doc *outline.Document, parent outline.DocumentID,
+) (*outline.Document, error) {
+	copies := map[outline.DocumentID]bool{}
+	for _, id := range c.cp.Documents {
+		copies[id] = true
+	}
+	parentCopy := c.cp.Documents[parent]
+
+	var found *outline.Document
+	req := c.dst.Documents().GetAll().Collection(c.cp.CollectionID).Template(doc.Template)
+	if parentCopy != "" {
+		req = req.Parent(parentCopy)
+	}
+	// Do returns the error which fn is called with.
+	err := req.Do(ctx, func(candidate *outline.Document, e error) bool {
+		if e != nil {
+			return false
+		}
+		if candidate.Title == doc.Title && candidate.ParentDocumentID == parentCopy && !copies[candidate.ID] {
+			found = candidate
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// copyCollectionMemberships adds the users having a membership of the source collection to the target collection and
+// records the group memberships of the source collection, unless done already.
+func (c *copier) copyCollectionMemberships(ctx context.Context) error {
+	if c.cp.CollectionMembers {
+		return nil
+	}
+
+	memberships := []outline.CollectionMembership{}
+	err := c.src.Collections().Memberships(c.cp.Source).Do(ctx,
+		func(m *outline.CollectionMembership, err error) (bool, error) {
+			if err != nil {
+				return false, err
+			}
+			memberships = append(memberships, *m)
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed listing memberships: %w", err)
+	}
+	groups := []outline.CollectionGroupMembership{}
+	err = c.src.Collections().GroupMemberships(c.cp.Source).Do(ctx,
+		func(m *outline.CollectionGroupMembership, err error) (bool, error) {
+			if err != nil {
+				return false, err
+			}
+			groups = append(groups, *m)
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed listing group memberships: %w", err)
+	}
+
+	// Memberships are copied all over again after an interruption.
+	c.cp.UnmappedCollection = []outline.CollectionMembership{}
+	for _, m := range memberships {
+		userID, err := c.targetUser(ctx, m.User)
+		if err != nil {
+			return fmt.Errorf("failed finding user '%s': %w", m.UserID, err)
+		}
+		if userID == "" {
+			c.cp.UnmappedCollection = append(c.cp.UnmappedCollection, m)
+			continue
+		}
+		if _, err := c.dst.Collections().AddUser(c.cp.CollectionID, userID, m.Permission).Do(ctx); err != nil {
+			return fmt.Errorf("failed adding user '%s': %w", userID, err)
+		}
+	}
+	c.cp.Groups = groups
+	c.cp.CollectionMembers = true
+
+	return c.save()
+}
+
+// copyDocument creates a copy of the document identified by id below the copy of parent, unless done already. The
+// attachments linked from the document are copied beforehand.
+func (c *copier) copyDocument(ctx context.Context, id outline.DocumentID, parent outline.DocumentID) error {
+	if _, ok := c.cp.Documents[id]; ok {
+		return nil
+	}
+
+	doc, err := c.src.Documents().Get().ByID(id).Do(ctx)
+	if err != nil {
+		return err
+	}
+	md := markdown.Parse(doc.Text)
+	for _, link := range md.Links() {
+		if attID, ok := link.AttachmentID(); ok && c.links.Internal(link.URL) {
+			if err := c.copyAttachment(ctx, outline.AttachmentID(attID)); err != nil {
+				return fmt.Errorf("failed copying attachment '%s': %w", attID, err)
+			}
+		}
+	}
+	delete(c.cp.UnmappedMentions, id)
+	for _, mention := range md.Mentions() {
+		if mention.Type() != "user" {
+			continue
+		}
+		userID, err := c.mentionedUser(ctx, mention)
+		if err != nil {
+			return fmt.Errorf("failed matching mentioned user '%s': %w", mention.ModelID(), err)
+		}
+		if userID == "" && !slices.Contains(c.cp.UnmappedMentions[id], outline.UserID(mention.ModelID())) {
+			c.cp.UnmappedMentions[id] = append(c.cp.UnmappedMentions[id], outline.UserID(mention.ModelID()))
+		}
+	}
+
+	if c.cp.Creating != nil && c.cp.Creating.Document == id {
+		created, err := c.createdDocument(ctx, doc, parent)
+		if err != nil {
+			return fmt.Errorf("failed looking up copy of interrupted copy: %w", err)
+		}
+		if created != nil {
+			return c.created(doc, created)
+		}
+	}
+	if err := c.creating(id); err != nil {
+		return err
+	}
+
+	req := c.dst.Documents().Create(doc.Title, c.cp.CollectionID).Text(c.links.Rewrite(doc.Text)).Publish(true)
+	if doc.Template {
+		req = req.Template(true)
+	}
+	if parent != "" {
+		req = req.ParentDocumentID(c.cp.Documents[parent])
+	}
+	created, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.created(doc, created)
+}
+
+// created records that created is the copy of doc.
+func (c *copier) created(doc *outline.Document, created *outline.Document) error {
+	c.cp.Documents[doc.ID] = created.ID
+	c.cp.URLs[doc.URLID] = created.URL
+	c.cp.Creating = nil
+
+	return c.save()
+}
+
+// copyAttachment uploads the content of the attachment identified by id to the target, unless done already. Links to
+// attachments which do not exist (anymore) are left as is.
+func (c *copier) copyAttachment(ctx context.Context, id outline.AttachmentID) error {
+	if _, ok := c.cp.Attachments[id]; ok {
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	contentType, err := c.src.Attachments().Download(id).Do(ctx, buf)
+	if outline.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	name := string(id) + outline.FileExtension(contentType)
+	att, err := c.dst.Attachments().Upload(name, contentType, buf.Bytes()).Do(ctx)
+	if err != nil {
+		return err
+	}
+	c.cp.Attachments[id] = att.ID
+
+	return c.save()
+}
+
+// rewriteLinks updates the links of the copy of the document identified by id, unless done already.
+func (c *copier) rewriteLinks(ctx context.Context, id outline.DocumentID) error {
+	if c.cp.Linked[id] {
+		return nil
+	}
+
+	doc, err := c.dst.Documents().Get().ByID(c.cp.Documents[id]).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if text := c.links.Rewrite(doc.Text); text != doc.Text {
+		if _, err := c.dst.Documents().Update(doc.ID).Text(text).Do(ctx); err != nil {
+			return err
+		}
+	}
+	c.cp.Linked[id] = true
+
+	return c.save()
+}
+
+// copyMemberships adds the users having a membership of the document identified by id to its copy, unless done
+// already.
+func (c *copier) copyMemberships(ctx context.Context, id outline.DocumentID) error {
+	if c.cp.Members[id] {
+		return nil
+	}
+
+	memberships := []outline.DocumentMembership{}
+	err := c.src.Documents().Memberships(id).Do(ctx, func(m *outline.DocumentMembership, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		memberships = append(memberships, *m)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed listing memberships: %w", err)
+	}
+
+	target := c.cp.Documents[id]
+	for _, m := range memberships {
+		userID, err := c.targetUser(ctx, m.User)
+		if err != nil {
+			return fmt.Errorf("failed finding user '%s': %w", m.UserID, err)
+		}
+		if userID == "" {
+			c.cp.Unmapped = append(c.cp.Unmapped, m)
+			continue
+		}
+		if _, err := c.dst.Documents().AddUser(target, userID, m.Permission).Do(ctx); err != nil {
+			return fmt.Errorf("failed adding user '%s': %w", userID, err)
+		}
+	}
+	c.cp.Members[id] = true
+
+	return c.save()
+}
+
+// mentionedUser returns the id of the user of the target workspace matching the source user mentioned by m. It is empty
+// if there is no such user.
+func (c *copier) mentionedUser(ctx context.Context, m *markdown.Mention) (outline.UserID, error) {
+	id := outline.UserID(m.ModelID())
+	if target, ok := c.users[id]; ok {
+		return target, nil
+	}
+
+	// Mentions only hold the name of the user at the time of mentioning, the email is needed for matching.
+	var u *outline.User
+	err := c.src.Users().List().Query(m.Name).Do(ctx, func(candidate *outline.User, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		if outline.UserID(candidate.ID) == id {
+			u = candidate
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if u == nil {
+		c.users[id] = ""
+		return "", nil
+	}
+
+	return c.targetUser(ctx, u)
+}
+
+// targetUser returns the id of the user of the target workspace matching the source user u. It is empty if there is no
+// such user.
+func (c *copier) targetUser(ctx context.Context, u *outline.User) (outline.UserID, error) {
+	if u == nil {
+		return "", nil
+	}
+	if id, ok := c.users[outline.UserID(u.ID)]; ok {
+		return id, nil
+	}
+
+	query := u.Email
+	if query == "" {
+		query = u.Name
+	}
+	var found outline.UserID
+	err := c.dst.Users().List().Query(query).Do(ctx, func(candidate *outline.User, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		if (u.Email != "" && strings.EqualFold(candidate.Email, u.Email)) || (u.Email == "" && candidate.Name == u.Name) {
+			found = outline.UserID(candidate.ID)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	c.users[outline.UserID(u.ID)] = found
+
+	return found, nil
+}
+
+// save writes the checkpoint, if any. The file is replaced atomically hence it is never left half written.
+func (c *copier) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(c.cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed encoding checkpoint: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("failed writing checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// readCheckpoint reads the checkpoint at path. An empty checkpoint is returned if there is none yet.
+func readCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(b, cp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cp.Documents == nil {
+		cp.Documents = map[outline.DocumentID]outline.DocumentID{}
+	}
+	if cp.Attachments == nil {
+		cp.Attachments = map[outline.AttachmentID]outline.AttachmentID{}
+	}
+	if cp.Unmapped == nil {
+		cp.Unmapped = []outline.DocumentMembership{}
+	}
+	if cp.UnmappedCollection == nil {
+		cp.UnmappedCollection = []outline.CollectionMembership{}
+	}
+	if cp.Groups == nil {
+		cp.Groups = []outline.CollectionGroupMembership{}
+	}
+	if cp.UnmappedMentions == nil {
+		cp.UnmappedMentions = map[outline.DocumentID][]outline.UserID{}
+	}
+	if cp.URLs == nil {
+		cp.URLs = map[string]string{}
+	}
+	if cp.Linked == nil {
+		cp.Linked = map[outline.DocumentID]bool{}
+	}
+	if cp.Members == nil {
+		cp.Members = map[outline.DocumentID]bool{}
+	}
+	return cp, nil
+}
@@ -0,0 +1,230 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/migrate"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+func TestCopyCollection(t *testing.T) {
+	src := outlinetest.NewServer(testApiKey)
+	defer src.Close()
+	dst := outlinetest.NewServer(testApiKey)
+	defer dst.Close()
+	ctx := context.Background()
+
+	col := src.AddCollection(outline.Collection{Name: "Ops", Color: "#FF0000", Permission: "read"})
+	db := src.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+	img := src.AddAttachment("diagram.png", "image/png", []byte("png"), db.ID)
+	failover := src.AddDocument(outline.Document{
+		CollectionID:     col.ID,
+		ParentDocumentID: db.ID,
+		Title:            "Failover",
+		Text: "Part of @[DB](mention://m1/document/" + string(db.ID) + "), see [DB](https://staging.example.com/doc/db-" +
+			db.URLID + "#setup).\n![diagram](" + img + ")",
+	})
+	tpl := src.AddDocument(outline.Document{
+		CollectionID: col.ID,
+		Title:        "Incident",
+		Text:         "Follow [failover](/doc/failover-" + failover.URLID + ")",
+		Template:     true,
+	})
+	jane := src.AddUser(outline.User{Name: "Jane Doe", Email: "jane@example.com"})
+	john := src.AddUser(outline.User{Name: "John Doe", Email: "john@example.com"})
+	_, err := src.OutlineClient().Documents().AddUser(db.ID, outline.UserID(jane.ID), outline.PermissionRead).Do(ctx)
+	require.NoError(t, err)
+	_, err = src.OutlineClient().Documents().AddUser(failover.ID, outline.UserID(john.ID), outline.PermissionReadWrite).Do(ctx)
+	require.NoError(t, err)
+	_, err = src.OutlineClient().Collections().
+		AddUser(col.ID, outline.UserID(jane.ID), outline.PermissionReadWrite).Do(ctx)
+	require.NoError(t, err)
+	_, err = src.OutlineClient().Collections().AddUser(col.ID, outline.UserID(john.ID), outline.PermissionRead).Do(ctx)
+	require.NoError(t, err)
+	ops := src.AddCollectionGroup(col.ID, outline.Group{Name: "Ops team"}, outline.PermissionRead)
+	dstJane := dst.AddUser(outline.User{Name: "Jane Doe", Email: "Jane@Example.com"})
+	// Mentioned users are matched like members.
+	_, err = src.OutlineClient().Documents().Update(db.ID).
+		Text("Owned by @[Jane Doe](mention://m2/user/" + jane.ID + ") and @[John Doe](mention://m3/user/" + john.ID + ")").
+		Do(ctx)
+	require.NoError(t, err)
+
+	res, err := migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), col.ID,
+		migrate.WithHosts("staging.example.com"))
+	require.NoError(t, err)
+	require.Len(t, res.Documents, 3)
+	require.Len(t, res.Attachments, 1)
+
+	newCol, ok := dst.Collection(res.CollectionID)
+	require.True(t, ok)
+	assert.Equal(t, "Ops", newCol.Name)
+	assert.Equal(t, "#FF0000", newCol.Color)
+	assert.Equal(t, "read", newCol.Permission)
+
+	st, err := dst.OutlineClient().Collections().DocumentStructure(res.CollectionID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Equal(t, res.Documents[db.ID], st[0].ID)
+	require.Len(t, st[0].Children, 1)
+	assert.Equal(t, res.Documents[failover.ID], st[0].Children[0].ID)
+
+	newDB, _ := dst.Document(res.Documents[db.ID])
+	newFailover, _ := dst.Document(res.Documents[failover.ID])
+	newTpl, _ := dst.Document(res.Documents[tpl.ID])
+	assert.True(t, newTpl.Template)
+	assert.Equal(t, "Follow [failover](/doc/failover-"+newFailover.URLID+")", newTpl.Text)
+	assert.Equal(t,
+		"Part of @[DB](mention://m1/document/"+string(newDB.ID)+"), see [DB](/doc/db-"+newDB.URLID+"#setup).\n"+
+			"![diagram](/api/attachments.redirect?id="+string(res.Attachments[attachmentID(img)])+")",
+		newFailover.Text,
+	)
+	assert.Equal(t,
+		"Owned by @[Jane Doe](mention://m2/user/"+dstJane.ID+") and @[John Doe](mention://m3/user/"+john.ID+")",
+		newDB.Text,
+	)
+	assert.Equal(t, map[outline.DocumentID][]outline.UserID{db.ID: {outline.UserID(john.ID)}}, res.UnmappedMentions)
+	data, ok := dst.Attachment("/api/attachments.redirect?id=" + string(res.Attachments[attachmentID(img)]))
+	require.True(t, ok)
+	assert.Equal(t, []byte("png"), data)
+
+	memberships := dst.Memberships(newDB.ID)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, outline.UserID(dstJane.ID), memberships[0].UserID)
+	assert.Equal(t, outline.PermissionRead, memberships[0].Permission)
+	assert.Empty(t, dst.Memberships(newFailover.ID))
+	require.Len(t, res.Unmapped, 1)
+	assert.Equal(t, outline.UserID(john.ID), res.Unmapped[0].UserID)
+
+	// Collection memberships are copied as well, groups are only reported.
+	colMemberships := dst.CollectionMemberships(res.CollectionID)
+	require.Len(t, colMemberships, 1)
+	assert.Equal(t, outline.UserID(dstJane.ID), colMemberships[0].UserID)
+	assert.Equal(t, outline.PermissionReadWrite, colMemberships[0].Permission)
+	require.Len(t, res.UnmappedCollection, 1)
+	assert.Equal(t, outline.UserID(john.ID), res.UnmappedCollection[0].UserID)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, ops.ID, res.Groups[0].GroupID)
+	assert.Equal(t, "Ops team", res.Groups[0].Group.Name)
+}
+
+func TestCopyCollection_resume(t *testing.T) {
+	src := outlinetest.NewServer(testApiKey)
+	defer src.Close()
+	dst := outlinetest.NewServer(testApiKey)
+	defer dst.Close()
+	ctx := context.Background()
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	col := src.AddCollection(outline.Collection{Name: "Ops"})
+	db := src.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+	setup := src.AddDocument(outline.Document{CollectionID: col.ID, Title: "Setup"})
+	// Links to documents copied later on are rewritten in a second pass.
+	_, err := src.OutlineClient().Documents().Update(db.ID).Text("[setup](/doc/setup-" + setup.URLID + ")").Do(ctx)
+	require.NoError(t, err)
+
+	// The copy is interrupted after all documents were created.
+	dst.InjectFault(outlinetest.Fault{Endpoint: common.DocumentsUpdateEndpoint(), Status: http.StatusBadGateway})
+	_, err = migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), col.ID,
+		migrate.WithCheckpoint(checkpoint))
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+	assert.Equal(t, 2, dst.RequestCount(common.DocumentsCreateEndpoint()))
+
+	dst.ClearFaults()
+	res, err := migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), col.ID,
+		migrate.WithCheckpoint(checkpoint))
+	require.NoError(t, err)
+	assert.Equal(t, 1, dst.RequestCount(common.CollectionsCreateEndpoint()))
+	assert.Equal(t, 2, dst.RequestCount(common.DocumentsCreateEndpoint()))
+	st, err := dst.OutlineClient().Collections().DocumentStructure(res.CollectionID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 2)
+	newDB, _ := dst.Document(res.Documents[db.ID])
+	newSetup, _ := dst.Document(res.Documents[setup.ID])
+	assert.Equal(t, "[setup](/doc/setup-"+newSetup.URLID+")", newDB.Text)
+
+	// A finished copy is not repeated.
+	again, err := migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), col.ID,
+		migrate.WithCheckpoint(checkpoint))
+	require.NoError(t, err)
+	assert.Equal(t, res, again)
+	assert.Equal(t, 2, dst.RequestCount(common.DocumentsCreateEndpoint()))
+	assert.Equal(t, 2, dst.RequestCount(common.DocumentsUpdateEndpoint()))
+
+	// The checkpoint cannot be used for another collection.
+	_, err = migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), "other",
+		migrate.WithCheckpoint(checkpoint))
+	require.ErrorContains(t, err, "checkpoint belongs to a copy of collection")
+}
+
+func TestCopyCollection_resumeLostResponse(t *testing.T) {
+	src := outlinetest.NewServer(testApiKey)
+	defer src.Close()
+	dst := outlinetest.NewServer(testApiKey)
+	defer dst.Close()
+	ctx := context.Background()
+
+	col := src.AddCollection(outline.Collection{Name: "Ops"})
+	db := src.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+	src.AddDocument(outline.Document{CollectionID: col.ID, ParentDocumentID: db.ID, Title: "Failover"})
+	// A collection of the same name existing beforehand is not mistaken for the copy.
+	dst.AddCollection(outline.Collection{Name: "Ops", CreatedAt: time.Now().Add(-time.Hour)})
+
+	for _, endpoint := range []string{common.CollectionsCreateEndpoint(), common.DocumentsCreateEndpoint()} {
+		t.Run(endpoint, func(t *testing.T) {
+			checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+			// The copy is interrupted after the server created the collection, or the first document, but before the
+			// response arrived.
+			lost := false
+			mw := func(next outline.Invoker) outline.Invoker {
+				return func(call *outline.Call) *outline.CallResult {
+					res := next(call)
+					if call.Endpoint == endpoint && !lost {
+						lost = true
+						return &outline.CallResult{Err: errors.New("connection reset")}
+					}
+					return res
+				}
+			}
+			interrupted := outline.New(dst.URL, dst.Client(), testApiKey, outline.WithMiddleware(mw))
+			_, err := migrate.CopyCollection(ctx, src.OutlineClient(), interrupted, col.ID,
+				migrate.WithCheckpoint(checkpoint))
+			require.Error(t, err)
+
+			creates := dst.RequestCount(endpoint)
+			res, err := migrate.CopyCollection(ctx, src.OutlineClient(), dst.OutlineClient(), col.ID,
+				migrate.WithCheckpoint(checkpoint))
+			require.NoError(t, err)
+			require.Len(t, res.Documents, 2)
+
+			// The copies created before the interruption are reused.
+			if endpoint == common.CollectionsCreateEndpoint() {
+				assert.Equal(t, creates, dst.RequestCount(endpoint))
+			} else {
+				assert.Equal(t, creates+1, dst.RequestCount(endpoint))
+			}
+			st, err := dst.OutlineClient().Collections().DocumentStructure(res.CollectionID).Do(ctx)
+			require.NoError(t, err)
+			require.Len(t, st, 1)
+			assert.Equal(t, res.Documents[db.ID], st[0].ID)
+			require.Len(t, st[0].Children, 1)
+		})
+	}
+}
+
+// attachmentID returns the id of the attachment available at url.
+func attachmentID(url string) outline.AttachmentID {
+	return outline.AttachmentID(url[len(url)-36:])
+}
@@ -1,6 +1,9 @@
 package outline
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type (
 	DocumentID      string
@@ -8,6 +11,21 @@ type (
 	DocumentUrlID   string
 	CollectionID    string
 	TemplateID      string
+	ViewID          string
+	UserID          string
+	MembershipID    string
+	GroupID         string
+	APIKeyID        string
+	OAuthClientID   string
+	AttachmentID    string
+)
+
+// Permission represents the level of access a user has on a resource.
+type Permission string
+
+const (
+	PermissionRead      Permission = "read"
+	PermissionReadWrite Permission = "read_write"
 )
 
 // DocumentSummary represents summary of a document (and its children) that is part of a collection.
@@ -18,31 +36,64 @@ type DocumentSummary struct {
 	Children []DocumentSummary `json:"children"`
 }
 
-// Document represents an outline document.
+// Document represents an outline document. Time fields which are null in responses e.g. ArchivedAt of a document which
+// was never archived are zero. The other way round zero times are turned into null when marshalling a document.
 type Document struct {
-	ID               DocumentID   `json:"id"`
-	CollectionID     CollectionID `json:"collectionId"`
-	ParentDocumentID DocumentID   `json:"parentDocumentId"`
-	Title            string       `json:"title"`
-	FullWidth        bool         `json:"fullWidth"`
-	Emoji            string       `json:"emoji"`
-	Text             string       `json:"text"`
-	URLID            string       `json:"urlId"`
-	Collaborators    []User       `json:"collaborators"`
-	Pinned           bool         `json:"pinned"`
-	Template         bool         `json:"template"`
-	TemplateID       TemplateID   `json:"templateId"`
-	Revision         int          `json:"revision"`
-	CreatedAt        time.Time    `json:"createdAt"`
-	CreatedBy        User         `json:"createdBy"`
-	UpdatedAt        time.Time    `json:"updatedAt"`
-	UpdatedBy        User         `json:"updatedBy"`
-	PublishedAt      time.Time    `json:"publishedAt"`
-	ArchivedAt       time.Time    `json:"archivedAt"`
-	DeletedAt        time.Time    `json:"deletedAt"`
-}
-
-// User represents an outline user.
+	ID                 DocumentID    `json:"id"`
+	CollectionID       CollectionID  `json:"collectionId"`
+	ParentDocumentID   DocumentID    `json:"parentDocumentId"`
+	Title              string        `json:"title"`
+	FullWidth          bool          `json:"fullWidth"`
+	Emoji              string        `json:"emoji"`
+	Icon               string        `json:"icon"`
+	Color              string        `json:"color"`
+	Text               string        `json:"text"`
+	URL                string        `json:"url"`
+	URLID              string        `json:"urlId"`
+	Collaborators      []User        `json:"collaborators"`
+	Pinned             bool          `json:"pinned"`
+	Template           bool          `json:"template"`
+	TemplateID         TemplateID    `json:"templateId"`
+	Revision           int           `json:"revision"`
+	Tasks              DocumentTasks `json:"tasks"`
+	IsCollaborativeDoc bool          `json:"isCollaborativeDoc"`
+	InsightsEnabled    bool          `json:"insightsEnabled"`
+	CreatedAt          time.Time     `json:"createdAt"`
+	CreatedBy          User          `json:"createdBy"`
+	UpdatedAt          time.Time     `json:"updatedAt"`
+	UpdatedBy          User          `json:"updatedBy"`
+	PublishedAt        time.Time     `json:"publishedAt"`
+	ArchivedAt         time.Time     `json:"archivedAt"`
+	DeletedAt          time.Time     `json:"deletedAt"`
+	LastViewedAt       time.Time     `json:"lastViewedAt"`
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	// The local type has the same fields but no methods which avoids infinite recursion. Fields of the outer struct
+	// take precedence over the embedded ones with the same JSON name.
+	type document Document
+	return json.Marshal(struct {
+		document
+		PublishedAt  *time.Time `json:"publishedAt"`
+		ArchivedAt   *time.Time `json:"archivedAt"`
+		DeletedAt    *time.Time `json:"deletedAt"`
+		LastViewedAt *time.Time `json:"lastViewedAt"`
+	}{
+		document:     document(d),
+		PublishedAt:  nullTime(d.PublishedAt),
+		ArchivedAt:   nullTime(d.ArchivedAt),
+		DeletedAt:    nullTime(d.DeletedAt),
+		LastViewedAt: nullTime(d.LastViewedAt),
+	})
+}
+
+// DocumentTasks represents the progress of the checklist items of a document.
+type DocumentTasks struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// User represents an outline user. LastActiveAt is zero if the user was never active.
 type User struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
@@ -54,19 +105,157 @@ type User struct {
 	CreatedAt    time.Time `json:"createdAt"`
 }
 
-// Collection represents an outline collection.
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	return json.Marshal(struct {
+		user
+		LastActiveAt *time.Time `json:"lastActiveAt"`
+	}{
+		user:         user(u),
+		LastActiveAt: nullTime(u.LastActiveAt),
+	})
+}
+
+// DocumentsAnswer represents an answer to a natural language question generated from the documents of a workspace.
+type DocumentsAnswer struct {
+	Query   string            `json:"query"`
+	Answer  string            `json:"answer"`
+	Sources []DocumentSummary `json:"sources"`
+}
+
+// View represents how often and when a user has viewed a document.
+type View struct {
+	ID            ViewID     `json:"id"`
+	DocumentID    DocumentID `json:"documentId"`
+	Count         int        `json:"count"`
+	FirstViewedAt time.Time  `json:"firstViewedAt"`
+	LastViewedAt  time.Time  `json:"lastViewedAt"`
+	User          User       `json:"user"`
+}
+
+// DocumentMembership represents access of a single user to a document granted outside of collection permissions.
+// User is not part of the membership returned by the server, it is filled in by the client for convenience.
+type DocumentMembership struct {
+	ID         MembershipID `json:"id"`
+	DocumentID DocumentID   `json:"documentId"`
+	UserID     UserID       `json:"userId"`
+	Permission Permission   `json:"permission"`
+	User       *User        `json:"user,omitempty"`
+}
+
+// CollectionMembership represents access of a single user to a collection. User is not part of the membership returned
+// by the server, it is filled in by the client for convenience.
+type CollectionMembership struct {
+	ID           MembershipID `json:"id"`
+	CollectionID CollectionID `json:"collectionId"`
+	UserID       UserID       `json:"userId"`
+	Permission   Permission   `json:"permission"`
+	User         *User        `json:"user,omitempty"`
+}
+
+// Group represents a group of users.
+type Group struct {
+	ID          GroupID `json:"id"`
+	Name        string  `json:"name"`
+	MemberCount int     `json:"memberCount"`
+}
+
+// CollectionGroupMembership represents access of all users of a group to a collection. Group is not part of the
+// membership returned by the server, it is filled in by the client for convenience.
+type CollectionGroupMembership struct {
+	ID           MembershipID `json:"id"`
+	CollectionID CollectionID `json:"collectionId"`
+	GroupID      GroupID      `json:"groupId"`
+	Permission   Permission   `json:"permission"`
+	Group        *Group       `json:"group,omitempty"`
+}
+
+// Collection represents an outline collection. Like for [Document] null times are zero and vice versa. Commenting is
+// nil if the collection follows the workspace setting. DocumentStructure is only part of some responses.
 type Collection struct {
-	ID          CollectionID   `json:"id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Sort        map[string]any `json:"sort"`
-	Index       string         `json:"index"`
-	Color       string         `json:"color"`
-	Icon        string         `json:"icon"`
-	Permission  string         `json:"permission"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   time.Time      `json:"deletedAt"`
+	ID                CollectionID      `json:"id"`
+	URLID             string            `json:"urlId"`
+	URL               string            `json:"url"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Sort              CollectionSort    `json:"sort"`
+	Index             string            `json:"index"`
+	Color             string            `json:"color"`
+	Icon              string            `json:"icon"`
+	Permission        string            `json:"permission"`
+	Sharing           bool              `json:"sharing"`
+	Private           bool              `json:"private"`
+	Commenting        *bool             `json:"commenting"`
+	DocumentStructure DocumentStructure `json:"documentStructure"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+	ArchivedAt        time.Time         `json:"archivedAt"`
+	DeletedAt         time.Time         `json:"deletedAt"`
+}
+
+func (c Collection) MarshalJSON() ([]byte, error) {
+	type collection Collection
+	return json.Marshal(struct {
+		collection
+		ArchivedAt *time.Time `json:"archivedAt"`
+		DeletedAt  *time.Time `json:"deletedAt"`
+	}{
+		collection: collection(c),
+		ArchivedAt: nullTime(c.ArchivedAt),
+		DeletedAt:  nullTime(c.DeletedAt),
+	})
+}
+
+// CollectionSort represents how the documents of a collection are sorted.
+type CollectionSort struct {
+	// Field is the document property to sort by e.g. "title" or "index" for manual sorting.
+	Field string `json:"field"`
+	// Direction is either "asc" or "desc".
+	Direction string `json:"direction"`
+}
+
+// APIKey represents an outline API key. The Secret is only returned once, right after the key is created. ExpiresAt
+// is zero for keys which never expire.
+type APIKey struct {
+	ID           APIKeyID  `json:"id"`
+	Name         string    `json:"name"`
+	Secret       string    `json:"value"`
+	Last4        string    `json:"last4"`
+	Scope        []string  `json:"scope"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (k APIKey) MarshalJSON() ([]byte, error) {
+	type apiKey APIKey
+	return json.Marshal(struct {
+		apiKey
+		ExpiresAt    *time.Time `json:"expiresAt"`
+		LastActiveAt *time.Time `json:"lastActiveAt"`
+	}{
+		apiKey:       apiKey(k),
+		ExpiresAt:    nullTime(k.ExpiresAt),
+		LastActiveAt: nullTime(k.LastActiveAt),
+	})
+}
+
+// OAuthClient represents an OAuth application registered with outline. Third party integrations use the ClientID and
+// ClientSecret to act on behalf of individual users.
+type OAuthClient struct {
+	ID            OAuthClientID `json:"id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	DeveloperName string        `json:"developerName"`
+	DeveloperURL  string        `json:"developerUrl"`
+	AvatarURL     string        `json:"avatarUrl"`
+	ClientID      string        `json:"clientId"`
+	ClientSecret  string        `json:"clientSecret"`
+	RedirectURIs  []string      `json:"redirectUris"`
+	Published     bool          `json:"published"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
 }
 
 type Attachment struct {
@@ -77,9 +266,18 @@ type Attachment struct {
 }
 
 type AttachmentData struct {
-	ContentType string `json:"contentType"`
-	Size        int    `json:"size"`
-	Name        string `json:"name"`
-	URL         string `json:"url"`
-	DocumentID  string `json:"documentId"`
+	ID          AttachmentID `json:"id"`
+	ContentType string       `json:"contentType"`
+	Size        int          `json:"size"`
+	Name        string       `json:"name"`
+	URL         string       `json:"url"`
+	DocumentID  string       `json:"documentId"`
+}
+
+// nullTime returns nil for a zero t so that it is marshalled as null.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
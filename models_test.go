@@ -0,0 +1,186 @@
+package outline_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_fixture(t *testing.T) {
+	raw := testReadFixture(t, "documents.info.json")
+	doc := outline.Document{}
+	require.NoError(t, json.Unmarshal(raw, &doc))
+
+	assert.Equal(t, outline.DocumentID("9bcfe3a6-34c4-4c0e-8fb5-bd8a5f3e2d17"), doc.ID)
+	assert.Equal(t, "/doc/on-call-handbook-Xk2pQ8vTfR", doc.URL)
+	assert.Equal(t, "📟", doc.Icon)
+	assert.Equal(t, "#FF5C80", doc.Color)
+	assert.Equal(t, outline.DocumentTasks{Completed: 1, Total: 3}, doc.Tasks)
+	assert.True(t, doc.IsCollaborativeDoc)
+	assert.True(t, doc.InsightsEnabled)
+	assert.Equal(t, time.Date(2024, 3, 12, 16, 40, 2, 118000000, time.UTC), doc.LastViewedAt)
+	assert.Equal(t, time.Date(2024, 3, 12, 16, 45, 10, 0, time.UTC), doc.UpdatedBy.LastActiveAt)
+	assert.Empty(t, doc.ParentDocumentID)
+	assert.True(t, doc.ArchivedAt.IsZero())
+	assert.True(t, doc.DeletedAt.IsZero())
+
+	// Properties the model does not cover are either deprecated or internal to the editor.
+	testAssertModelComplete(t, raw, doc, "data", "teamId", "collaboratorIds")
+}
+
+func TestCollection_fixtures(t *testing.T) {
+	tests := map[string]struct {
+		fixture string
+		assert  func(*testing.T, outline.Collection)
+	}{
+		"active": {
+			fixture: "collections.info.json",
+			assert: func(t *testing.T, col outline.Collection) {
+				assert.Equal(t, "k1CmJHTrFb", col.URLID)
+				assert.Equal(t, "/collection/operations-k1CmJHTrFb", col.URL)
+				assert.Equal(t, outline.CollectionSort{Field: "title", Direction: "asc"}, col.Sort)
+				assert.True(t, col.Sharing)
+				assert.Nil(t, col.Commenting)
+				assert.True(t, col.ArchivedAt.IsZero())
+				require.Len(t, col.DocumentStructure, 1)
+				assert.Equal(t, "On-call handbook", col.DocumentStructure[0].Title)
+			},
+		},
+		"archived": {
+			fixture: "collections.info_archived.json",
+			assert: func(t *testing.T, col outline.Collection) {
+				assert.True(t, col.Private)
+				require.NotNil(t, col.Commenting)
+				assert.False(t, *col.Commenting)
+				assert.Empty(t, col.Color)
+				assert.Equal(t, time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), col.ArchivedAt)
+				assert.Nil(t, col.DocumentStructure)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw := testReadFixture(t, test.fixture)
+			col := outline.Collection{}
+			require.NoError(t, json.Unmarshal(raw, &col))
+			test.assert(t, col)
+
+			testAssertModelComplete(t, raw, col, "data", "archivedBy")
+		})
+	}
+}
+
+func TestModels_nullTimes(t *testing.T) {
+	b, err := json.Marshal(outline.Document{PublishedAt: time.Date(2024, 3, 11, 9, 15, 2, 0, time.UTC)})
+	require.NoError(t, err)
+
+	got := map[string]any{}
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "2024-03-11T09:15:02Z", got["publishedAt"])
+	for _, k := range []string{"archivedAt", "deletedAt", "lastViewedAt"} {
+		assert.Contains(t, got, k)
+		assert.Nil(t, got[k], k)
+	}
+
+	// Zero times survive a round trip.
+	doc := outline.Document{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	assert.True(t, doc.ArchivedAt.IsZero())
+	assert.True(t, doc.CreatedBy.LastActiveAt.IsZero())
+
+	b, err = json.Marshal(outline.Collection{})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"archivedAt":null`)
+	assert.Contains(t, string(b), `"deletedAt":null`)
+}
+
+// TestRecordFixtures replaces the fixtures in testdata with responses recorded from a real server. It only runs if the
+// server and the recorded resources are configured, see testdata/README.md.
+func TestRecordFixtures(t *testing.T) {
+	env := map[string]string{}
+	for _, k := range []string{
+		"OUTLINE_RECORD_URL",
+		"OUTLINE_RECORD_API_KEY",
+		"OUTLINE_RECORD_DOCUMENT",
+		"OUTLINE_RECORD_COLLECTION",
+		"OUTLINE_RECORD_ARCHIVED_COLLECTION",
+	} {
+		if env[k] = os.Getenv(k); env[k] == "" {
+			t.Skipf("%s is not set", k)
+		}
+	}
+
+	// Anything identifying people or revealing content is scrubbed, ids and timestamps are kept.
+	rec := outlinetest.NewRecordingTransport(filepath.Join("testdata", "cassettes", "models.json"), nil).
+		ScrubFields("name", "email", "avatarUrl", "text", "description")
+	cl := outline.New(env["OUTLINE_RECORD_URL"], &http.Client{Transport: rec}, env["OUTLINE_RECORD_API_KEY"])
+	ctx := context.Background()
+
+	_, err := cl.Documents().Get().ByID(outline.DocumentID(env["OUTLINE_RECORD_DOCUMENT"])).Do(ctx)
+	require.NoError(t, err)
+	_, err = cl.Collections().Get(outline.CollectionID(env["OUTLINE_RECORD_COLLECTION"])).Do(ctx)
+	require.NoError(t, err)
+	_, err = cl.Collections().Get(outline.CollectionID(env["OUTLINE_RECORD_ARCHIVED_COLLECTION"])).Do(ctx)
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	// The fixtures are the recorded response bodies in the order of the calls above.
+	b, err := os.ReadFile(filepath.Join("testdata", "cassettes", "models.json"))
+	require.NoError(t, err)
+	cassette := struct {
+		Interactions []struct {
+			Response struct {
+				Body json.RawMessage `json:"body"`
+			} `json:"response"`
+		} `json:"interactions"`
+	}{}
+	require.NoError(t, json.Unmarshal(b, &cassette))
+	require.Len(t, cassette.Interactions, 3)
+	for i, name := range []string{"documents.info.json", "collections.info.json", "collections.info_archived.json"} {
+		body := cassette.Interactions[i].Response.Body
+		require.NoError(t, os.WriteFile(filepath.Join("testdata", name), append(body, '\n'), 0o644))
+	}
+}
+
+// testReadFixture returns the data property of the response stored in testdata/name. Until they are recorded via
+// TestRecordFixtures the fixtures are synthetic, see testdata/README.md.
+func testReadFixture(t *testing.T, name string) json.RawMessage {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	resp := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(b, &resp))
+	return resp.Data
+}
+
+// testAssertModelComplete asserts that model, once marshalled, has every property of raw except the unmodeled ones.
+func testAssertModelComplete(t *testing.T, raw json.RawMessage, model any, unmodeled ...string) {
+	t.Helper()
+	b, err := json.Marshal(model)
+	require.NoError(t, err)
+
+	expected := map[string]json.RawMessage{}
+	require.NoError(t, json.Unmarshal(raw, &expected))
+	got := map[string]json.RawMessage{}
+	require.NoError(t, json.Unmarshal(b, &got))
+
+	for _, k := range unmodeled {
+		delete(expected, k)
+	}
+	for k := range expected {
+		assert.Contains(t, got, k)
+	}
+}
@@ -0,0 +1,248 @@
+package outline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxModifyAttempts limits how often [Client.ModifyDocument] merges concurrent changes and tries again.
+const maxModifyAttempts = 5
+
+// ErrConflict is matched by errors caused by a document which was changed by someone else in the meantime, see
+// [ConflictError].
+var ErrConflict = errors.New("conflict")
+
+// ConflictError is returned when a document is not at the expected revision, see [DocumentsUpdateClient.IfRevision].
+// It matches [ErrConflict] via [errors.Is].
+type ConflictError struct {
+	// Revision is the expected revision.
+	Revision int
+	// Current is the document as currently stored by the server.
+	Current *Document
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("document '%s' is at revision %d, expected %d", e.Current.ID, e.Current.Revision, e.Revision)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ModifyDocument gets the document identified by id, calls fn to modify it and updates the document with the title and
+// text set by fn. Other properties changed by fn are ignored. If the document was changed by someone else in the
+// meantime, the changes are merged line by line with the ones made by fn and the update is tried again. An error
+// matching [ErrConflict] is returned if both changed the same lines or the title, or if the document keeps changing.
+// If fn returns an error the document is left as is and the error is returned.
+func (cl *Client) ModifyDocument(ctx context.Context, id DocumentID, fn func(*Document) error) (*Document, error) {
+	base, err := cl.Documents().Get().ByID(id).Do(NoCache(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed getting document: %w", err)
+	}
+	ours := *base
+	if err := fn(&ours); err != nil {
+		return nil, err
+	}
+	if ours.Title == base.Title && ours.Text == base.Text {
+		return base, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		doc, err := cl.Documents().Update(id).Title(ours.Title).Text(ours.Text).IfRevision(base.Revision).Do(ctx)
+		conflict := &ConflictError{}
+		if !errors.As(err, &conflict) || attempt == maxModifyAttempts {
+			return doc, err
+		}
+
+		theirs := conflict.Current
+		title, ok := merge3(base.Title, ours.Title, theirs.Title)
+		if !ok {
+			return nil, fmt.Errorf("failed merging title: %w", err)
+		}
+		text, ok := mergeLines(base.Text, ours.Text, theirs.Text)
+		if !ok {
+			return nil, fmt.Errorf("failed merging text: %w", err)
+		}
+		base, ours.Title, ours.Text = theirs, title, text
+	}
+}
+
+// merge3 merges the changes made to base by ours and theirs. False is returned if both made different changes.
+func merge3(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == base || ours == theirs:
+		return theirs, true
+	case theirs == base:
+		return ours, true
+	default:
+		return "", false
+	}
+}
+
+// mergeLines merges the changes made to base by ours and theirs line by line. False is returned if both made different
+// changes to the same lines, or inserted different lines at the same place.
+func mergeLines(base, ours, theirs string) (string, bool) {
+	// A missing line break at the end is a change of its own hence appending to such a text does not change its last
+	// line.
+	lineBreak, ok := merge3(finalLineBreak(base), finalLineBreak(ours), finalLineBreak(theirs))
+	if !ok {
+		return "", false
+	}
+	merged, ok := mergeCompleteLines(addFinalLineBreak(base), addFinalLineBreak(ours), addFinalLineBreak(theirs))
+	if !ok || merged == "" {
+		return merged, ok
+	}
+	return strings.TrimSuffix(merged, "\n") + lineBreak, true
+}
+
+func finalLineBreak(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return "\n"
+	}
+	return ""
+}
+
+func addFinalLineBreak(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
+
+// mergeCompleteLines is like mergeLines for texts ending with a line break.
+func mergeCompleteLines(base, ours, theirs string) (string, bool) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	bo, ok := commonLines(b, o)
+	if !ok {
+		return "", false
+	}
+	bt, ok := commonLines(b, t)
+	if !ok {
+		return "", false
+	}
+
+	merged := &strings.Builder{}
+	i, oi, ti := 0, 0, 0
+	for {
+		// Find the next base line kept by both, the lines in between were changed by either side.
+		k := i
+		for k < len(b) && (bo[k] < 0 || bt[k] < 0) {
+			k++
+		}
+		endO, endT := len(o), len(t)
+		if k < len(b) {
+			endO, endT = bo[k], bt[k]
+		}
+
+		chunk, ok := merge3(
+			strings.Join(b[i:k], ""),
+			strings.Join(o[oi:endO], ""),
+			strings.Join(t[ti:endT], ""),
+		)
+		if !ok {
+			return "", false
+		}
+		merged.WriteString(chunk)
+
+		if k == len(b) {
+			return merged.String(), true
+		}
+		merged.WriteString(b[k])
+		i, oi, ti = k+1, endO+1, endT+1
+	}
+}
+
+// splitLines splits s into lines which keep their line break.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxDiffCells caps the product of the number of lines compared by commonLines once the common prefix and suffix are
+// trimmed. Bigger changes take too long to diff and are treated as conflict.
+const maxDiffCells = 25_000_000
+
+// commonLines returns for every line of a the index of the same line in b if it is part of the longest common
+// subsequence of both, otherwise -1. False is returned if the changed lines are too many to compare.
+func commonLines(a, b []string) ([]int, bool) {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// Changes are usually small hence most lines are part of the common prefix and suffix.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		match[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(a)*len(b) > maxDiffCells {
+		return nil, false
+	}
+	matchLines(a, b, prefix, prefix, match)
+
+	return match, true
+}
+
+// matchLines sets match[ao+i] to bo+j for every line a[i] equal to b[j] which is part of the longest common
+// subsequence of a and b. It follows Hirschberg's algorithm which needs space linear to the number of lines: a is
+// split in half and b where the longest common subsequences of both halves add up to the longest one.
+func matchLines(a, b []string, ao, bo int, match []int) {
+	if len(a) == 0 || len(b) == 0 {
+		return
+	}
+	if len(a) == 1 {
+		for j := range b {
+			if a[0] == b[j] {
+				match[ao] = bo + j
+				return
+			}
+		}
+		return
+	}
+
+	mid := len(a) / 2
+	head, tail := lcsLengths(a[:mid], b, false), lcsLengths(a[mid:], b, true)
+	split := 0
+	for j := 0; j <= len(b); j++ {
+		if head[j]+tail[len(b)-j] > head[split]+tail[len(b)-split] {
+			split = j
+		}
+	}
+
+	matchLines(a[:mid], b[:split], ao, bo, match)
+	matchLines(a[mid:], b[split:], ao+mid, bo+split, match)
+}
+
+// lcsLengths returns the lengths of the longest common subsequences of a and b[:j] for every j. If reverse is set a
+// and b are compared from their end i.e. the lengths are the ones of a and b[len(b)-j:].
+func lcsLengths(a, b []string, reverse bool) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			x, y := a[i], b[j]
+			if reverse {
+				x, y = a[len(a)-1-i], b[len(b)-1-j]
+			}
+			if x == y {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
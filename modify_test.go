@@ -0,0 +1,121 @@
+package outline
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := map[string]struct {
+		base, ours, theirs string
+		want               string
+		ok                 bool
+	}{
+		"changes to different lines": {
+			base:   "a\nb\nc\n",
+			ours:   "A\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "A\nb\nC\n",
+			ok:     true,
+		},
+		"insertions at different places": {
+			base:   "a\nb\n",
+			ours:   "x\na\nb\n",
+			theirs: "a\nb\ny\n",
+			want:   "x\na\nb\ny\n",
+			ok:     true,
+		},
+		"same change on both sides": {
+			base:   "a\nb\n",
+			ours:   "a\nB\n",
+			theirs: "a\nB\n",
+			want:   "a\nB\n",
+			ok:     true,
+		},
+		"deletion and unrelated change": {
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nc\nd\n",
+			theirs: "a\nb\nc\nD\n",
+			want:   "a\nc\nD\n",
+			ok:     true,
+		},
+		"empty base": {
+			base:   "",
+			ours:   "",
+			theirs: "new\n",
+			want:   "new\n",
+			ok:     true,
+		},
+		"append to text without final line break": {
+			base:   "a\nb",
+			ours:   "A\nb",
+			theirs: "a\nb\nc",
+			want:   "A\nb\nc",
+			ok:     true,
+		},
+		"all empty": {
+			ok: true,
+		},
+		"different changes to the same line": {
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nX\nc\n",
+		},
+		"different insertions at the same place": {
+			base:   "a\n",
+			ours:   "a\nb\n",
+			theirs: "a\nc\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := mergeLines(test.base, test.ours, test.theirs)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCommonLines(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want []int
+	}{
+		"equal":          {a: "abc", b: "abc", want: []int{0, 1, 2}},
+		"disjoint":       {a: "abc", b: "xyz", want: []int{-1, -1, -1}},
+		"insertion":      {a: "ac", b: "abc", want: []int{0, 2}},
+		"deletion":       {a: "abc", b: "ac", want: []int{0, -1, 1}},
+		"moved line":     {a: "abcd", b: "bcda", want: []int{-1, 0, 1, 2}},
+		"changed middle": {a: "axbycz", b: "aXbYcZ", want: []int{0, -1, 2, -1, 4, -1}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := commonLines(strings.Split(test.a, ""), strings.Split(test.b, ""))
+			assert.True(t, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMergeLines_large(t *testing.T) {
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d\n", i)
+	}
+	base := strings.Join(lines, "")
+	ours := strings.Replace(base, "line 100\n", "LINE 100\n", 1)
+	theirs := strings.Replace(base, "line 19000\n", "LINE 19000\n", 1)
+
+	got, ok := mergeLines(base, ours, theirs)
+	assert.True(t, ok)
+	assert.Equal(t, strings.Replace(ours, "line 19000\n", "LINE 19000\n", 1), got)
+
+	// Rewriting all lines on both sides is too much to diff and treated as conflict.
+	_, ok = mergeLines(base, strings.ToUpper(base), strings.ReplaceAll(base, "line", "row"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,225 @@
+// Package oauth implements the OAuth 2.0 authorization code flow against an outline server. It is meant for
+// integrations which act on behalf of individual users instead of using a single API key. The obtained tokens can be
+// plugged into [outline.NewWithTokenSource] via [Config.TokenSource].
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	authorizePath = "/oauth/authorize"
+	tokenPath     = "/oauth/token"
+
+	// expiryDelta is how early a token is considered expired. This avoids using a token which expires while the
+	// request is in flight.
+	expiryDelta = 30 * time.Second
+)
+
+// Config describes an OAuth client registered with an outline server, see [outline.OAuthClientsClient].
+type Config struct {
+	// ServerURL is the url of the outline server e.g. https://wiki.example.com
+	ServerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL must match one of the redirect URIs of the OAuth client.
+	RedirectURL string
+	// Scopes requested by the client e.g. "read", "write" or "documents:read".
+	Scopes []string
+	// HTTPClient is used for making token requests. If nil then [http.DefaultClient] is used.
+	HTTPClient *http.Client
+}
+
+// Token represents the credentials obtained from the server.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	Scope        string    `json:"scope"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid returns true if the access token is present and not (about to be) expired. A token without expiry never
+// expires.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// Error is returned when the server rejects a token request e.g. because the code or refresh token is invalid.
+// Reference: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+type Error struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("oauth error: status %d: %s: %s", e.Status, e.Code, e.Description)
+}
+
+// AuthCodeURL returns the url of the consent page the user must be sent to. The state is passed back to the redirect
+// url unchanged and should be verified there to protect against CSRF.
+func (c *Config) AuthCodeURL(state string) string {
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", c.ClientID)
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(c.Scopes) > 0 {
+		v.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if state != "" {
+		v.Set("state", state)
+	}
+
+	return strings.TrimSuffix(c.ServerURL, "/") + authorizePath + "?" + v.Encode()
+}
+
+// Exchange converts the authorization code received on the redirect url into a token.
+func (c *Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+
+	return c.retrieveToken(ctx, v)
+}
+
+// Refresh obtains a new token using refreshToken. The server might rotate the refresh token as well hence the returned
+// token should replace the old one completely.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", refreshToken)
+
+	tok, err := c.retrieveToken(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+// retrieveToken makes the token request with form values v and client credentials.
+func (c *Config) retrieveToken(ctx context.Context, v url.Values) (*Token, error) {
+	v.Set("client_id", c.ClientID)
+	v.Set("client_secret", c.ClientSecret)
+
+	u := strings.TrimSuffix(c.ServerURL, "/") + tokenPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading token response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		e := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(body, e) != nil || e.Code == "" {
+			e.Description = string(body)
+		}
+		return nil, e
+	}
+
+	data := struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		Scope        string `json:"scope"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed decoding token response: %w", err)
+	}
+	if data.AccessToken == "" {
+		return nil, fmt.Errorf("token response contains no access token")
+	}
+
+	tok := &Token{
+		AccessToken:  data.AccessToken,
+		TokenType:    data.TokenType,
+		RefreshToken: data.RefreshToken,
+		Scope:        data.Scope,
+	}
+	if data.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// TokenSource returns a token source which hands out tok until it expires and then refreshes it automatically. The
+// returned source is safe for concurrent use and satisfies [outline.TokenSource].
+func (c *Config) TokenSource(tok *Token) *TokenSource {
+	return &TokenSource{conf: c, tok: tok}
+}
+
+// TokenSource provides a valid access token, refreshing it whenever needed.
+type TokenSource struct {
+	conf      *Config
+	onRefresh func(*Token)
+
+	mu  sync.Mutex
+	tok *Token
+}
+
+// OnRefresh registers fn to be called with every newly refreshed token. Use this to persist the token, specially the
+// refresh token which might have been rotated by the server.
+func (ts *TokenSource) OnRefresh(fn func(*Token)) *TokenSource {
+	ts.onRefresh = fn
+	return ts
+}
+
+// Token returns a valid access token, refreshing the current one if it has expired.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tok.Valid() {
+		return ts.tok.AccessToken, nil
+	}
+	if ts.tok == nil || ts.tok.RefreshToken == "" {
+		return "", fmt.Errorf("token expired and no refresh token available")
+	}
+
+	tok, err := ts.conf.Refresh(ctx, ts.tok.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed refreshing token: %w", err)
+	}
+	ts.tok = tok
+	if ts.onRefresh != nil {
+		ts.onRefresh(tok)
+	}
+
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,159 @@
+package oauth_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/testutils"
+	"github.com/ioki-mobility/go-outline/oauth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ outline.TokenSource = (*oauth.TokenSource)(nil)
+
+func testConfig(rt http.RoundTripper) *oauth.Config {
+	return &oauth.Config{
+		ServerURL:    "https://wiki.example.com/",
+		ClientID:     "client id",
+		ClientSecret: "client secret",
+		RedirectURL:  "https://app.example.com/callback",
+		Scopes:       []string{"read", "documents:write"},
+		HTTPClient:   &http.Client{Transport: rt},
+	}
+}
+
+func TestConfigAuthCodeURL(t *testing.T) {
+	conf := testConfig(nil)
+
+	u, err := url.Parse(conf.AuthCodeURL("xyz"))
+	require.NoError(t, err)
+	assert.Equal(t, "https", u.Scheme)
+	assert.Equal(t, "wiki.example.com", u.Host)
+	assert.Equal(t, "/oauth/authorize", u.Path)
+	assert.Equal(t, url.Values{
+		"response_type": {"code"},
+		"client_id":     {"client id"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"scope":         {"read documents:write"},
+		"state":         {"xyz"},
+	}, u.Query())
+}
+
+func TestConfigExchange(t *testing.T) {
+	conf := testConfig(&testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "https://wiki.example.com/oauth/token", r.URL.String())
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {"the code"},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"client_id":     {"client id"},
+			"client_secret": {"client secret"},
+		}, r.PostForm)
+
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"access_token":"access","token_type":"Bearer","refresh_token":"refresh","expires_in":3600}`,
+			)),
+		}, nil
+	}})
+
+	tok, err := conf.Exchange(context.Background(), "the code")
+	require.NoError(t, err)
+	assert.Equal(t, "access", tok.AccessToken)
+	assert.Equal(t, "refresh", tok.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tok.Expiry, time.Minute)
+	assert.True(t, tok.Valid())
+}
+
+func TestConfigExchange_failed(t *testing.T) {
+	conf := testConfig(&testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusBadRequest,
+			Body: io.NopCloser(strings.NewReader(
+				`{"error":"invalid_grant","error_description":"code expired"}`,
+			)),
+		}, nil
+	}})
+
+	tok, err := conf.Exchange(context.Background(), "the code")
+	assert.Nil(t, tok)
+
+	var e *oauth.Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusBadRequest, e.Status)
+	assert.Equal(t, "invalid_grant", e.Code)
+	assert.Equal(t, "code expired", e.Description)
+}
+
+func TestTokenSource(t *testing.T) {
+	refreshCount := atomic.Uint32{}
+	conf := testConfig(&testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		refreshCount.Add(1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "old refresh", r.PostForm.Get("refresh_token"))
+
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"access_token":"new access","token_type":"Bearer","expires_in":3600}`,
+			)),
+		}, nil
+	}})
+
+	var refreshed *oauth.Token
+	ts := conf.TokenSource(&oauth.Token{
+		AccessToken:  "old access",
+		RefreshToken: "old refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	}).OnRefresh(func(tok *oauth.Token) { refreshed = tok })
+
+	// First call refreshes the expired token, the second one reuses the refreshed token.
+	for i := 0; i < 2; i++ {
+		tok, err := ts.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "new access", tok)
+	}
+	assert.Equal(t, uint32(1), refreshCount.Load())
+	require.NotNil(t, refreshed)
+	assert.Equal(t, "old refresh", refreshed.RefreshToken)
+}
+
+func TestTokenSource_withClient(t *testing.T) {
+	hc := &http.Client{Transport: &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, "Bearer valid access", r.Header.Get("Authorization"))
+
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {"id": "collection id"}}`)),
+		}, nil
+	}}}
+
+	ts := testConfig(nil).TokenSource(&oauth.Token{AccessToken: "valid access"})
+	cl := outline.NewWithTokenSource("https://wiki.example.com", hc, ts)
+
+	col, err := cl.Collections().Get("collection id").Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, outline.CollectionID("collection id"), col.ID)
+}
@@ -0,0 +1,327 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/rsjethani/rsling"
+)
+
+// OAuthClientsClient exposes CRUD operations around the OAuth clients resource.
+type OAuthClientsClient struct {
+	sl *rsling.Sling
+}
+
+// newOAuthClientsClient creates a new instance of OAuthClientsClient.
+func newOAuthClientsClient(sl *rsling.Sling) *OAuthClientsClient {
+	return &OAuthClientsClient{sl: sl}
+}
+
+// List returns a client for listing all OAuth clients.
+// API reference: https://www.getoutline.com/developers#tag/OAuthClients/paths/~1oauthClients.list/post
+func (cl *OAuthClientsClient) List() *OAuthClientsListClient {
+	return newOAuthClientsListClient(cl.sl)
+}
+
+// Get returns a client for retrieving the OAuth client identified by id.
+// API reference: https://www.getoutline.com/developers#tag/OAuthClients/paths/~1oauthClients.info/post
+func (cl *OAuthClientsClient) Get(id OAuthClientID) *OAuthClientsGetClient {
+	return newOAuthClientsGetClient(cl.sl, id)
+}
+
+// Create returns a client for creating an OAuth client. After authorization users are sent back to one of the
+// redirectURIs.
+// API reference: https://www.getoutline.com/developers#tag/OAuthClients/paths/~1oauthClients.create/post
+func (cl *OAuthClientsClient) Create(name string, redirectURIs []string) *OAuthClientsCreateClient {
+	return newOAuthClientsCreateClient(cl.sl, name, redirectURIs)
+}
+
+// Update returns a client for updating the OAuth client identified by id.
+// API reference: https://www.getoutline.com/developers#tag/OAuthClients/paths/~1oauthClients.update/post
+func (cl *OAuthClientsClient) Update(id OAuthClientID) *OAuthClientsUpdateClient {
+	return newOAuthClientsUpdateClient(cl.sl, id)
+}
+
+// Delete returns a client for deleting the OAuth client identified by id. All tokens issued to it are revoked.
+// API reference: https://www.getoutline.com/developers#tag/OAuthClients/paths/~1oauthClients.delete/post
+func (cl *OAuthClientsClient) Delete(id OAuthClientID) *OAuthClientsDeleteClient {
+	return newOAuthClientsDeleteClient(cl.sl, id)
+}
+
+// OAuthClientsListClient is a client for listing OAuth clients.
+type OAuthClientsListClient struct {
+	sl *rsling.Sling
+}
+
+func newOAuthClientsListClient(sl *rsling.Sling) *OAuthClientsListClient {
+	copy := sl.New()
+	copy.Post(common.OAuthClientsListEndpoint())
+
+	return &OAuthClientsListClient{sl: copy}
+}
+
+// OAuthClientsListFn is the type of function called by [OAuthClientsListClient.Do] for every OAuth client it finds.
+type OAuthClientsListFn func(*OAuthClient, error) (bool, error)
+
+// Do makes the actual request for listing OAuth clients. If the request is successful then fn is called sequentially
+// with every OAuth client received. But if there is some error/bad response then fn is called with the error. If fn
+// returns false then the whole process is aborted otherwise the request is retried.
+func (cl *OAuthClientsListClient) Do(ctx context.Context, fn OAuthClientsListFn) error {
+	success := &struct {
+		Data       []*OAuthClient `json:"data"`
+		Pagination pagination     `json:"pagination"`
+	}{}
+
+	params := &paginationQueryParams{}
+	for {
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, oc := range success.Data {
+			if ok, e := fn(oc, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
+
+// OAuthClientsGetClient is a client for retrieving a single OAuth client.
+type OAuthClientsGetClient struct {
+	sl *rsling.Sling
+}
+
+func newOAuthClientsGetClient(sl *rsling.Sling, id OAuthClientID) *OAuthClientsGetClient {
+	data := struct {
+		ID OAuthClientID `json:"id"`
+	}{ID: id}
+
+	copy := sl.New()
+	copy.Post(common.OAuthClientsGetEndpoint()).BodyJSON(&data)
+
+	return &OAuthClientsGetClient{sl: copy}
+}
+
+// Do makes the actual request for fetching the OAuth client.
+func (cl *OAuthClientsGetClient) Do(ctx context.Context) (*OAuthClient, error) {
+	success := &struct {
+		Data *OAuthClient `json:"data"`
+	}{}
+
+	br, err := request(ctx, cl.sl, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// oauthClientsCreateParams represents the Outline OAuthClients.create parameters
+type oauthClientsCreateParams struct {
+	Name          string   `json:"name"`
+	RedirectURIs  []string `json:"redirectUris"`
+	Description   string   `json:"description,omitempty"`
+	DeveloperName string   `json:"developerName,omitempty"`
+	DeveloperURL  string   `json:"developerUrl,omitempty"`
+	AvatarURL     string   `json:"avatarUrl,omitempty"`
+	Published     bool     `json:"published,omitempty"`
+}
+
+// OAuthClientsCreateClient is a client for creating a single OAuth client.
+type OAuthClientsCreateClient struct {
+	sl     *rsling.Sling
+	params oauthClientsCreateParams
+}
+
+func newOAuthClientsCreateClient(sl *rsling.Sling, name string, redirectURIs []string) *OAuthClientsCreateClient {
+	copy := sl.New()
+	params := oauthClientsCreateParams{Name: name, RedirectURIs: redirectURIs}
+	return &OAuthClientsCreateClient{sl: copy, params: params}
+}
+
+func (cl *OAuthClientsCreateClient) Description(desc string) *OAuthClientsCreateClient {
+	c := *cl
+	c.params.Description = desc
+	return &c
+}
+
+func (cl *OAuthClientsCreateClient) DeveloperName(name string) *OAuthClientsCreateClient {
+	c := *cl
+	c.params.DeveloperName = name
+	return &c
+}
+
+func (cl *OAuthClientsCreateClient) DeveloperURL(url string) *OAuthClientsCreateClient {
+	c := *cl
+	c.params.DeveloperURL = url
+	return &c
+}
+
+func (cl *OAuthClientsCreateClient) AvatarURL(url string) *OAuthClientsCreateClient {
+	c := *cl
+	c.params.AvatarURL = url
+	return &c
+}
+
+// Published configures whether the OAuth client can be used by users of other workspaces.
+func (cl *OAuthClientsCreateClient) Published(published bool) *OAuthClientsCreateClient {
+	c := *cl
+	c.params.Published = published
+	return &c
+}
+
+// Do makes the actual request to create an OAuth client. The returned client contains the ClientSecret.
+func (cl *OAuthClientsCreateClient) Do(ctx context.Context) (*OAuthClient, error) {
+	req := cl.sl.New().Post(common.OAuthClientsCreateEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data *OAuthClient `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// oauthClientsUpdateParams represents the Outline OAuthClients.update parameters. Only parameters which were set are
+// sent hence all other properties of the OAuth client are left as is.
+type oauthClientsUpdateParams struct {
+	ID            OAuthClientID       `json:"id"`
+	Name          *optional[string]   `json:"name,omitempty"`
+	RedirectURIs  *optional[[]string] `json:"redirectUris,omitempty"`
+	Description   *optional[string]   `json:"description,omitempty"`
+	DeveloperName *optional[string]   `json:"developerName,omitempty"`
+	DeveloperURL  *optional[string]   `json:"developerUrl,omitempty"`
+	AvatarURL     *optional[string]   `json:"avatarUrl,omitempty"`
+	Published     *optional[bool]     `json:"published,omitempty"`
+}
+
+// OAuthClientsUpdateClient is a client for updating a single OAuth client.
+type OAuthClientsUpdateClient struct {
+	sl     *rsling.Sling
+	params oauthClientsUpdateParams
+}
+
+func newOAuthClientsUpdateClient(sl *rsling.Sling, id OAuthClientID) *OAuthClientsUpdateClient {
+	copy := sl.New()
+	params := oauthClientsUpdateParams{ID: id}
+	return &OAuthClientsUpdateClient{sl: copy, params: params}
+}
+
+func (cl *OAuthClientsUpdateClient) Name(name string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.Name = some(name)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) RedirectURIs(uris []string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.RedirectURIs = some(uris)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) Description(desc string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.Description = some(desc)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) DeveloperName(name string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.DeveloperName = some(name)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) DeveloperURL(url string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.DeveloperURL = some(url)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) AvatarURL(url string) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.AvatarURL = some(url)
+	return &c
+}
+
+func (cl *OAuthClientsUpdateClient) Published(published bool) *OAuthClientsUpdateClient {
+	c := *cl
+	c.params.Published = some(published)
+	return &c
+}
+
+// Do makes the actual request for updating the OAuth client.
+func (cl *OAuthClientsUpdateClient) Do(ctx context.Context) (*OAuthClient, error) {
+	req := cl.sl.New().Post(common.OAuthClientsUpdateEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data *OAuthClient `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// OAuthClientsDeleteClient is a client for deleting a single OAuth client.
+type OAuthClientsDeleteClient struct {
+	sl *rsling.Sling
+}
+
+func newOAuthClientsDeleteClient(sl *rsling.Sling, id OAuthClientID) *OAuthClientsDeleteClient {
+	data := struct {
+		ID OAuthClientID `json:"id"`
+	}{ID: id}
+
+	copy := sl.New()
+	copy.Post(common.OAuthClientsDeleteEndpoint()).BodyJSON(&data)
+
+	return &OAuthClientsDeleteClient{sl: copy}
+}
+
+// Do makes the actual request to delete the OAuth client.
+func (cl *OAuthClientsDeleteClient) Do(ctx context.Context) error {
+	br, err := request(ctx, cl.sl, nil)
+	if err != nil {
+		return fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package outlinetest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+)
+
+const (
+	filesCreateEndpoint = "files.create"
+
+	maxUploadSize = 100 << 20
+)
+
+// attachment is an attachment as stored by the server.
+type attachment struct {
+	id          string
+	key         string
+	name        string
+	contentType string
+	size        int
+	documentID  outline.DocumentID
+	data        []byte
+	uploaded    bool
+}
+
+func (a *attachment) url() string {
+	return "/api/" + common.AttachmentsRedirectEndpoint() + "?id=" + a.id
+}
+
+// AddAttachment stores an already uploaded attachment and returns its url (relative to the server url) as it would
+// appear in document text.
+func (s *Server) AddAttachment(name string, contentType string, data []byte, documentID outline.DocumentID) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := newID()
+	a := &attachment{
+		id:          id,
+		key:         "uploads/" + id + "/" + name,
+		name:        name,
+		contentType: contentType,
+		size:        len(data),
+		documentID:  documentID,
+		data:        data,
+		uploaded:    true,
+	}
+	s.attachments = append(s.attachments, a)
+
+	return a.url()
+}
+
+// Attachment returns the content of the attachment available at url, if it was uploaded.
+func (s *Server) Attachment(url string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.attachments {
+		if a.uploaded && (a.url() == url || s.URL+a.url() == url) {
+			return a.data, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) attachmentsCreate(req *request) {
+	params := struct {
+		Name        string             `json:"name"`
+		ContentType string             `json:"contentType"`
+		Size        int                `json:"size"`
+		DocumentID  outline.DocumentID `json:"documentId"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if params.Name == "" {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "name: Required")
+		return
+	}
+	if params.Size > maxUploadSize {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "size: File size too large")
+		return
+	}
+	if params.DocumentID != "" && s.findDocument(params.DocumentID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	id := newID()
+	a := &attachment{
+		id:          id,
+		key:         "uploads/" + id + "/" + params.Name,
+		name:        params.Name,
+		contentType: params.ContentType,
+		size:        params.Size,
+		documentID:  params.DocumentID,
+	}
+	s.attachments = append(s.attachments, a)
+
+	req.writeData(map[string]any{
+		"maxUploadSize": maxUploadSize,
+		"uploadUrl":     s.URL + "/api/" + filesCreateEndpoint,
+		"form": map[string]any{
+			"Cache-Control": "max-age=31557600",
+			"Content-Type":  a.contentType,
+			"key":           a.key,
+		},
+		"attachment": map[string]any{
+			"id":          a.id,
+			"documentId":  a.documentID,
+			"contentType": a.contentType,
+			"name":        a.name,
+			"url":         a.url(),
+			"size":        a.size,
+		},
+	})
+}
+
+// filesCreate accepts the multipart upload of an attachment's content created earlier via attachments.create.
+func (s *Server) filesCreate(req *request) {
+	req.r.Body = io.NopCloser(bytes.NewReader(req.body))
+	if err := req.r.ParseMultipartForm(maxUploadSize); err != nil {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "invalid multipart form: "+err.Error())
+		return
+	}
+
+	key := req.r.FormValue("key")
+	var a *attachment
+	for _, at := range s.attachments {
+		if at.key == key {
+			a = at
+		}
+	}
+	if a == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Attachment not found")
+		return
+	}
+
+	f, _, err := req.r.FormFile("file")
+	if err != nil {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "file: Required")
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "file: "+err.Error())
+		return
+	}
+	a.data = data
+	a.size = len(data)
+	a.uploaded = true
+
+	req.writeSuccess()
+}
+
+// attachmentsRedirect serves the content of an uploaded attachment. The real server redirects to the storage instead.
+func (s *Server) attachmentsRedirect(req *request) {
+	id := req.r.URL.Query().Get("id")
+	for _, a := range s.attachments {
+		if a.id == id && a.uploaded {
+			req.w.Header().Set("Content-Type", a.contentType)
+			req.w.WriteHeader(http.StatusOK)
+			_, _ = req.w.Write(a.data)
+			return
+		}
+	}
+	writeError(req.w, http.StatusNotFound, "not_found", "Attachment not found")
+}
@@ -0,0 +1,209 @@
+package outlinetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// AddCollection adds a copy of col to the server state and returns the stored collection. Missing ID and timestamps
+// are filled in.
+func (s *Server) AddCollection(col outline.Collection) *outline.Collection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if col.ID == "" {
+		col.ID = outline.CollectionID(newID())
+	}
+	if col.CreatedAt.IsZero() {
+		col.CreatedAt = now()
+	}
+	if col.UpdatedAt.IsZero() {
+		col.UpdatedAt = col.CreatedAt
+	}
+	if col.Index == "" {
+		col.Index = string(rune('P' + len(s.collections)))
+	}
+	if col.URLID == "" {
+		col.URLID = newURLID()
+	}
+	col.URL = collectionURL(&col)
+
+	s.collections = append(s.collections, &col)
+	c := col
+	return &c
+}
+
+// Collection returns a copy of the collection identified by id as currently stored by the server.
+func (s *Server) Collection(id outline.CollectionID) (*outline.Collection, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col := s.findCollection(id)
+	if col == nil {
+		return nil, false
+	}
+	c := *col
+	return &c, true
+}
+
+// findCollection returns the stored collection identified by id or nil. It must be called with the lock held.
+func (s *Server) findCollection(id outline.CollectionID) *outline.Collection {
+	for _, col := range s.collections {
+		if col.ID == id {
+			return col
+		}
+	}
+	return nil
+}
+
+func (s *Server) collectionsInfo(req *request) {
+	params := struct {
+		ID outline.CollectionID `json:"id"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	col := s.findCollection(params.ID)
+	if col == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+	req.writeData(col)
+}
+
+func (s *Server) collectionsList(req *request) {
+	writeList(req, s.pageLimit, s.collections)
+}
+
+func (s *Server) collectionsCreate(req *request) {
+	params := struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Permission  string `json:"permission"`
+		Color       string `json:"color"`
+		Private     bool   `json:"private"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if strings.TrimSpace(params.Name) == "" {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "name: Required")
+		return
+	}
+	if !validPermission(params.Permission) {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "permission: Invalid enum value")
+		return
+	}
+
+	ts := now()
+	col := &outline.Collection{
+		ID:          outline.CollectionID(newID()),
+		Name:        params.Name,
+		Description: params.Description,
+		Permission:  params.Permission,
+		Color:       params.Color,
+		Index:       string(rune('P' + len(s.collections))),
+		Sort:        outline.CollectionSort{Field: "index", Direction: "asc"},
+		URLID:       newURLID(),
+		CreatedAt:   ts,
+		UpdatedAt:   ts,
+	}
+	col.URL = collectionURL(col)
+	s.collections = append(s.collections, col)
+
+	req.writeData(col)
+}
+
+func (s *Server) collectionsUpdate(req *request) {
+	// Pointer fields allow distinguishing between fields which were not sent and fields sent with zero value.
+	params := struct {
+		ID          outline.CollectionID `json:"id"`
+		Name        *string              `json:"name"`
+		Description *string              `json:"description"`
+		Permission  *string              `json:"permission"`
+		Color       json.RawMessage      `json:"color"` // null removes the color
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	col := s.findCollection(params.ID)
+	if col == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+	if params.Permission != nil && !validPermission(*params.Permission) {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "permission: Invalid enum value")
+		return
+	}
+	var color *string
+	if params.Color != nil {
+		if err := json.Unmarshal(params.Color, &color); err != nil {
+			writeError(req.w, http.StatusBadRequest, "validation_error", "color: Expected string")
+			return
+		}
+		if color == nil {
+			color = new(string)
+		}
+	}
+
+	if params.Name != nil {
+		col.Name = *params.Name
+		col.URL = collectionURL(col)
+	}
+	if params.Description != nil {
+		col.Description = *params.Description
+	}
+	if params.Permission != nil {
+		col.Permission = *params.Permission
+	}
+	if color != nil {
+		col.Color = *color
+	}
+	col.UpdatedAt = now()
+
+	req.writeData(col)
+}
+
+func (s *Server) collectionsDocuments(req *request) {
+	params := struct {
+		ID outline.CollectionID `json:"id"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if s.findCollection(params.ID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+
+	req.writeData(s.structure(params.ID, ""))
+}
+
+// structure returns the tree of published documents, not including templates, of the collection identified by id below
+// parent.
+func (s *Server) structure(id outline.CollectionID, parent outline.DocumentID) outline.DocumentStructure {
+	st := outline.DocumentStructure{}
+	for _, doc := range s.documents {
+		if doc.CollectionID != id || doc.ParentDocumentID != parent || !isPublished(doc) || isGone(doc) || doc.Template {
+			continue
+		}
+		st = append(st, outline.DocumentSummary{
+			ID:       doc.ID,
+			Title:    doc.Title,
+			URL:      documentURL(doc),
+			Children: s.structure(id, doc.ID),
+		})
+	}
+	return st
+}
+
+func validPermission(p string) bool {
+	return p == "" || p == string(outline.PermissionRead) || p == string(outline.PermissionReadWrite)
+}
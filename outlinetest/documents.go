@@ -0,0 +1,411 @@
+package outlinetest
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// AddDocument adds a copy of doc to the server state and returns the stored document. Missing ID, url id, revision,
+// authors and timestamps are filled in. Documents other than templates are published right away.
+func (s *Server) AddDocument(doc outline.Document) *outline.Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if doc.ID == "" {
+		doc.ID = outline.DocumentID(newID())
+	}
+	if doc.URLID == "" {
+		doc.URLID = newURLID()
+	}
+	if doc.Revision == 0 {
+		doc.Revision = 1
+	}
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = now()
+	}
+	if doc.UpdatedAt.IsZero() {
+		doc.UpdatedAt = doc.CreatedAt
+	}
+	if doc.PublishedAt.IsZero() && !doc.Template {
+		doc.PublishedAt = doc.CreatedAt
+	}
+	if doc.CreatedBy.ID == "" {
+		doc.CreatedBy = s.User
+	}
+	if doc.UpdatedBy.ID == "" {
+		doc.UpdatedBy = s.User
+	}
+
+	s.documents = append(s.documents, &doc)
+	d := doc
+	return &d
+}
+
+// Document returns a copy of the document identified by id as currently stored by the server.
+func (s *Server) Document(id outline.DocumentID) (*outline.Document, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := s.findDocument(id)
+	if doc == nil {
+		return nil, false
+	}
+	d := *doc
+	return &d, true
+}
+
+// ShareDocument creates a public share of the document identified by id and returns its share id.
+func (s *Server) ShareDocument(id outline.DocumentID) outline.DocumentShareID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shareID := outline.DocumentShareID(newID())
+	s.shares[shareID] = id
+	return shareID
+}
+
+// findDocument returns the stored document identified by either its id or url id, just like the real server accepts
+// both. It must be called with the lock held.
+func (s *Server) findDocument(id outline.DocumentID) *outline.Document {
+	for _, doc := range s.documents {
+		if doc.ID == id || (doc.URLID != "" && doc.URLID == string(id)) {
+			return doc
+		}
+	}
+	return nil
+}
+
+// presentDocument returns a copy of doc the way the server presents it i.e. with its current url.
+func presentDocument(doc *outline.Document) *outline.Document {
+	d := *doc
+	d.URL = documentURL(doc)
+	return &d
+}
+
+func (s *Server) documentsInfo(req *request) {
+	params := struct {
+		ID      outline.DocumentID      `json:"id"`
+		ShareID outline.DocumentShareID `json:"shareId"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	// With a share id the id is optional and refers to a document within the shared one.
+	id := params.ID
+	if params.ShareID != "" {
+		shared, ok := s.shares[params.ShareID]
+		if !ok {
+			writeError(req.w, http.StatusNotFound, "not_found", "Share not found")
+			return
+		}
+		if id == "" {
+			id = shared
+		}
+	}
+	if id == "" {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "id: one of id or shareId is required")
+		return
+	}
+
+	doc := s.findDocument(id)
+	if doc == nil || !doc.DeletedAt.IsZero() {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+	req.writeData(presentDocument(doc))
+}
+
+func (s *Server) documentsList(req *request) {
+	params := struct {
+		CollectionID       outline.CollectionID `json:"collectionId"`
+		ParentDocumentID   outline.DocumentID   `json:"parentDocumentId"`
+		BacklinkDocumentID outline.DocumentID   `json:"backlinkDocumentId"`
+		Template           bool                 `json:"template"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	var target *outline.Document
+	if params.BacklinkDocumentID != "" {
+		if target = s.findDocument(params.BacklinkDocumentID); target == nil {
+			writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+			return
+		}
+	}
+
+	docs := []*outline.Document{}
+	for _, doc := range s.documents {
+		// Templates are listed regardless of being published, just like the real server lists them.
+		if isGone(doc) || doc.Template != params.Template || (!doc.Template && !isPublished(doc)) {
+			continue
+		}
+		if params.CollectionID != "" && doc.CollectionID != params.CollectionID {
+			continue
+		}
+		if params.ParentDocumentID != "" && doc.ParentDocumentID != params.ParentDocumentID {
+			continue
+		}
+		if target != nil && (doc.ID == target.ID || !strings.Contains(doc.Text, target.URLID)) {
+			continue
+		}
+		docs = append(docs, presentDocument(doc))
+	}
+
+	writeList(req, s.pageLimit, docs)
+}
+
+func (s *Server) documentsCreate(req *request) {
+	params := struct {
+		CollectionID     outline.CollectionID `json:"collectionId"`
+		ParentDocumentID outline.DocumentID   `json:"parentDocumentId"`
+		Publish          bool                 `json:"publish"`
+		Template         bool                 `json:"template"`
+		TemplateID       outline.TemplateID   `json:"templateId"`
+		Text             string               `json:"text"`
+		Title            string               `json:"title"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if params.ParentDocumentID != "" {
+		parent := s.findDocument(params.ParentDocumentID)
+		if parent == nil || isGone(parent) {
+			writeError(req.w, http.StatusNotFound, "not_found", "Parent document not found")
+			return
+		}
+		if params.CollectionID == "" {
+			params.CollectionID = parent.CollectionID
+		}
+		params.ParentDocumentID = parent.ID
+	}
+	if params.CollectionID == "" {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "collectionId: Required")
+		return
+	}
+	if s.findCollection(params.CollectionID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+
+	ts := now()
+	doc := &outline.Document{
+		ID:               outline.DocumentID(newID()),
+		CollectionID:     params.CollectionID,
+		ParentDocumentID: params.ParentDocumentID,
+		Title:            params.Title,
+		Text:             params.Text,
+		URLID:            newURLID(),
+		Template:         params.Template,
+		TemplateID:       params.TemplateID,
+		Revision:         1,
+		CreatedAt:        ts,
+		CreatedBy:        s.User,
+		UpdatedAt:        ts,
+		UpdatedBy:        s.User,
+	}
+	if params.Publish {
+		doc.PublishedAt = ts
+	}
+	s.documents = append(s.documents, doc)
+
+	req.writeData(presentDocument(doc))
+}
+
+func (s *Server) documentsUpdate(req *request) {
+	// Pointer fields allow distinguishing between fields which were not sent and fields sent with zero value.
+	params := struct {
+		ID      outline.DocumentID `json:"id"`
+		Title   *string            `json:"title"`
+		Text    *string            `json:"text"`
+		Append  bool               `json:"append"`
+		Publish bool               `json:"publish"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || !doc.DeletedAt.IsZero() {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	if params.Title != nil {
+		doc.Title = *params.Title
+	}
+	if params.Text != nil {
+		if params.Append {
+			doc.Text += *params.Text
+		} else {
+			doc.Text = *params.Text
+		}
+	}
+	ts := now()
+	if params.Publish && doc.PublishedAt.IsZero() {
+		doc.PublishedAt = ts
+	}
+	doc.Revision++
+	doc.UpdatedAt = ts
+	doc.UpdatedBy = s.User
+
+	req.writeData(presentDocument(doc))
+}
+
+func (s *Server) documentsDelete(req *request) {
+	params := struct {
+		ID        outline.DocumentID `json:"id"`
+		Permanent bool               `json:"permanent"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || (!doc.DeletedAt.IsZero() && !params.Permanent) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	if params.Permanent {
+		for i, d := range s.documents {
+			if d == doc {
+				s.documents = append(s.documents[:i], s.documents[i+1:]...)
+				break
+			}
+		}
+	} else {
+		doc.DeletedAt = now()
+	}
+
+	req.writeSuccess()
+}
+
+func (s *Server) documentsMove(req *request) {
+	params := struct {
+		ID               outline.DocumentID   `json:"id"`
+		CollectionID     outline.CollectionID `json:"collectionId"`
+		ParentDocumentID outline.DocumentID   `json:"parentDocumentId"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || isGone(doc) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+	moved := append([]*outline.Document{doc}, s.descendants(doc.ID)...)
+	if params.ParentDocumentID != "" {
+		parent := s.findDocument(params.ParentDocumentID)
+		if parent == nil || isGone(parent) {
+			writeError(req.w, http.StatusNotFound, "not_found", "Parent document not found")
+			return
+		}
+		for _, d := range moved {
+			if d == parent {
+				writeError(req.w, http.StatusBadRequest, "validation_error", "Cannot move a document below itself")
+				return
+			}
+		}
+		if params.CollectionID == "" {
+			params.CollectionID = parent.CollectionID
+		}
+		params.ParentDocumentID = parent.ID
+	}
+	if params.CollectionID == "" {
+		params.CollectionID = doc.CollectionID
+	}
+	if s.findCollection(params.CollectionID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+
+	doc.ParentDocumentID = params.ParentDocumentID
+	// Documents are kept in creation order which is also the order of siblings, the moved document becomes the last one.
+	for i, d := range s.documents {
+		if d == doc {
+			s.documents = append(append(s.documents[:i:i], s.documents[i+1:]...), doc)
+			break
+		}
+	}
+
+	data := struct {
+		Documents []*outline.Document `json:"documents"`
+	}{}
+	for _, d := range moved {
+		d.CollectionID = params.CollectionID
+		data.Documents = append(data.Documents, presentDocument(d))
+	}
+	req.writeData(data)
+}
+
+func (s *Server) documentsArchive(req *request) {
+	params := struct {
+		ID outline.DocumentID `json:"id"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || isGone(doc) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	ts := now()
+	for _, d := range append([]*outline.Document{doc}, s.descendants(doc.ID)...) {
+		d.ArchivedAt = ts
+	}
+
+	req.writeData(presentDocument(doc))
+}
+
+// descendants returns the children of the document identified by id, their children and so on.
+func (s *Server) descendants(id outline.DocumentID) []*outline.Document {
+	docs := []*outline.Document{}
+	for _, d := range s.documents {
+		if d.ParentDocumentID == id && !isGone(d) {
+			docs = append(docs, d)
+			docs = append(docs, s.descendants(d.ID)...)
+		}
+	}
+	return docs
+}
+
+func isPublished(doc *outline.Document) bool {
+	return !doc.PublishedAt.IsZero()
+}
+
+// isGone returns true if the document is deleted or archived i.e. no longer part of its collection's structure.
+func isGone(doc *outline.Document) bool {
+	return !doc.DeletedAt.IsZero() || !doc.ArchivedAt.IsZero()
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// documentURL returns the path of the document the same way the real server builds it e.g. /doc/my-title-hDYep1TPAM
+func documentURL(doc *outline.Document) string {
+	return "/doc/" + slug(doc.Title) + "-" + doc.URLID
+}
+
+// collectionURL returns the path of the collection the same way the real server builds it e.g.
+// /collection/runbooks-k1CmJHTrFb
+func collectionURL(col *outline.Collection) string {
+	return "/collection/" + slug(col.Name) + "-" + col.URLID
+}
+
+func slug(title string) string {
+	s := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if s == "" {
+		return "untitled"
+	}
+	return s
+}
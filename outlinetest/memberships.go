@@ -0,0 +1,186 @@
+package outlinetest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// groupMembership is a group given access to a collection.
+type groupMembership struct {
+	membership *outline.CollectionGroupMembership
+	group      *outline.Group
+}
+
+// CollectionMemberships returns the user memberships of the collection identified by id.
+func (s *Server) CollectionMemberships(id outline.CollectionID) []outline.CollectionMembership {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	memberships := []outline.CollectionMembership{}
+	for _, m := range s.collectionMemberships {
+		if m.CollectionID == id {
+			memberships = append(memberships, *m)
+		}
+	}
+	return memberships
+}
+
+// AddCollectionGroup gives the group g access to the collection identified by id and returns the stored group. A
+// missing ID is filled in. Groups are only visible through the memberships of collections.
+func (s *Server) AddCollectionGroup(
+	id outline.CollectionID, g outline.Group, permission outline.Permission,
+) *outline.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if g.ID == "" {
+		g.ID = outline.GroupID(newID())
+	}
+	s.groupMemberships = append(s.groupMemberships, &groupMembership{
+		membership: &outline.CollectionGroupMembership{
+			ID:           outline.MembershipID(newID()),
+			CollectionID: id,
+			GroupID:      g.ID,
+			Permission:   permission,
+		},
+		group: &g,
+	})
+	c := g
+	return &c
+}
+
+func (s *Server) collectionsMemberships(req *request) {
+	params := struct {
+		ID         outline.CollectionID `json:"id"`
+		Query      string               `json:"query"`
+		Permission outline.Permission   `json:"permission"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+	if s.findCollection(params.ID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+
+	memberships := []*outline.CollectionMembership{}
+	for _, m := range s.collectionMemberships {
+		u := s.findUser(m.UserID)
+		if m.CollectionID != params.ID || (params.Permission != "" && m.Permission != params.Permission) ||
+			!strings.Contains(strings.ToLower(u.Name), strings.ToLower(params.Query)) {
+			continue
+		}
+		memberships = append(memberships, m)
+	}
+
+	// Users are returned separately from the memberships hence the page is cut here instead of using writeList.
+	offset, limit := req.page(s.pageLimit)
+	start, end := min(offset, len(memberships)), min(offset+limit, len(memberships))
+	users := []*outline.User{}
+	for _, m := range memberships[start:end] {
+		users = append(users, s.findUser(m.UserID))
+	}
+
+	writeJSON(req.w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"users":       users,
+			"memberships": memberships[start:end],
+		},
+		"pagination": map[string]any{"offset": offset, "limit": limit},
+		"policies":   []any{},
+	})
+}
+
+func (s *Server) collectionsAddUser(req *request) {
+	params := struct {
+		ID         outline.CollectionID `json:"id"`
+		UserID     outline.UserID       `json:"userId"`
+		Permission outline.Permission   `json:"permission"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if s.findCollection(params.ID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+	u := s.findUser(params.UserID)
+	if u == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "User not found")
+		return
+	}
+	if params.Permission == "" {
+		params.Permission = outline.PermissionReadWrite
+	}
+	if !validPermission(string(params.Permission)) {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "permission: Invalid enum value")
+		return
+	}
+
+	var membership *outline.CollectionMembership
+	for _, m := range s.collectionMemberships {
+		if m.CollectionID == params.ID && m.UserID == params.UserID {
+			membership = m
+		}
+	}
+	if membership == nil {
+		membership = &outline.CollectionMembership{
+			ID:           outline.MembershipID(newID()),
+			CollectionID: params.ID,
+			UserID:       params.UserID,
+		}
+		s.collectionMemberships = append(s.collectionMemberships, membership)
+	}
+	membership.Permission = params.Permission
+
+	req.writeData(map[string]any{
+		"users":       []*outline.User{u},
+		"memberships": []*outline.CollectionMembership{membership},
+	})
+}
+
+func (s *Server) collectionsGroupMemberships(req *request) {
+	params := struct {
+		ID         outline.CollectionID `json:"id"`
+		Query      string               `json:"query"`
+		Permission outline.Permission   `json:"permission"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+	if s.findCollection(params.ID) == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Collection not found")
+		return
+	}
+
+	matching := []*groupMembership{}
+	for _, m := range s.groupMemberships {
+		if m.membership.CollectionID != params.ID ||
+			(params.Permission != "" && m.membership.Permission != params.Permission) ||
+			!strings.Contains(strings.ToLower(m.group.Name), strings.ToLower(params.Query)) {
+			continue
+		}
+		matching = append(matching, m)
+	}
+
+	// Groups are returned separately from the memberships hence the page is cut here instead of using writeList.
+	offset, limit := req.page(s.pageLimit)
+	start, end := min(offset, len(matching)), min(offset+limit, len(matching))
+	groups, memberships := []*outline.Group{}, []*outline.CollectionGroupMembership{}
+	for _, m := range matching[start:end] {
+		groups = append(groups, m.group)
+		memberships = append(memberships, m.membership)
+	}
+
+	writeJSON(req.w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"groups":           groups,
+			"groupMemberships": memberships,
+		},
+		"pagination": map[string]any{"offset": offset, "limit": limit},
+		"policies":   []any{},
+	})
+}
@@ -0,0 +1,339 @@
+package outlinetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+)
+
+const (
+	// cassetteVersion is the version of the cassette file format written by RecordingTransport.
+	cassetteVersion = 1
+
+	// redacted replaces scrubbed values.
+	redacted = "[REDACTED]"
+)
+
+// cassette is the content of a cassette file.
+type cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*interaction `json:"interactions"`
+}
+
+// interaction is a single recorded request/response pair.
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+
+	replayed bool
+}
+
+type recordedRequest struct {
+	Method   string      `json:"method"`
+	Endpoint string      `json:"endpoint"`
+	Query    string      `json:"query,omitempty"`
+	Headers  http.Header `json:"headers,omitempty"`
+	recordedBody
+}
+
+type recordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	recordedBody
+}
+
+// recordedBody keeps JSON bodies as is so that cassettes stay readable and reviewable. Other bodies are kept as text.
+type recordedBody struct {
+	Body    json.RawMessage `json:"body,omitempty"`
+	RawBody string          `json:"rawBody,omitempty"`
+}
+
+func (rb recordedBody) bytes() []byte {
+	if rb.Body != nil {
+		return rb.Body
+	}
+	return []byte(rb.RawBody)
+}
+
+// RecordingTransport is an [http.RoundTripper] which records real request/response pairs into a cassette file or
+// replays them back from it. Use it as the transport of the [http.Client] passed to [outline.New] to write regression
+// tests against real server payloads without needing the server. The Authorization header is always scrubbed from the
+// recording, use [RecordingTransport.ScrubHeaders] and [RecordingTransport.ScrubFields] for anything else sensitive.
+type RecordingTransport struct {
+	path   string
+	base   http.RoundTripper
+	replay bool
+
+	mu           sync.Mutex
+	headers      []string
+	fields       map[string]bool
+	interactions []*interaction
+}
+
+// NewRecordingTransport returns a transport which makes real requests via base, or [http.DefaultTransport] if nil, and
+// records them. Call [RecordingTransport.Save] to write the recording to the cassette file at path.
+func NewRecordingTransport(path string, base http.RoundTripper) *RecordingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RecordingTransport{
+		path:    path,
+		base:    base,
+		headers: []string{common.HdrKeyAuthorization},
+		fields:  map[string]bool{},
+	}
+}
+
+// NewReplayingTransport returns a transport which never makes real requests but serves the responses recorded in the
+// cassette file at path instead. A request is matched against recorded ones by its endpoint, query and JSON body. Every
+// recorded interaction is served only once, in the order of recording, so repeated identical requests get successive
+// responses.
+func NewReplayingTransport(path string) (*RecordingTransport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading cassette: %w", err)
+	}
+
+	c := &cassette{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("failed decoding cassette '%s': %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d, expected %d", c.Version, cassetteVersion)
+	}
+
+	return &RecordingTransport{
+		path:         path,
+		replay:       true,
+		headers:      []string{common.HdrKeyAuthorization},
+		fields:       map[string]bool{},
+		interactions: c.Interactions,
+	}, nil
+}
+
+// ScrubHeaders configures additional request and response headers whose values are replaced in the recording.
+func (rt *RecordingTransport) ScrubHeaders(headers ...string) *RecordingTransport {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.headers = append(rt.headers, headers...)
+	return rt
+}
+
+// ScrubFields configures JSON object keys, at any depth of request and response bodies, whose values are replaced in
+// the recording e.g. "email" or "clientSecret". When replaying, the same fields are scrubbed from requests before
+// matching them.
+func (rt *RecordingTransport) ScrubFields(fields ...string) *RecordingTransport {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for _, f := range fields {
+		rt.fields[f] = true
+	}
+	return rt
+}
+
+// RoundTrip records or replays req depending on how the transport was created.
+func (rt *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading request body: %w", err)
+	}
+
+	rt.mu.Lock()
+	recReq := recordedRequest{
+		Method:       req.Method,
+		Endpoint:     endpointOf(req),
+		Query:        req.URL.RawQuery,
+		Headers:      rt.scrubHeaders(req.Header),
+		recordedBody: rt.scrubBody(body),
+	}
+	rt.mu.Unlock()
+
+	if rt.replay {
+		return rt.replayRequest(req, recReq)
+	}
+
+	// Hand over an untouched copy of the request to the real transport.
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := rt.base.RoundTrip(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.interactions = append(rt.interactions, &interaction{
+		Request: recReq,
+		Response: recordedResponse{
+			Status:       resp.StatusCode,
+			Headers:      rt.scrubHeaders(resp.Header),
+			recordedBody: rt.scrubBody(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// replayRequest serves the first recorded, not yet replayed, interaction matching recReq.
+func (rt *RecordingTransport) replayRequest(req *http.Request, recReq recordedRequest) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for _, in := range rt.interactions {
+		if in.replayed || !matches(in.Request, recReq) {
+			continue
+		}
+		in.replayed = true
+
+		body := in.Response.bytes()
+		return &http.Response{
+			Request:       req,
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Headers.Clone(),
+			ContentLength: int64(len(body)),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	}
+
+	return nil, fmt.Errorf(
+		"no recorded interaction left for %s %s?%s in cassette '%s'", recReq.Method, recReq.Endpoint, recReq.Query, rt.path,
+	)
+}
+
+// Save writes all recorded interactions to the cassette file, creating missing directories. It does nothing when
+// replaying.
+func (rt *RecordingTransport) Save() error {
+	if rt.replay {
+		return nil
+	}
+
+	rt.mu.Lock()
+	c := &cassette{Version: cassetteVersion, Interactions: rt.interactions}
+	b, err := json.MarshalIndent(c, "", "  ")
+	rt.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed encoding cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rt.path), 0o755); err != nil {
+		return fmt.Errorf("failed creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(rt.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed writing cassette: %w", err)
+	}
+	return nil
+}
+
+// scrubHeaders returns a copy of h with values of configured headers replaced. It must be called with the lock held.
+func (rt *RecordingTransport) scrubHeaders(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range rt.headers {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+// scrubBody returns body prepared for recording with values of configured fields replaced. It must be called with the
+// lock held.
+func (rt *RecordingTransport) scrubBody(body []byte) recordedBody {
+	if len(body) == 0 {
+		return recordedBody{}
+	}
+
+	v, err := decodeJSON(body)
+	if err != nil {
+		return recordedBody{RawBody: string(body)}
+	}
+	b, err := json.Marshal(scrubValue(v, rt.fields))
+	if err != nil {
+		return recordedBody{RawBody: string(body)}
+	}
+	return recordedBody{Body: b}
+}
+
+// scrubValue replaces values of keys present in fields at any depth of the decoded JSON value v.
+func scrubValue(v any, fields map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if fields[k] {
+				t[k] = redacted
+				continue
+			}
+			t[k] = scrubValue(val, fields)
+		}
+	case []any:
+		for i := range t {
+			t[i] = scrubValue(t[i], fields)
+		}
+	}
+	return v
+}
+
+// matches returns true if the recorded request a and the incoming request b are equivalent. Bodies are compared as
+// JSON values so formatting and key order do not matter.
+func matches(a recordedRequest, b recordedRequest) bool {
+	if a.Method != b.Method || a.Endpoint != b.Endpoint || a.Query != b.Query || a.RawBody != b.RawBody {
+		return false
+	}
+	if len(a.Body) == 0 || len(b.Body) == 0 {
+		return len(a.Body) == len(b.Body)
+	}
+
+	av, aErr := decodeJSON(a.Body)
+	bv, bErr := decodeJSON(b.Body)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	ab, _ := json.Marshal(av)
+	bb, _ := json.Marshal(bv)
+	return bytes.Equal(ab, bb)
+}
+
+// endpointOf returns the API endpoint targeted by req e.g. "documents.info". Requests outside the API are identified
+// by their full path.
+func endpointOf(req *http.Request) string {
+	if i := strings.Index(req.URL.Path, "/api/"); i >= 0 {
+		return req.URL.Path[i+len("/api/"):]
+	}
+	return req.URL.Path
+}
+
+// decodeJSON decodes b keeping numbers as is, so that large ids survive a round trip.
+func decodeJSON(b []byte) (any, error) {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
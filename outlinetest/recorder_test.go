@@ -0,0 +1,80 @@
+package outlinetest_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTransport(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassettes", "documents.json")
+	ctx := context.Background()
+
+	// Record a few interactions against the fake server.
+	srv := outlinetest.NewServer(testApiKey)
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+
+	rec := outlinetest.NewRecordingTransport(cassette, srv.Client().Transport).ScrubFields("email")
+	cl := outline.New(srv.URL, &http.Client{Transport: rec}, testApiKey)
+
+	created, err := cl.Documents().Create("DB", col.ID).Text("v1").Publish(true).Do(ctx)
+	require.NoError(t, err)
+	updated, err := cl.Documents().Update(created.ID).Text("v2").Do(ctx)
+	require.NoError(t, err)
+	_, err = cl.Collections().Get("unknown").Do(ctx)
+	require.Error(t, err)
+
+	require.NoError(t, rec.Save())
+	srv.Close()
+
+	// Secrets must not end up in the cassette.
+	b, err := os.ReadFile(cassette)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), testApiKey)
+	assert.NotContains(t, string(b), srv.User.Email)
+	assert.Contains(t, string(b), `"endpoint": "documents.update"`)
+
+	// Replay the same requests without any server.
+	rep, err := outlinetest.NewReplayingTransport(cassette)
+	require.NoError(t, err)
+	rep.ScrubFields("email")
+	cl = outline.New("https://unreachable.invalid", &http.Client{Transport: rep}, "another key")
+
+	got, err := cl.Documents().Create("DB", col.ID).Text("v1").Publish(true).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+	assert.Equal(t, "[REDACTED]", got.CreatedBy.Email)
+
+	got, err = cl.Documents().Update(created.ID).Text("v2").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Revision, got.Revision)
+
+	_, err = cl.Collections().Get("unknown").Do(ctx)
+	require.Error(t, err)
+	assert.False(t, outline.IsTemporary(err))
+
+	// Every interaction is replayed only once and requests with different body don't match.
+	_, err = cl.Documents().Update(created.ID).Text("v2").Do(ctx)
+	assert.ErrorContains(t, err, "no recorded interaction left")
+	_, err = cl.Documents().Create("DB", col.ID).Text("v3").Do(ctx)
+	assert.ErrorContains(t, err, "no recorded interaction left")
+}
+
+func TestNewReplayingTransport_invalidCassette(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	_, err := outlinetest.NewReplayingTransport(cassette)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(cassette, []byte(`{"version": 42}`), 0o644))
+	_, err = outlinetest.NewReplayingTransport(cassette)
+	assert.ErrorContains(t, err, "unsupported cassette version")
+}
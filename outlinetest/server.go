@@ -0,0 +1,360 @@
+// Package outlinetest provides an in-process fake outline server for testing code which uses the outline client. The
+// fake keeps its state in memory and implements the endpoints wrapped by the outline client closely enough to run
+// tools end-to-end without a real server, including pagination, authorization and injectable faults. For tests based
+// on payloads of a real server see [RecordingTransport].
+//
+// A typical test looks like:
+//
+//	srv := outlinetest.NewServer("api key")
+//	defer srv.Close()
+//
+//	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+//	cl := srv.OutlineClient()
+//	// Use cl like any other outline client.
+package outlinetest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+)
+
+const (
+	// defaultPageLimit is the number of items returned per page if the request does not ask for a limit.
+	defaultPageLimit = 25
+	// maxPageLimit is the maximum number of items returned per page regardless of what the request asks for.
+	maxPageLimit = 100
+)
+
+// Fault describes a failure the server simulates instead of (or before) handling a request normally.
+type Fault struct {
+	// Endpoint the fault applies to e.g. "documents.update". Empty means every endpoint.
+	Endpoint string
+	// Latency delays the response. The delay is cut short if the request is cancelled.
+	Latency time.Duration
+	// Status is the HTTP status code the server responds with. Zero means the request is handled normally once
+	// Latency has passed. A 429 response carries Retry-After and RateLimit-* headers like the real server.
+	Status int
+	// Times limits how many requests are affected by the fault. Zero means all of them.
+	Times int
+}
+
+// Server is a fake outline server. Use [NewServer] to create one and [Server.Close] once done.
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the only key accepted by the server.
+	APIKey string
+	// User is the user on whose behalf all requests are made.
+	User outline.User
+
+	mu          sync.Mutex
+	pageLimit   int
+	faults      []*Fault
+	requests    map[string]int
+	collections []*outline.Collection
+	documents   []*outline.Document
+	shares      map[outline.DocumentShareID]outline.DocumentID
+	attachments []*attachment
+	users       []*outline.User
+	memberships []*outline.DocumentMembership
+
+	collectionMemberships []*outline.CollectionMembership
+	groupMemberships      []*groupMembership
+}
+
+// NewServer starts and returns a new fake server which accepts requests authorized with apiKey.
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		APIKey: apiKey,
+		User: outline.User{
+			ID:        newID(),
+			Name:      "Test User",
+			Email:     "test.user@example.com",
+			IsAdmin:   true,
+			CreatedAt: now(),
+		},
+		pageLimit: defaultPageLimit,
+		requests:  map[string]int{},
+		shares:    map[outline.DocumentShareID]outline.DocumentID{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// OutlineClient returns an outline client configured to talk to the server.
+func (s *Server) OutlineClient() *outline.Client {
+	return outline.New(s.URL, s.Client(), s.APIKey)
+}
+
+// SetPageLimit sets the number of items returned per page when a request does not specify a limit.
+func (s *Server) SetPageLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageLimit = limit
+}
+
+// InjectFault makes the server simulate f for matching requests. Faults are evaluated in the order they were injected
+// and the first matching one wins.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// RequestCount returns how many requests were made to endpoint e.g. "collections.list", including failed ones.
+func (s *Server) RequestCount(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// handlers maps endpoints to their handlers. All handlers are called with the server lock held.
+func (s *Server) handlers() map[string]func(*request) {
+	return map[string]func(*request){
+		common.CollectionsGetEndpoint():              s.collectionsInfo,
+		common.CollectionsListEndpoint():             s.collectionsList,
+		common.CollectionsCreateEndpoint():           s.collectionsCreate,
+		common.CollectionsUpdateEndpoint():           s.collectionsUpdate,
+		common.CollectionsStructureEndpoint():        s.collectionsDocuments,
+		common.CollectionsMembershipsEndpoint():      s.collectionsMemberships,
+		common.CollectionsAddUserEndpoint():          s.collectionsAddUser,
+		common.CollectionsGroupMembershipsEndpoint(): s.collectionsGroupMemberships,
+		common.UsersListEndpoint():                   s.usersList,
+		common.DocumentsGetEndpoint():                s.documentsInfo,
+		common.DocumentsListEndpoint():               s.documentsList,
+		common.DocumentsCreateEndpoint():             s.documentsCreate,
+		common.DocumentsUpdateEndpoint():             s.documentsUpdate,
+		common.DocumentsDeleteEndpoint():             s.documentsDelete,
+		common.DocumentsMoveEndpoint():               s.documentsMove,
+		common.DocumentsArchiveEndpoint():            s.documentsArchive,
+		common.DocumentsUsersEndpoint():              s.documentsUsers,
+		common.DocumentsMembershipsEndpoint():        s.documentsMemberships,
+		common.DocumentsAddUserEndpoint():            s.documentsAddUser,
+		common.DocumentsRemoveUserEndpoint():         s.documentsRemoveUser,
+		common.AttachmentsCreateEndpoint():           s.attachmentsCreate,
+		filesCreateEndpoint:                          s.filesCreate,
+		common.AttachmentsRedirectEndpoint():         s.attachmentsRedirect,
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	// Read the body right away as the server notices cancelled requests only after the body was consumed.
+	req, err := newRequest(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[endpoint]++
+	f := s.matchFault(endpoint)
+	s.mu.Unlock()
+
+	if f != nil && f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f != nil && f.Status != 0 {
+		writeFault(w, f.Status)
+		return
+	}
+
+	if r.Header.Get(common.HdrKeyAuthorization) != common.HdrValueAuthorization(s.APIKey) {
+		writeError(w, http.StatusUnauthorized, "authentication_required", "Authentication required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handler, ok := s.handlers()[endpoint]
+	if !ok || !strings.HasPrefix(r.URL.Path, "/api/") {
+		writeError(w, http.StatusNotFound, "not_found", "Resource not found")
+		return
+	}
+
+	handler(req)
+}
+
+// matchFault returns the first fault applicable to endpoint, if any. It must be called with the lock held.
+func (s *Server) matchFault(endpoint string) *Fault {
+	for i, f := range s.faults {
+		if f.Endpoint != "" && f.Endpoint != endpoint {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// request bundles everything a handler needs for processing a single request.
+type request struct {
+	w    http.ResponseWriter
+	r    *http.Request
+	body []byte
+}
+
+func newRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading body: %w", err)
+	}
+	return &request{w: w, r: r, body: body}, nil
+}
+
+// decode unmarshals JSON body into v. An empty body is treated as an empty object. If the body is invalid then a bad
+// request response is written and false returned.
+func (req *request) decode(v any) bool {
+	if len(req.body) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(req.body, v); err != nil {
+		writeError(req.w, http.StatusBadRequest, "validation_error", fmt.Sprintf("invalid body: %s", err))
+		return false
+	}
+	return true
+}
+
+// page returns the offset and limit requested either via query or body, just like the real server accepts them.
+func (req *request) page(defaultLimit int) (int, int) {
+	p := struct {
+		Offset int `json:"offset"`
+		Limit  int `json:"limit"`
+	}{}
+	_ = json.Unmarshal(req.body, &p)
+
+	if v, err := strconv.Atoi(req.r.URL.Query().Get("offset")); err == nil {
+		p.Offset = v
+	}
+	if v, err := strconv.Atoi(req.r.URL.Query().Get("limit")); err == nil {
+		p.Limit = v
+	}
+
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxPageLimit {
+		p.Limit = maxPageLimit
+	}
+	return p.Offset, p.Limit
+}
+
+// writeData writes data as a successful response.
+func (req *request) writeData(data any) {
+	writeJSON(req.w, http.StatusOK, map[string]any{"data": data, "policies": []any{}})
+}
+
+// writeList writes the page of items selected by the request as a successful paginated response.
+func writeList[T any](req *request, defaultLimit int, items []T) {
+	offset, limit := req.page(defaultLimit)
+
+	start, end := offset, offset+limit
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+
+	writeJSON(req.w, http.StatusOK, map[string]any{
+		"data": items[start:end],
+		"pagination": map[string]any{
+			"offset":   offset,
+			"limit":    limit,
+			"nextPath": fmt.Sprintf("%s?limit=%d&offset=%d", req.r.URL.Path, limit, offset+limit),
+		},
+		"policies": []any{},
+	})
+}
+
+// writeSuccess writes the response returned by endpoints which have no data to return.
+func (req *request) writeSuccess() {
+	writeJSON(req.w, http.StatusOK, map[string]any{"success": true})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set(common.HdrKeyContentType, common.HdrValueContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format used by the real server.
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, map[string]any{
+		"ok":      false,
+		"error":   code,
+		"status":  status,
+		"message": message,
+	})
+}
+
+// writeFault writes the response of an injected fault.
+func writeFault(w http.ResponseWriter, status int) {
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "1")
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+		writeError(w, status, "rate_limit_exceeded", "Rate limit exceeded for this operation")
+		return
+	}
+	writeError(w, status, strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_")), http.StatusText(status))
+}
+
+// newID returns a random UUID (version 4).
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// newURLID returns a random url id like the ones used in document urls.
+func newURLID() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, 10)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
+
+// now returns the current time with the precision the real server uses.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
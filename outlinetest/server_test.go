@@ -0,0 +1,349 @@
+package outlinetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+func TestServer_collections(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col, err := cl.Collections().Create("Runbooks").Description("How to").PermissionRead().Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Runbooks", col.Name)
+	assert.Equal(t, "read", col.Permission)
+
+	got, err := cl.Collections().Get(col.ID).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, col, got)
+
+	updated, err := cl.Collections().Update(col.ID).Name("Playbooks").Color("#123123").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Playbooks", updated.Name)
+	assert.Equal(t, "How to", updated.Description)
+
+	stored, ok := srv.Collection(col.ID)
+	require.True(t, ok)
+	assert.Equal(t, "#123123", stored.Color)
+
+	// Properties which are not set are left as is.
+	updated, err = cl.Collections().Update(col.ID).ClearColor().Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Playbooks", updated.Name)
+	assert.Empty(t, updated.Color)
+
+	_, err = cl.Collections().Get("unknown").Do(ctx)
+	require.Error(t, err)
+	assert.False(t, outline.IsTemporary(err))
+}
+
+func TestServer_collectionsListPagination(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	srv.SetPageLimit(2)
+
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		srv.AddCollection(outline.Collection{Name: name})
+	}
+
+	var names []string
+	err := srv.OutlineClient().Collections().List().Do(
+		context.Background(),
+		func(col *outline.Collection, err error) (bool, error) {
+			require.NoError(t, err)
+			names = append(names, col.Name)
+			return true, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B", "C", "D", "E"}, names)
+	assert.Equal(t, 3, srv.RequestCount("collections.list"))
+}
+
+func TestServer_documents(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+
+	parent, err := cl.Documents().Create("DB", col.ID).Publish(true).Do(ctx)
+	require.NoError(t, err)
+	child, err := cl.Documents().Create("Failover", col.ID).ParentDocumentID(parent.ID).Publish(true).Do(ctx)
+	require.NoError(t, err)
+	_, err = cl.Documents().Create("Draft", col.ID).Do(ctx)
+	require.NoError(t, err)
+
+	// Drafts are not part of the structure.
+	st, err := cl.Collections().DocumentStructure(col.ID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Equal(t, parent.ID, st[0].ID)
+	require.Len(t, st[0].Children, 1)
+	assert.Equal(t, child.ID, st[0].Children[0].ID)
+	assert.Equal(t, "/doc/failover-"+child.URLID, st[0].Children[0].URL)
+
+	updated, err := cl.Documents().Update(child.ID).Text("See ").Do(ctx)
+	require.NoError(t, err)
+	updated, err = cl.Documents().Update(child.ID).Text(st[0].URL).Append(true).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "See "+st[0].URL, updated.Text)
+	assert.Equal(t, 3, updated.Revision)
+
+	// Documents can be retrieved by url id and share id as well.
+	got, err := cl.Documents().Get().ByID(outline.DocumentID(child.URLID)).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, got.ID)
+	got, err = cl.Documents().Get().ByShareID(srv.ShareDocument(child.ID)).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, got.ID)
+
+	// The child links to the parent hence is related to it.
+	var related []outline.DocumentID
+	err = cl.Documents().Related(parent.ID).Do(ctx, func(d *outline.Document, err error) (bool, error) {
+		require.NoError(t, err)
+		related = append(related, d.ID)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []outline.DocumentID{child.ID}, related)
+
+	_, err = cl.Documents().Create("Orphan", "unknown").Do(ctx)
+	require.Error(t, err)
+
+	// Deleted documents are gone from the structure, permanently deleted ones from the server.
+	require.NoError(t, cl.Documents().Delete(child.ID).Do(ctx))
+	st, err = cl.Collections().DocumentStructure(col.ID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Empty(t, st[0].Children)
+	require.NoError(t, cl.Documents().Delete(child.ID).Permanent(true).Do(ctx))
+	_, ok := srv.Document(child.ID)
+	assert.False(t, ok)
+}
+
+func TestServer_attachments(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+
+	att, err := srv.OutlineClient().Attachments().Create("diagram.png", "image/png", 3).DocumentID(doc.ID).Do(ctx)
+	require.NoError(t, err)
+
+	// Upload the content the same way a browser would.
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	for k, v := range att.Form {
+		require.NoError(t, mw.WriteField(k, v.(string)))
+	}
+	fw, err := mw.CreateFormFile("file", "diagram.png")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("png"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req, err := http.NewRequest(http.MethodPost, att.UploadURL, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+testApiKey)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	data, ok := srv.Attachment(att.AttachmentData.URL)
+	require.True(t, ok)
+	assert.Equal(t, []byte("png"), data)
+}
+
+func TestServer_memberships(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+	jane := srv.AddUser(outline.User{Name: "Jane Doe", Email: "jane@example.com"})
+	srv.AddUser(outline.User{Name: "John Doe", Email: "john@example.com"})
+
+	users := []string{}
+	err := cl.Documents().Users(doc.ID).Query("jane").Do(ctx, func(u *outline.User, err error) (bool, error) {
+		require.NoError(t, err)
+		users = append(users, u.Email)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jane@example.com"}, users)
+
+	m, err := cl.Documents().AddUser(doc.ID, outline.UserID(jane.ID), outline.PermissionRead).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, outline.PermissionRead, m.Permission)
+	require.NotNil(t, m.User)
+	assert.Equal(t, "Jane Doe", m.User.Name)
+
+	memberships := []outline.DocumentMembership{}
+	err = cl.Documents().Memberships(doc.ID).Do(ctx, func(m *outline.DocumentMembership, err error) (bool, error) {
+		require.NoError(t, err)
+		memberships = append(memberships, *m)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, outline.UserID(jane.ID), memberships[0].UserID)
+	assert.Equal(t, "jane@example.com", memberships[0].User.Email)
+
+	require.NoError(t, cl.Documents().RemoveUser(doc.ID, outline.UserID(jane.ID)).Do(ctx))
+	assert.Empty(t, srv.Memberships(doc.ID))
+}
+
+func TestServer_collectionMemberships(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+	jane := srv.AddUser(outline.User{Name: "Jane Doe", Email: "jane@example.com"})
+	srv.AddUser(outline.User{Name: "John Doe", Email: "john@example.com"})
+	ops := srv.AddCollectionGroup(col.ID, outline.Group{Name: "Ops"}, outline.PermissionRead)
+
+	users := []string{}
+	err := cl.Users().List().Query("jane@").Do(ctx, func(u *outline.User, err error) (bool, error) {
+		require.NoError(t, err)
+		users = append(users, u.Name)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Jane Doe"}, users)
+
+	m, err := cl.Collections().AddUser(col.ID, outline.UserID(jane.ID), outline.PermissionRead).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, col.ID, m.CollectionID)
+	require.NotNil(t, m.User)
+	assert.Equal(t, "Jane Doe", m.User.Name)
+
+	memberships := []outline.CollectionMembership{}
+	err = cl.Collections().Memberships(col.ID).Do(ctx, func(m *outline.CollectionMembership, err error) (bool, error) {
+		require.NoError(t, err)
+		memberships = append(memberships, *m)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, "jane@example.com", memberships[0].User.Email)
+	assert.Equal(t, srv.CollectionMemberships(col.ID), []outline.CollectionMembership{{
+		ID: m.ID, CollectionID: col.ID, UserID: outline.UserID(jane.ID), Permission: outline.PermissionRead,
+	}})
+
+	groups := []outline.CollectionGroupMembership{}
+	err = cl.Collections().GroupMemberships(col.ID).Do(ctx,
+		func(m *outline.CollectionGroupMembership, err error) (bool, error) {
+			require.NoError(t, err)
+			groups = append(groups, *m)
+			return true, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, ops, groups[0].Group)
+}
+
+func TestServer_templates(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB"})
+	tpl := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Incident", Template: true})
+
+	list := func(template bool) []outline.DocumentID {
+		ids := []outline.DocumentID{}
+		err := cl.Documents().GetAll().Collection(col.ID).Template(template).
+			Do(ctx, func(d *outline.Document, err error) bool {
+				require.NoError(t, err)
+				ids = append(ids, d.ID)
+				return true
+			})
+		require.NoError(t, err)
+		return ids
+	}
+	assert.Equal(t, []outline.DocumentID{doc.ID}, list(false))
+	assert.Equal(t, []outline.DocumentID{tpl.ID}, list(true))
+
+	// Templates are never part of the structure.
+	_, err := cl.Documents().Create("Postmortem", col.ID).Template(true).Publish(true).Do(ctx)
+	require.NoError(t, err)
+	st, err := cl.Collections().DocumentStructure(col.ID).Do(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Equal(t, doc.ID, st[0].ID)
+}
+
+func TestServer_unauthorized(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+
+	cl := outline.New(srv.URL, srv.Client(), "wrong key")
+	_, err := cl.Collections().Get("id").Do(context.Background())
+	require.Error(t, err)
+	assert.False(t, outline.IsTemporary(err))
+	assert.Contains(t, err.Error(), "authentication_required")
+}
+
+func TestServer_faults(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Runbooks"})
+
+	srv.InjectFault(outlinetest.Fault{Endpoint: "collections.info", Status: http.StatusServiceUnavailable, Times: 1})
+	srv.InjectFault(outlinetest.Fault{Endpoint: "collections.info", Status: http.StatusTooManyRequests, Times: 1})
+
+	_, err := cl.Collections().Get(col.ID).Do(ctx)
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+
+	_, err = cl.Collections().Get(col.ID).Do(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate_limit_exceeded")
+
+	// Faults are used up now.
+	_, err = cl.Collections().Get(col.ID).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, srv.RequestCount("collections.info"))
+
+	srv.InjectFault(outlinetest.Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = cl.Collections().Get(col.ID).Do(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	srv.ClearFaults()
+	_, err = cl.Collections().Get(col.ID).Do(context.Background())
+	require.NoError(t, err)
+}
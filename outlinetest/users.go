@@ -0,0 +1,216 @@
+package outlinetest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// AddUser adds a copy of u to the workspace and returns the stored user. Missing ID and creation time are filled in.
+// The user on whose behalf requests are made, [Server.User], is part of the workspace from the start.
+func (s *Server) AddUser(u outline.User) *outline.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = newID()
+	}
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = now()
+	}
+
+	s.users = append(s.users, &u)
+	c := u
+	return &c
+}
+
+// Memberships returns the direct memberships of the document identified by id.
+func (s *Server) Memberships(id outline.DocumentID) []outline.DocumentMembership {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	memberships := []outline.DocumentMembership{}
+	for _, m := range s.memberships {
+		if m.DocumentID == id {
+			memberships = append(memberships, *m)
+		}
+	}
+	return memberships
+}
+
+// findUser returns the user identified by id.
+func (s *Server) findUser(id outline.UserID) *outline.User {
+	if outline.UserID(s.User.ID) == id {
+		return &s.User
+	}
+	for _, u := range s.users {
+		if outline.UserID(u.ID) == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// documentsUsers lists all users of the workspace as all of them have access to every document of the fake.
+func (s *Server) documentsUsers(req *request) {
+	params := struct {
+		ID    outline.DocumentID `json:"id"`
+		Query string             `json:"query"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	if doc := s.findDocument(params.ID); doc == nil || isGone(doc) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	users := []*outline.User{}
+	for _, u := range append([]*outline.User{&s.User}, s.users...) {
+		if strings.Contains(strings.ToLower(u.Name), strings.ToLower(params.Query)) {
+			users = append(users, u)
+		}
+	}
+
+	writeList(req, s.pageLimit, users)
+}
+
+// usersList lists the users of the workspace whose name or email contains the query.
+func (s *Server) usersList(req *request) {
+	params := struct {
+		Query string `json:"query"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	users := []*outline.User{}
+	for _, u := range append([]*outline.User{&s.User}, s.users...) {
+		q := strings.ToLower(params.Query)
+		if strings.Contains(strings.ToLower(u.Name), q) || strings.Contains(strings.ToLower(u.Email), q) {
+			users = append(users, u)
+		}
+	}
+
+	writeList(req, s.pageLimit, users)
+}
+
+func (s *Server) documentsMemberships(req *request) {
+	params := struct {
+		ID         outline.DocumentID `json:"id"`
+		Query      string             `json:"query"`
+		Permission outline.Permission `json:"permission"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || isGone(doc) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+
+	memberships := []*outline.DocumentMembership{}
+	for _, m := range s.memberships {
+		u := s.findUser(m.UserID)
+		if m.DocumentID != doc.ID || (params.Permission != "" && m.Permission != params.Permission) ||
+			!strings.Contains(strings.ToLower(u.Name), strings.ToLower(params.Query)) {
+			continue
+		}
+		memberships = append(memberships, m)
+	}
+
+	// Users are returned separately from the memberships hence the page is cut here instead of using writeList.
+	offset, limit := req.page(s.pageLimit)
+	start, end := min(offset, len(memberships)), min(offset+limit, len(memberships))
+	users := []*outline.User{}
+	for _, m := range memberships[start:end] {
+		users = append(users, s.findUser(m.UserID))
+	}
+
+	writeJSON(req.w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"users":       users,
+			"memberships": memberships[start:end],
+		},
+		"pagination": map[string]any{"offset": offset, "limit": limit},
+		"policies":   []any{},
+	})
+}
+
+func (s *Server) documentsAddUser(req *request) {
+	params := struct {
+		ID         outline.DocumentID `json:"id"`
+		UserID     outline.UserID     `json:"userId"`
+		Permission outline.Permission `json:"permission"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil || isGone(doc) {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+	u := s.findUser(params.UserID)
+	if u == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "User not found")
+		return
+	}
+	if params.Permission == "" {
+		params.Permission = outline.PermissionReadWrite
+	}
+	if !validPermission(string(params.Permission)) {
+		writeError(req.w, http.StatusBadRequest, "validation_error", "permission: Invalid enum value")
+		return
+	}
+
+	var membership *outline.DocumentMembership
+	for _, m := range s.memberships {
+		if m.DocumentID == doc.ID && m.UserID == outline.UserID(u.ID) {
+			membership = m
+		}
+	}
+	if membership == nil {
+		membership = &outline.DocumentMembership{
+			ID:         outline.MembershipID(newID()),
+			DocumentID: doc.ID,
+			UserID:     outline.UserID(u.ID),
+		}
+		s.memberships = append(s.memberships, membership)
+	}
+	membership.Permission = params.Permission
+
+	req.writeData(map[string]any{
+		"users":       []*outline.User{u},
+		"memberships": []*outline.DocumentMembership{membership},
+	})
+}
+
+func (s *Server) documentsRemoveUser(req *request) {
+	params := struct {
+		ID     outline.DocumentID `json:"id"`
+		UserID outline.UserID     `json:"userId"`
+	}{}
+	if !req.decode(&params) {
+		return
+	}
+
+	doc := s.findDocument(params.ID)
+	if doc == nil {
+		writeError(req.w, http.StatusNotFound, "not_found", "Document not found")
+		return
+	}
+	for i, m := range s.memberships {
+		if m.DocumentID == doc.ID && m.UserID == params.UserID {
+			s.memberships = append(s.memberships[:i:i], s.memberships[i+1:]...)
+			req.writeSuccess()
+			return
+		}
+	}
+	writeError(req.w, http.StatusNotFound, "not_found", "Membership not found")
+}
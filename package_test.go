@@ -1,20 +1,26 @@
 package outline_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ioki-mobility/go-outline"
 	"github.com/ioki-mobility/go-outline/internal/common"
 	"github.com/ioki-mobility/go-outline/internal/testutils"
+	"github.com/ioki-mobility/go-outline/outlinetest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -768,6 +774,2036 @@ func TestAttachmentsClientCreate_failed(t *testing.T) {
 	}
 }
 
+func TestAttachmentsClientDownload(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.AttachmentsRedirectEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u+"?id=e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4", r.URL.String())
+		testAssertHeaders(t, r.Header)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{"image/png"}},
+			Body:          io.NopCloser(strings.NewReader("png data")),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	buf := &bytes.Buffer{}
+	contentType, err := cl.Attachments().Download("e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4").Do(context.Background(), buf)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, "png data", buf.String())
+}
+
+func TestAttachmentsClientDownload_failed(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusNotFound,
+			Body:          io.NopCloser(strings.NewReader("not found")),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	buf := &bytes.Buffer{}
+	_, err := cl.Attachments().Download("unknown").Do(context.Background(), buf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+	assert.True(t, outline.IsNotFound(err))
+	assert.False(t, outline.IsForbidden(err))
+	assert.False(t, outline.IsNotFound(errors.New("not found")))
+	assert.Zero(t, buf.Len())
+}
+
+func TestAttachmentsClientRedirect(t *testing.T) {
+	storageURL := "https://storage.example.com/bucket/diagram.png?signature=abc"
+	storageRequests := 0
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "storage.example.com" {
+			storageRequests++
+			return &http.Response{
+				Request:       r,
+				ContentLength: -1,
+				StatusCode:    http.StatusOK,
+				Body:          io.NopCloser(strings.NewReader("png data")),
+			}, nil
+		}
+
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.AttachmentsRedirectEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u+"?id=e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4", r.URL.String())
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusFound,
+			Header:        http.Header{"Location": []string{storageURL}},
+			Body:          io.NopCloser(strings.NewReader("")),
+		}, nil
+	}}
+
+	// The redirect is not followed, with and without middlewares.
+	ctx := context.Background()
+	for _, cl := range []*outline.Client{
+		outline.New(testServerURL, hc, testApiKey),
+		outline.New(testServerURL, hc, testApiKey, outline.WithRateLimit(10, 1)),
+	} {
+		got, err := cl.Attachments().Redirect("e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4").Do(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, storageURL, got)
+		assert.Zero(t, storageRequests)
+
+		// Downloads still follow it.
+		buf := &bytes.Buffer{}
+		_, err = cl.Attachments().Download("e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4").Do(ctx, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "png data", buf.String())
+		storageRequests = 0
+	}
+	assert.Nil(t, hc.CheckRedirect)
+}
+
+func TestAttachmentsClientRedirect_failed(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusNotFound,
+			Body:          io.NopCloser(strings.NewReader("not found")),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	_, err := cl.Attachments().Redirect("unknown").Do(context.Background())
+	require.Error(t, err)
+	assert.True(t, outline.IsNotFound(err))
+}
+
+func TestAttachmentsClientUpload(t *testing.T) {
+	// testAssertUpload asserts that r is the upload of diagram.png.
+	testAssertUpload := func(t *testing.T, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "uploads/diagram.png", r.FormValue("key"))
+		assert.Equal(t, "private", r.FormValue("acl"))
+		f, fh, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, "diagram.png", fh.Filename)
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "png", string(data))
+	}
+
+	tests := map[string]struct {
+		uploadURL string
+		// endpoints are the ones seen by middlewares.
+		endpoints []string
+	}{
+		"storage": {
+			uploadURL: "https://storage.example.com/bucket",
+			endpoints: []string{common.AttachmentsCreateEndpoint()},
+		},
+		"server": {
+			uploadURL: "/api/files.create",
+			endpoints: []string{common.AttachmentsCreateEndpoint(), "files.create"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			serverUploads, storageUploads := 0, 0
+			hc := &http.Client{}
+			// Uploads to the storage are made with the given client as well e.g. to use the same proxy.
+			hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+				if r.URL.Host == "storage.example.com" {
+					storageUploads++
+					assert.Equal(t, "/bucket", r.URL.Path)
+					assert.Empty(t, r.Header.Get(common.HdrKeyAuthorization))
+					testAssertUpload(t, r)
+					return &http.Response{
+						Request:       r,
+						ContentLength: 0,
+						StatusCode:    http.StatusNoContent,
+						Body:          io.NopCloser(strings.NewReader("")),
+					}, nil
+				}
+				if strings.HasSuffix(r.URL.Path, common.AttachmentsCreateEndpoint()) {
+					testAssertHeaders(t, r.Header)
+					testAssertBody(t, r, `{"name":"diagram.png", "contentType":"image/png", "size":3, "documentId":"doc"}`)
+					body := fmt.Sprintf(
+						`{"data": {"uploadUrl": "%s", "form": {"key": "uploads/diagram.png", "acl": "private"}, `+
+							`"attachment": {"id": "att", "url": "/api/attachments.redirect?id=att"}}}`,
+						test.uploadURL,
+					)
+					return &http.Response{
+						Request:       r,
+						ContentLength: -1,
+						StatusCode:    http.StatusOK,
+						Body:          io.NopCloser(strings.NewReader(body)),
+					}, nil
+				}
+
+				serverUploads++
+				assert.Equal(t, testServerURL+"/api/files.create", r.URL.String())
+				assert.NotEmpty(t, r.Header.Get(common.HdrKeyAuthorization))
+				testAssertUpload(t, r)
+
+				return &http.Response{
+					Request:       r,
+					ContentLength: 0,
+					StatusCode:    http.StatusNoContent,
+					Body:          io.NopCloser(strings.NewReader("")),
+				}, nil
+			}}
+
+			endpoints := []string{}
+			mw := func(next outline.Invoker) outline.Invoker {
+				return func(call *outline.Call) *outline.CallResult {
+					endpoints = append(endpoints, call.Endpoint)
+					return next(call)
+				}
+			}
+
+			cl := outline.New(testServerURL, hc, testApiKey, outline.WithMiddleware(mw))
+			got, err := cl.Attachments().Upload("diagram.png", "image/png", []byte("png")).DocumentID("doc").
+				Do(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, serverUploads+storageUploads)
+			assert.Equal(t, test.endpoints, endpoints)
+			assert.Equal(t, outline.AttachmentID("att"), got.ID)
+			assert.Equal(t, "/api/attachments.redirect?id=att", got.URL)
+		})
+	}
+}
+
+func TestAttachmentsClientUpload_failed(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "s3.ioki.com" {
+			return &http.Response{
+				Request:       r,
+				ContentLength: -1,
+				StatusCode:    http.StatusForbidden,
+				Body:          io.NopCloser(strings.NewReader("<Error><Code>AccessDenied</Code></Error>")),
+			}, nil
+		}
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleAttachmentsCreateResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	_, err := cl.Attachments().Upload("diagram.png", "image/png", []byte("png")).Do(context.Background())
+	require.Error(t, err)
+	assert.True(t, outline.IsForbidden(err))
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func TestDocumentsClientMove(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsMoveEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "collectionId":"col2", "parentDocumentId":"doc2", "index":0}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"data": {"documents": [{"id": "doc1", "collectionId": "col2", "parentDocumentId": "doc2"}]}}`,
+			)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	docs, err := cl.Documents().Move("doc1").CollectionID("col2").ParentDocumentID("doc2").Index(0).
+		Do(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, outline.DocumentID("doc2"), docs[0].ParentDocumentID)
+}
+
+func TestDocumentsClientArchive(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsArchiveEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {"id": "doc1", "archivedAt": "2024-05-01T10:00:00Z"}}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	doc, err := cl.Documents().Archive("doc1").Do(context.Background())
+	require.NoError(t, err)
+	assert.False(t, doc.ArchivedAt.IsZero())
+}
+
+func TestDocumentsClientViewed(t *testing.T) {
+	requestCount := atomic.Uint32{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		requestCount.Add(1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		testAssertHeaders(t, r.Header)
+
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsViewedEndpoint())
+		require.NoError(t, err)
+
+		body := exampleDocumentsListResponse_2documents
+		if requestCount.Load() == 1 {
+			assert.Equal(t, u, r.URL.String())
+		} else {
+			// Second page is asked with an offset equal to number of items in first page.
+			assert.Equal(t, u+"?offset=2", r.URL.String())
+			body = `{"data": [], "pagination": {"offset": 2, "limit": 25}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var ids []outline.DocumentID
+	err := cl.Documents().Viewed().Do(context.Background(), func(d *outline.Document, err error) (bool, error) {
+		require.NoError(t, err)
+		ids = append(ids, d.ID)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []outline.DocumentID{"doc1", "doc2"}, ids)
+	assert.Equal(t, uint32(2), requestCount.Load())
+}
+
+func TestViewsClientList(t *testing.T) {
+	testResponse := exampleViewsListResponse
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.ViewsListEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"documentId":"doc1", "includeSuspended":true}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(testResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	got, err := cl.Views().List("doc1").IncludeSuspended(true).Do(context.Background())
+	require.NoError(t, err)
+
+	// Manually unmarshal test response and see if we get same object via the API.
+	expected := &struct {
+		Data []outline.View `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(testResponse), expected))
+	assert.Equal(t, expected.Data, got)
+	require.Len(t, got, 1)
+	assert.Equal(t, 12, got[0].Count)
+	assert.Equal(t, "Jane Doe", got[0].User.Name)
+}
+
+func TestViewsClientList_failed(t *testing.T) {
+	tests := map[string]struct {
+		isTemporary bool
+		rt          http.RoundTripper
+	}{
+		"HTTP request failed": {
+			isTemporary: false,
+			rt: &testutils.MockRoundTripper{
+				RoundTripFn: func(r *http.Request) (*http.Response, error) {
+					return nil, &net.DNSError{}
+				},
+			},
+		},
+		"server side error": {
+			isTemporary: true,
+			rt: &testutils.MockRoundTripper{
+				RoundTripFn: func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						Request:       r,
+						StatusCode:    http.StatusServiceUnavailable,
+						ContentLength: -1,
+						Body:          io.NopCloser(strings.NewReader("service unavailable")),
+					}, nil
+				},
+			},
+		},
+		"client side error": {
+			isTemporary: false,
+			rt: &testutils.MockRoundTripper{
+				RoundTripFn: func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						Request:       r,
+						ContentLength: -1,
+						StatusCode:    http.StatusUnauthorized,
+						Body:          io.NopCloser(strings.NewReader("unauthorized key")),
+					}, nil
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hc := &http.Client{}
+			hc.Transport = test.rt
+			cl := outline.New(testServerURL, hc, testApiKey)
+			views, err := cl.Views().List("doc1").Do(context.Background())
+			assert.Nil(t, views)
+			require.NotNil(t, err)
+			assert.Equal(t, test.isTemporary, outline.IsTemporary(err))
+		})
+	}
+}
+
+func TestViewsClientCreate(t *testing.T) {
+	testResponse := exampleViewsCreateResponse
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.ViewsCreateEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"documentId":"doc1"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(testResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	got, err := cl.Views().Create("doc1").Do(context.Background())
+	require.NoError(t, err)
+
+	// Manually unmarshal test response and see if we get same object via the API.
+	expected := &struct {
+		Data outline.View `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(testResponse), expected))
+	assert.Equal(t, &expected.Data, got)
+}
+
+func TestDocumentsClientAddUser(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsAddUserEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "userId":"user1", "permission":"read"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleDocumentsMembershipsResponse_1membership)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	got, err := cl.Documents().AddUser("doc1", "user1", outline.PermissionRead).Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, outline.MembershipID("membership1"), got.ID)
+	assert.Equal(t, outline.PermissionRead, got.Permission)
+	require.NotNil(t, got.User)
+	assert.Equal(t, "Jane Doe", got.User.Name)
+}
+
+func TestDocumentsClientRemoveUser(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsRemoveUserEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "userId":"user1"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"success": true}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	err := cl.Documents().RemoveUser("doc1", "user1").Do(context.Background())
+	require.NoError(t, err)
+}
+
+func TestDocumentsClientMemberships(t *testing.T) {
+	requestCount := atomic.Uint32{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		requestCount.Add(1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "permission":"read_write"}`)
+
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsMembershipsEndpoint())
+		require.NoError(t, err)
+
+		body := exampleDocumentsMembershipsResponse_2memberships
+		if requestCount.Load() == 1 {
+			assert.Equal(t, u, r.URL.String())
+		} else {
+			assert.Equal(t, u+"?offset=2", r.URL.String())
+			body = `{"data": {"users": [], "memberships": []}, "pagination": {"offset": 2, "limit": 25}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []outline.DocumentMembership
+	err := cl.Documents().Memberships("doc1").Permission(outline.PermissionReadWrite).Do(
+		context.Background(),
+		func(m *outline.DocumentMembership, err error) (bool, error) {
+			require.NoError(t, err)
+			got = append(got, *m)
+			return true, nil
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "user1", got[0].User.ID)
+	assert.Equal(t, "user2", got[1].User.ID)
+	assert.Equal(t, uint32(2), requestCount.Load())
+}
+
+func TestCollectionsClientMemberships(t *testing.T) {
+	requestCount := atomic.Uint32{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		requestCount.Add(1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"col1", "query":"doe"}`)
+
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.CollectionsMembershipsEndpoint())
+		require.NoError(t, err)
+
+		body := `{"data": {` +
+			`"users": [{"id": "user1", "name": "Jane Doe"}, {"id": "user2", "name": "John Doe"}], ` +
+			`"memberships": [` +
+			`{"id": "m1", "userId": "user1", "collectionId": "col1", "permission": "read_write"}, ` +
+			`{"id": "m2", "userId": "user2", "collectionId": "col1", "permission": "read"}` +
+			`]}, "pagination": {"offset": 0, "limit": 25}}`
+		if requestCount.Load() == 1 {
+			assert.Equal(t, u, r.URL.String())
+		} else {
+			assert.Equal(t, u+"?offset=2", r.URL.String())
+			body = `{"data": {"users": [], "memberships": []}, "pagination": {"offset": 2, "limit": 25}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []outline.CollectionMembership
+	err := cl.Collections().Memberships("col1").Query("doe").Do(
+		context.Background(),
+		func(m *outline.CollectionMembership, err error) (bool, error) {
+			require.NoError(t, err)
+			got = append(got, *m)
+			return true, nil
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "Jane Doe", got[0].User.Name)
+	assert.Equal(t, outline.PermissionReadWrite, got[0].Permission)
+	assert.Equal(t, "John Doe", got[1].User.Name)
+	assert.Equal(t, uint32(2), requestCount.Load())
+}
+
+func TestCollectionsClientGroupMemberships(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.CollectionsGroupMembershipsEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+		testAssertBody(t, r, `{"id":"col1"}`)
+
+		body := `{"data": {"groups": [{"id": "g1", "name": "Ops", "memberCount": 3}], ` +
+			`"groupMemberships": [{"id": "m1", "groupId": "g1", "collectionId": "col1", "permission": "read"}]}}`
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []outline.CollectionGroupMembership
+	err := cl.Collections().GroupMemberships("col1").Do(
+		context.Background(),
+		func(m *outline.CollectionGroupMembership, err error) (bool, error) {
+			require.NoError(t, err)
+			got = append(got, *m)
+			return true, nil
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, outline.GroupID("g1"), got[0].GroupID)
+	assert.Equal(t, outline.PermissionRead, got[0].Permission)
+	assert.Equal(t, &outline.Group{ID: "g1", Name: "Ops", MemberCount: 3}, got[0].Group)
+}
+
+func TestUsersClientList(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.UsersListEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"query":"jane@example.com"}`)
+
+		return &http.Response{
+			Request:       r,
+			StatusCode:    http.StatusOK,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(`{"data": [{"id": "user1", "email": "jane@example.com"}]}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []string
+	ctx := context.Background()
+	err := cl.Users().List().Query("jane@example.com").Do(ctx, func(u *outline.User, err error) (bool, error) {
+		require.NoError(t, err)
+		got = append(got, u.ID)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user1"}, got)
+}
+
+func TestDocumentsClientUsers(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsUsersEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "query":"jane"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"data": [{"id": "user1", "name": "Jane Doe"}], "pagination": {"offset": 0, "limit": 25}}`,
+			)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []string
+	err := cl.Documents().Users("doc1").Query("jane").Do(
+		context.Background(),
+		func(u *outline.User, err error) (bool, error) {
+			require.NoError(t, err)
+			got = append(got, u.ID)
+			return true, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user1"}, got)
+}
+
+func TestDocumentsClientAnswerQuestion(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsAnswerQuestionEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"query":"how do I rotate the DB password?", "collectionId":"collection id"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleDocumentsAnswerQuestionResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	got, err := cl.Documents().AnswerQuestion("how do I rotate the DB password?").
+		Collection("collection id").
+		Do(context.Background())
+	require.NoError(t, err)
+
+	expected := &outline.DocumentsAnswer{
+		Query:  "how do I rotate the DB password?",
+		Answer: "Run the rotation job described in the runbook.",
+		Sources: []outline.DocumentSummary{
+			{ID: "doc1", Title: "DB Runbook", URL: "/doc/db-runbook-hDYep1TPAM"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestDocumentsClientRelated(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsListEndpoint())
+		require.NoError(t, err)
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"backlinkDocumentId":"doc3"}`)
+
+		body := exampleDocumentsListResponse_2documents
+		if r.URL.String() != u {
+			body = `{"data": [], "pagination": {"offset": 2, "limit": 25}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var ids []outline.DocumentID
+	err := cl.Documents().Related("doc3").Do(context.Background(), func(d *outline.Document, err error) (bool, error) {
+		require.NoError(t, err)
+		ids = append(ids, d.ID)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []outline.DocumentID{"doc1", "doc2"}, ids)
+}
+
+func TestDocumentsClientGetAll(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsListEndpoint())
+		require.NoError(t, err)
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"collectionId":"col", "parentDocumentId":"doc3", "template":true}`)
+
+		body := exampleDocumentsListResponse_2documents
+		if r.URL.String() != u {
+			body = `{"data": [], "pagination": {"offset": 2, "limit": 25}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var ids []outline.DocumentID
+	err := cl.Documents().GetAll().Collection("col").Parent("doc3").Template(true).
+		Do(context.Background(), func(d *outline.Document, err error) bool {
+			require.NoError(t, err)
+			ids = append(ids, d.ID)
+			return true
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []outline.DocumentID{"doc1", "doc2"}, ids)
+}
+
+func TestDocumentsClientGetAll_pages(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	srv.SetPageLimit(2)
+	col := srv.AddCollection(outline.Collection{Name: "Acme"})
+	other := srv.AddCollection(outline.Collection{Name: "Other"})
+	for _, title := range []string{"One", "Two", "Three", "Four", "Five"} {
+		srv.AddDocument(outline.Document{CollectionID: col.ID, Title: title})
+	}
+	srv.AddDocument(outline.Document{CollectionID: other.ID, Title: "Elsewhere"})
+	srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Template", Template: true})
+
+	titles := func(cl *outline.DocumentsClientGetAll) []string {
+		got := []string{}
+		err := cl.Do(context.Background(), func(d *outline.Document, err error) bool {
+			require.NoError(t, err)
+			got = append(got, d.Title)
+			return true
+		})
+		require.NoError(t, err)
+		return got
+	}
+
+	all := srv.OutlineClient().Documents().GetAll().Collection(col.ID)
+	assert.ElementsMatch(t, []string{"One", "Two", "Three", "Four", "Five"}, titles(all))
+	assert.Equal(t, []string{"Template"}, titles(all.Template(true)))
+}
+
+func TestDocumentsClientGetAll_error(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	srv.InjectFault(outlinetest.Fault{Endpoint: common.DocumentsListEndpoint(), Status: http.StatusBadGateway})
+
+	// The error given to fn last is returned once fn aborts.
+	calls := 0
+	err := srv.OutlineClient().Documents().GetAll().Do(context.Background(), func(d *outline.Document, err error) bool {
+		calls++
+		assert.Nil(t, d)
+		assert.Error(t, err)
+		return false
+	})
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestAPIKeysClientCreate(t *testing.T) {
+	testResponse := exampleAPIKeysCreateResponse
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.APIKeysCreateEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(
+			t,
+			r,
+			`{"name":"sync job", "expiresAt":"2024-01-01T00:00:00Z", "scope":["documents.info", "collections.*"]}`,
+		)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(testResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	got, err := cl.APIKeys().
+		Create("sync job", expiresAt, []string{"documents.info", "collections.*"}).
+		Do(context.Background())
+	require.NoError(t, err)
+
+	// Manually unmarshal test response and see if we get same object via the API.
+	expected := &struct {
+		Data outline.APIKey `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(testResponse), expected))
+	assert.Equal(t, &expected.Data, got)
+	assert.Equal(t, "ol_api_secret", got.Secret)
+}
+
+func TestAPIKeysClientCreate_noExpiry(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		testAssertBody(t, r, `{"name":"forever"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleAPIKeysCreateResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	_, err := cl.APIKeys().Create("forever", time.Time{}, nil).Do(context.Background())
+	require.NoError(t, err)
+}
+
+func TestAPIKeysClientList(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.APIKeysListEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+		testAssertHeaders(t, r.Header)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"data": [{"id": "key1", "name": "sync job", "last4": "cret"}], "pagination": {"offset": 0, "limit": 25}}`,
+			)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+
+	var got []outline.APIKeyID
+	err := cl.APIKeys().List().Do(context.Background(), func(k *outline.APIKey, err error) (bool, error) {
+		require.NoError(t, err)
+		got = append(got, k.ID)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []outline.APIKeyID{"key1"}, got)
+}
+
+func TestAPIKeysClientRevoke(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.APIKeysRevokeEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"key1"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"success": true}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	require.NoError(t, cl.APIKeys().Revoke("key1").Do(context.Background()))
+}
+
+func TestOAuthClientsClientCreate(t *testing.T) {
+	testResponse := exampleOAuthClientsCreateResponse
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.OAuthClientsCreateEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(
+			t,
+			r,
+			`{"name":"Portal", "redirectUris":["https://portal.example.com/callback"], "developerName":"Platform"}`,
+		)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(testResponse)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	got, err := cl.OAuthClients().
+		Create("Portal", []string{"https://portal.example.com/callback"}).
+		DeveloperName("Platform").
+		Do(context.Background())
+	require.NoError(t, err)
+
+	// Manually unmarshal test response and see if we get same object via the API.
+	expected := &struct {
+		Data outline.OAuthClient `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(testResponse), expected))
+	assert.Equal(t, &expected.Data, got)
+}
+
+func TestOAuthClientsClientDelete(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.OAuthClientsDeleteEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"oauth client id"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"success": true}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	require.NoError(t, cl.OAuthClients().Delete("oauth client id").Do(context.Background()))
+}
+
+func TestNewWithTokenSource(t *testing.T) {
+	tokens := []string{"token 1", "token 2"}
+	requestCount := atomic.Uint32{}
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		n := requestCount.Add(1)
+		assert.Equal(t, "Bearer "+tokens[n-1], r.Header.Get(common.HdrKeyAuthorization))
+		assert.Equal(t, common.HdrValueAccept, r.Header.Get(common.HdrKeyAccept))
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleCollectionsGetResponse)),
+		}, nil
+	}}
+
+	ts := &testTokenSource{tokens: tokens}
+	cl := outline.NewWithTokenSource(testServerURL, hc, ts)
+
+	// Every request asks the token source for a fresh token.
+	for range tokens {
+		_, err := cl.Collections().Get("collection id").Do(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint32(2), requestCount.Load())
+}
+
+func TestNewWithTokenSource_redirect(t *testing.T) {
+	storageURL := "https://storage.example.com/bucket/diagram.png?signature=abc"
+	var storageAuth []string
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() == storageURL {
+			storageAuth = r.Header.Values(common.HdrKeyAuthorization)
+			return &http.Response{
+				Request:       r,
+				ContentLength: -1,
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": []string{"image/png"}},
+				Body:          io.NopCloser(strings.NewReader("png data")),
+			}, nil
+		}
+
+		assert.Equal(t, "Bearer token", r.Header.Get(common.HdrKeyAuthorization))
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusFound,
+			Header:        http.Header{"Location": []string{storageURL}},
+			Body:          io.NopCloser(strings.NewReader("")),
+		}, nil
+	}}
+
+	ts := &testTokenSource{tokens: []string{"token"}}
+	cl := outline.NewWithTokenSource(testServerURL, hc, ts)
+	buf := &bytes.Buffer{}
+	_, err := cl.Attachments().Download("e7d2b7f4-0fd5-4a0c-9f44-4dbc2ae1e8c4").Do(context.Background(), buf)
+	require.NoError(t, err)
+	assert.Equal(t, "png data", buf.String())
+	// The token is meant for the server only, the storage must not get it.
+	assert.Empty(t, storageAuth)
+	assert.Equal(t, 1, ts.next)
+}
+
+func TestNewWithTokenSource_nilClient(t *testing.T) {
+	assert.NotPanics(t, func() {
+		outline.NewWithTokenSource(testServerURL, nil, &testTokenSource{tokens: []string{"token"}})
+	})
+}
+
+type testTokenSource struct {
+	tokens []string
+	next   int
+}
+
+func (ts *testTokenSource) Token(context.Context) (string, error) {
+	tok := ts.tokens[ts.next]
+	ts.next++
+	return tok, nil
+}
+
+func TestClientWithMiddleware(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// The body must still reach the server after middlewares have seen it.
+		testAssertBody(t, r, `{"id":"collection id"}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(exampleCollectionsGetResponse)),
+		}, nil
+	}}
+
+	var order []string
+	var seen []*outline.CallResult
+	record := func(name string) outline.Middleware {
+		return func(next outline.Invoker) outline.Invoker {
+			return func(call *outline.Call) *outline.CallResult {
+				assert.Equal(t, "collections.info", call.Endpoint)
+				assert.JSONEq(t, `{"id":"collection id"}`, string(call.Body))
+
+				order = append(order, name+" before")
+				res := next(call)
+				order = append(order, name+" after")
+				seen = append(seen, res)
+				return res
+			}
+		}
+	}
+
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithMiddleware(record("first"), record("second")))
+	col, err := cl.Collections().Get("collection id").Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Human Resources", col.Name)
+
+	assert.Equal(t, []string{"first before", "second before", "second after", "first after"}, order)
+	require.Len(t, seen, 2)
+	assert.Equal(t, http.StatusOK, seen[0].Status)
+	assert.NoError(t, seen[0].Err)
+	assert.Greater(t, seen[0].Duration, time.Duration(0))
+}
+
+func TestClientWithMiddleware_shortCircuit(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		t.Fatal("request must not reach the transport")
+		return nil, nil
+	}}
+
+	deny := func(next outline.Invoker) outline.Invoker {
+		return func(call *outline.Call) *outline.CallResult {
+			return &outline.CallResult{Err: errors.New("denied by middleware")}
+		}
+	}
+
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithMiddleware(deny))
+	_, err := cl.Documents().Update("doc1").Do(context.Background())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "denied by middleware")
+}
+
+func TestClientWithMiddleware_nilClient(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+
+	// Just like without options the default client is used.
+	cl := outline.New(srv.URL, nil, testApiKey, outline.WithRateLimit(10, 1))
+	got, err := cl.Collections().Get(col.ID).Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Ops", got.Name)
+}
+
+func TestClientWithRateLimit(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {}}`)),
+		}, nil
+	}}
+
+	// Burst of 2 then 20 calls per second i.e. the 4 calls below need at least 2 * 50ms. All resource level clients
+	// share the same budget.
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithRateLimit(20, 2))
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := cl.Documents().Get().ByID("doc1").Do(context.Background())
+		require.NoError(t, err)
+		_, err = cl.Collections().Get("collection id").Do(context.Background())
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestClientWithRateLimit_serverHeaders(t *testing.T) {
+	requestTimes := []time.Time{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		requestTimes = append(requestTimes, time.Now())
+
+		// First response tells that the budget is exhausted for the next 200ms.
+		header := http.Header{}
+		if len(requestTimes) == 1 {
+			header.Set("RateLimit-Remaining", "0")
+			header.Set("RateLimit-Reset", "0.2")
+		}
+		return &http.Response{
+			Request:       r,
+			Header:        header,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {}}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithRateLimit(0, 0))
+	for i := 0; i < 2; i++ {
+		_, err := cl.Collections().Get("collection id").Do(context.Background())
+		require.NoError(t, err)
+	}
+	require.Len(t, requestTimes, 2)
+	assert.GreaterOrEqual(t, requestTimes[1].Sub(requestTimes[0]), 190*time.Millisecond)
+
+	// Calls waiting for the budget give up once their context is done.
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Request:       r,
+			Header:        http.Header{"Retry-After": []string{"10"}},
+			ContentLength: -1,
+			StatusCode:    http.StatusTooManyRequests,
+			Body:          io.NopCloser(strings.NewReader(`{"ok": false, "error": "rate_limit_exceeded"}`)),
+		}, nil
+	}}
+	cl = outline.New(testServerURL, hc, testApiKey, outline.WithRateLimit(0, 0))
+	_, err := cl.Collections().Get("collection id").Do(context.Background())
+	require.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = cl.Collections().Get("collection id").Do(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestDocumentsClientDelete(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Assert request method and URL.
+		assert.Equal(t, http.MethodPost, r.Method)
+		u, err := url.JoinPath(common.BaseURL(testServerURL), common.DocumentsDeleteEndpoint())
+		require.NoError(t, err)
+		assert.Equal(t, u, r.URL.String())
+
+		testAssertHeaders(t, r.Header)
+		testAssertBody(t, r, `{"id":"doc1", "permanent":true}`)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"success": true}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	require.NoError(t, cl.Documents().Delete("doc1").Permanent(true).Do(context.Background()))
+}
+
+func TestDocumentsClientBatchGet(t *testing.T) {
+	const concurrency = 3
+
+	inFlight := atomic.Int32{}
+	maxInFlight := atomic.Int32{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		params := struct {
+			ID string `json:"id"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		if params.ID == "missing" {
+			return &http.Response{
+				Request:       r,
+				ContentLength: -1,
+				StatusCode:    http.StatusNotFound,
+				Body:          io.NopCloser(strings.NewReader(`{"ok": false, "error": "not_found"}`)),
+			}, nil
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(fmt.Sprintf(`{"data": {"id": "%s"}}`, params.ID))),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	ids := []outline.DocumentID{"doc1", "doc2", "missing", "doc4", "doc5", "doc6", "doc7"}
+	results := cl.Documents().BatchGet(context.Background(), ids, outline.WithConcurrency(concurrency))
+
+	require.Len(t, results, len(ids))
+	for i, res := range results {
+		if ids[i] == "missing" {
+			assert.Nil(t, res.Value)
+			assert.Error(t, res.Err)
+			continue
+		}
+		require.NoError(t, res.Err)
+		assert.Equal(t, ids[i], res.Value.ID)
+	}
+	assert.Equal(t, int32(concurrency), maxInFlight.Load())
+}
+
+func TestDocumentsClientBatchUpdate_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requestCount := atomic.Uint32{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Cancel the whole batch while the first update is in flight.
+		requestCount.Add(1)
+		cancel()
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {"id": "doc1"}}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	updates := []*outline.DocumentsUpdateClient{
+		cl.Documents().Update("doc1").Text("footer"),
+		cl.Documents().Update("doc2").Text("footer"),
+		cl.Documents().Update("doc3").Text("footer"),
+	}
+	results := cl.Documents().BatchUpdate(ctx, updates, outline.WithConcurrency(1))
+
+	require.Len(t, results, 3)
+	assert.Equal(t, uint32(1), requestCount.Load())
+	assert.True(t, errors.Is(results[1].Err, context.Canceled))
+	assert.True(t, errors.Is(results[2].Err, context.Canceled))
+}
+
+func TestClientWithCache(t *testing.T) {
+	requests := map[string]int{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		endpoint := path.Base(r.URL.Path)
+		requests[endpoint]++
+
+		body := `{"data": {"id": "doc1", "collectionId": "col1", "revision": %d}}`
+		switch endpoint {
+		case common.CollectionsStructureEndpoint():
+			body = `{"data": [{"id": "doc1", "title": "Rev %d"}]}`
+		case common.CollectionsGetEndpoint(), common.CollectionsUpdateEndpoint():
+			body = `{"data": {"id": "col1", "name": "Rev %d"}}`
+		case common.DocumentsMoveEndpoint():
+			body = `{"data": {"documents": [{"id": "doc1", "collectionId": "col2", "revision": %d}]}}`
+		case common.CollectionsListEndpoint():
+			body = `{"data": [{"id": "col1", "name": "Rev %d"}]}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(fmt.Sprintf(body, requests[endpoint]))),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithCache(nil, time.Minute))
+	ctx := context.Background()
+
+	// Identical reads are served from the cache.
+	for i := 0; i < 3; i++ {
+		doc, err := cl.Documents().Get().ByID("doc1").Do(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, doc.Revision)
+		st, err := cl.Collections().DocumentStructure("col1").Do(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Rev 1", st[0].Title)
+		col, err := cl.Collections().Get("col1").Do(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Rev 1", col.Name)
+	}
+	assert.Equal(t, 1, requests[common.DocumentsGetEndpoint()])
+	assert.Equal(t, 1, requests[common.CollectionsStructureEndpoint()])
+	assert.Equal(t, 1, requests[common.CollectionsGetEndpoint()])
+
+	// Bypassing the cache hits the server and refreshes the cache.
+	doc, err := cl.Documents().Get().ByID("doc1").Do(outline.NoCache(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.Revision)
+	doc, err = cl.Documents().Get().ByID("doc1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.Revision)
+
+	// Updating a document invalidates the document and its collection's structure but not the collection itself.
+	_, err = cl.Documents().Update("doc1").Text("new").Do(ctx)
+	require.NoError(t, err)
+	doc, err = cl.Documents().Get().ByID("doc1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, doc.Revision)
+	st, err := cl.Collections().DocumentStructure("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 2", st[0].Title)
+	col, err := cl.Collections().Get("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 1", col.Name)
+
+	// Updating a collection invalidates the collection.
+	_, err = cl.Collections().Update("col1").Name("new").Do(ctx)
+	require.NoError(t, err)
+	col, err = cl.Collections().Get("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 2", col.Name)
+
+	// Moving a document invalidates the document and the structures of all collections, including the one it left.
+	_, err = cl.Documents().Move("doc1").CollectionID("col2").Do(ctx)
+	require.NoError(t, err)
+	doc, err = cl.Documents().Get().ByID("doc1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, doc.Revision)
+	st, err = cl.Collections().DocumentStructure("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 3", st[0].Title)
+
+	// Creating a document invalidates the structure of its collection.
+	_, err = cl.Documents().Create("new", "col1").Do(ctx)
+	require.NoError(t, err)
+	st, err = cl.Collections().DocumentStructure("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 4", st[0].Title)
+
+	// Deleting a document invalidates the document and the structures of all collections.
+	require.NoError(t, cl.Documents().Delete("doc1").Do(ctx))
+	doc, err = cl.Documents().Get().ByID("doc1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, doc.Revision)
+	st, err = cl.Collections().DocumentStructure("col1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Rev 5", st[0].Title)
+
+	// Creating a collection invalidates the collections list.
+	firstPage := func(col *outline.Collection, err error) (bool, error) { return false, err }
+	require.NoError(t, cl.Collections().List().Do(ctx, firstPage))
+	_, err = cl.Collections().Create("new").Do(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cl.Collections().List().Do(ctx, firstPage))
+	require.NoError(t, cl.Collections().List().Do(ctx, firstPage))
+	assert.Equal(t, 2, requests[common.CollectionsListEndpoint()])
+}
+
+func TestClientWithCache_tokenSource(t *testing.T) {
+	requests := map[string]int{}
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		token := r.Header.Get(common.HdrKeyAuthorization)
+		requests[token]++
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(fmt.Sprintf(`{"data": {"id": "doc1", "text": %q}}`, token))),
+		}, nil
+	}}
+
+	// The token is not visible to the cache hence clients sharing a cache must not see each others responses.
+	cache := outline.NewMemoryCache(10)
+	ctx := context.Background()
+	for _, token := range []string{"alice", "bob"} {
+		ts := &testTokenSource{tokens: []string{token, token}}
+		cl := outline.NewWithTokenSource(testServerURL, hc, ts, outline.WithCache(cache, time.Minute))
+		for i := 0; i < 2; i++ {
+			doc, err := cl.Documents().Get().ByID("doc1").Do(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, common.HdrValueAuthorization(token), doc.Text)
+		}
+	}
+	assert.Equal(t, map[string]int{
+		common.HdrValueAuthorization("alice"): 1,
+		common.HdrValueAuthorization("bob"):   1,
+	}, requests)
+}
+
+func TestClientWithCache_badResponse(t *testing.T) {
+	requestCount := 0
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		requestCount++
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusNotFound,
+			Body:          io.NopCloser(strings.NewReader(`{"ok": false, "error": "not_found"}`)),
+		}, nil
+	}}
+
+	// Bad responses are never cached.
+	cl := outline.New(testServerURL, hc, testApiKey, outline.WithCache(outline.NewMemoryCache(10), time.Minute))
+	for i := 0; i < 2; i++ {
+		_, err := cl.Collections().Get("col1").Do(context.Background())
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, requestCount)
+}
+
+func TestBuildersConcurrentDo(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Echo the request parameters as document so that every caller can verify it got its own request.
+		params := struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		b, err := json.Marshal(map[string]any{"data": params})
+		require.NoError(t, err)
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(bytes.NewReader(b)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	get := cl.Documents().Get().ByID("doc1")
+	update := cl.Documents().Update("doc1").Title("title")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+
+			doc, err := get.Do(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, outline.DocumentID("doc1"), doc.ID)
+
+			// Deriving a builder leaves the shared one untouched.
+			text := fmt.Sprintf("text %d", i)
+			doc, err = update.Text(text).Do(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "title", doc.Title)
+			assert.Equal(t, text, doc.Text)
+
+			doc, err = update.Do(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "title", doc.Title)
+			assert.Empty(t, doc.Text)
+
+			doc, err = cl.Documents().Get().ByID(outline.DocumentID(text)).Do(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, outline.DocumentID(text), doc.ID)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBuildersConcurrentDo_paginated(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// Two pages with two collections each then an empty page.
+		body := `{"data": [], "pagination": {"offset": 4, "limit": 2}}`
+		switch r.URL.Query().Get("offset") {
+		case "":
+			body = `{"data": [{"id": "c1"}, {"id": "c2"}], "pagination": {"offset": 0, "limit": 2}}`
+		case "2":
+			body = `{"data": [{"id": "c3"}, {"id": "c4"}], "pagination": {"offset": 2, "limit": 2}}`
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	list := outline.New(testServerURL, hc, testApiKey).Collections().List()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			got := []outline.CollectionID{}
+			err := list.Do(context.Background(), func(c *outline.Collection, err error) (bool, error) {
+				require.NoError(t, err)
+				got = append(got, c.ID)
+				return true, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []outline.CollectionID{"c1", "c2", "c3", "c4"}, got)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDocumentsClientUpdate_fields(t *testing.T) {
+	tests := map[string]struct {
+		update   func(*outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient
+		expected string
+	}{
+		"nothing set": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl },
+			expected: `{"id":"doc1"}`,
+		},
+		"title": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Title("New") },
+			expected: `{"id":"doc1", "title":"New"}`,
+		},
+		"empty text": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Text("") },
+			expected: `{"id":"doc1", "text":""}`,
+		},
+		"append false": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Append(false) },
+			expected: `{"id":"doc1", "append":false}`,
+		},
+		"append true": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Append(true) },
+			expected: `{"id":"doc1", "append":true}`,
+		},
+		"publish false": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Publish(false) },
+			expected: `{"id":"doc1", "publish":false}`,
+		},
+		"done false": {
+			update:   func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient { return cl.Done(false) },
+			expected: `{"id":"doc1", "done":false}`,
+		},
+		"last value wins": {
+			update: func(cl *outline.DocumentsUpdateClient) *outline.DocumentsUpdateClient {
+				return cl.Publish(true).Publish(false)
+			},
+			expected: `{"id":"doc1", "publish":false}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hc := &http.Client{}
+			hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+				testAssertBody(t, r, test.expected)
+				return &http.Response{
+					Request:       r,
+					ContentLength: -1,
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(strings.NewReader(exampleDocumentResponse)),
+				}, nil
+			}}
+
+			cl := outline.New(testServerURL, hc, testApiKey)
+			_, err := test.update(cl.Documents().Update("doc1")).Do(context.Background())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCollectionsClientUpdate_fields(t *testing.T) {
+	tests := map[string]struct {
+		update   func(*outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient
+		expected string
+	}{
+		"nothing set": {
+			update:   func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient { return cl },
+			expected: `{"id":"col1"}`,
+		},
+		"name": {
+			update:   func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient { return cl.Name("HR") },
+			expected: `{"id":"col1", "name":"HR"}`,
+		},
+		"empty description": {
+			update: func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient {
+				return cl.Description("")
+			},
+			expected: `{"id":"col1", "description":""}`,
+		},
+		"color": {
+			update: func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient {
+				return cl.Color("#AABBCC")
+			},
+			expected: `{"id":"col1", "color":"#AABBCC"}`,
+		},
+		"clear color": {
+			update:   func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient { return cl.ClearColor() },
+			expected: `{"id":"col1", "color":null}`,
+		},
+		"permission read": {
+			update: func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient {
+				return cl.PermissionRead()
+			},
+			expected: `{"id":"col1", "permission":"read"}`,
+		},
+		"permission read write": {
+			update: func(cl *outline.CollectionsUpdateClient) *outline.CollectionsUpdateClient {
+				return cl.PermissionReadWrite()
+			},
+			expected: `{"id":"col1", "permission":"read_write"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hc := &http.Client{}
+			hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+				testAssertBody(t, r, test.expected)
+				return &http.Response{
+					Request:       r,
+					ContentLength: -1,
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(strings.NewReader(exampleCollectionsGetResponse)),
+				}, nil
+			}}
+
+			cl := outline.New(testServerURL, hc, testApiKey)
+			_, err := test.update(cl.Collections().Update("col1")).Do(context.Background())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOAuthClientsClientUpdate_fields(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		testAssertBody(t, r, `{"id":"client1", "published":false, "description":"", "redirectUris":["https://a.b/cb"]}`)
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {"id": "client1"}}`)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	_, err := cl.OAuthClients().Update("client1").
+		Published(false).Description("").RedirectURIs([]string{"https://a.b/cb"}).
+		Do(context.Background())
+	require.NoError(t, err)
+}
+
+func TestCollectPolicies(t *testing.T) {
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		body := `{
+			"data": {"id": "doc1", "title": "Handbook"},
+			"policies": [{
+				"id": "doc1",
+				"abilities": {"read": true, "update": false, "share": true, "delete": false, "comment": ["team1"]}
+			}]
+		}`
+		if path.Base(r.URL.Path) == common.CollectionsListEndpoint() {
+			// Two pages of collections, every page with its own policies.
+			body = `{"data": [], "policies": []}`
+			switch r.URL.Query().Get("offset") {
+			case "":
+				body = `{
+					"data": [{"id": "col1"}, {"id": "col2"}],
+					"policies": [{"id": "col1", "abilities": {"update": true}}, {"id": "col2", "abilities": {}}]
+				}`
+			case "2":
+				body = `{
+					"data": [{"id": "col3"}, {"id": "col4"}],
+					"policies": [{"id": "col3", "abilities": {"update": true}}, {"id": "col4", "abilities": {}}]
+				}`
+			}
+		}
+
+		return &http.Response{
+			Request:       r,
+			ContentLength: -1,
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}}
+
+	cl := outline.New(testServerURL, hc, testApiKey)
+	policies := &outline.Policies{}
+	ctx := outline.CollectPolicies(context.Background(), policies)
+
+	doc, err := cl.Documents().Get().ByID("doc1").Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Handbook", doc.Title)
+	assert.True(t, policies.Can(string(doc.ID), outline.AbilityRead))
+	assert.True(t, policies.Can(string(doc.ID), outline.AbilityShare))
+	assert.False(t, policies.Can(string(doc.ID), outline.AbilityUpdate))
+	p, ok := policies.Get(string(doc.ID))
+	require.True(t, ok)
+	assert.True(t, p.Allows("comment"))
+
+	err = cl.Collections().List().Do(ctx, func(c *outline.Collection, err error) (bool, error) {
+		require.NoError(t, err)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.True(t, policies.Can("col1", outline.AbilityUpdate))
+	assert.False(t, policies.Can("col2", outline.AbilityUpdate))
+	assert.True(t, policies.Can("col3", outline.AbilityUpdate))
+	assert.False(t, policies.Can("unknown", outline.AbilityRead))
+}
+
+func TestDocumentsClientResolve(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	srv.AddCollection(outline.Collection{Name: "Other"})
+	col := srv.AddCollection(outline.Collection{Name: "Acme"})
+	parent := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Welcome"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, ParentDocumentID: parent.ID, Title: "Getting started"})
+	shareID := srv.ShareDocument(doc.ID)
+
+	tests := map[string]string{
+		"id":                string(doc.ID),
+		"url id":            doc.URLID,
+		"slug":              "getting-started-" + doc.URLID,
+		"url":               "https://wiki.example.com/doc/getting-started-" + doc.URLID,
+		"url with anchor":   "https://wiki.example.com/doc/getting-started-" + doc.URLID + "#setup",
+		"share link":        "https://wiki.example.com/s/" + string(shareID),
+		"legacy share link": "https://wiki.example.com/share/" + string(shareID),
+		"title path":        "Acme/Welcome/Getting started",
+		"padded title path": " /Acme/Welcome/Getting started/ ",
+	}
+	for name, ref := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := cl.Documents().Resolve(ctx, ref)
+			require.NoError(t, err)
+			assert.Equal(t, doc.ID, got.ID)
+		})
+	}
+
+	for _, ref := range []string{
+		"Acme/Welcome/Unknown",
+		"Unknown/Welcome",
+		"https://wiki.example.com/collection/acme",
+		"unknown",
+	} {
+		_, err := cl.Documents().Resolve(ctx, ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestParseURLID(t *testing.T) {
+	tests := map[string]outline.DocumentUrlID{
+		"hDYep1TPAM":                      "hDYep1TPAM",
+		"welcome-to-acme-hDYep1TPAM":      "hDYep1TPAM",
+		"/doc/welcome-to-acme-hDYep1TPAM": "hDYep1TPAM",
+		"https://wiki.example.com/doc/welcome-to-acme-hDYep1TPAM?q=1#setup": "hDYep1TPAM",
+	}
+	for ref, want := range tests {
+		got, ok := outline.ParseURLID(ref)
+		assert.True(t, ok, ref)
+		assert.Equal(t, want, got, ref)
+	}
+
+	for _, ref := range []string{
+		"497f6eca-6276-4993-bfeb-53cbbbba6f08",
+		"https://wiki.example.com/collection/acme-hDYep1TPAM",
+		"/doc/",
+		"welcome",
+	} {
+		_, ok := outline.ParseURLID(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, ".png", outline.FileExtension("image/png"))
+	assert.Equal(t, ".jpg", outline.FileExtension("image/jpeg"))
+	assert.Equal(t, ".txt", outline.FileExtension("text/plain; charset=utf-8"))
+	assert.Equal(t, ".json", outline.FileExtension("application/json"))
+	assert.Equal(t, "", outline.FileExtension("application/x-unknown"))
+	assert.Equal(t, "", outline.FileExtension(""))
+}
+
+func TestLinkRewriter(t *testing.T) {
+	rw := &outline.LinkRewriter{
+		Hosts:     []string{"Wiki.example.com"},
+		Documents: map[outline.DocumentID]outline.DocumentID{"doc1": "copy1"},
+		URLs:      map[string]string{"hDYep1TPAM": "/doc/welcome-xyzXYZ1234"},
+		Attachments: map[outline.AttachmentID]outline.AttachmentID{
+			"2f1c7b9e-1d2a-4c3b-9e8f-0a1b2c3d4e5f": "7d6e5f4a-3b2c-4d1e-8f9a-0b1c2d3e4f5a",
+		},
+		Users: map[outline.UserID]outline.UserID{"jane": "jane2", "john": ""},
+	}
+
+	text := strings.Join([]string{
+		"[Welcome](https://wiki.example.com/doc/welcome-hDYep1TPAM#setup)",
+		"[Relative](/doc/welcome-hDYep1TPAM)",
+		"[Elsewhere](https://other.example.com/doc/welcome-hDYep1TPAM)",
+		"[Unknown](/doc/unknown-aaaaaaaaaa)",
+		"![Logo](/api/attachments.redirect?id=2f1c7b9e-1d2a-4c3b-9e8f-0a1b2c3d4e5f)",
+		"@[Welcome](mention://m1/document/doc1) @[Jane](mention://m2/user/doc1)",
+		"@[Jane](mention://m3/user/jane) @[John](mention://m4/user/john)",
+	}, "\n")
+	want := strings.Join([]string{
+		"[Welcome](/doc/welcome-xyzXYZ1234#setup)",
+		"[Relative](/doc/welcome-xyzXYZ1234)",
+		"[Elsewhere](https://other.example.com/doc/welcome-hDYep1TPAM)",
+		"[Unknown](/doc/unknown-aaaaaaaaaa)",
+		"![Logo](/api/attachments.redirect?id=7d6e5f4a-3b2c-4d1e-8f9a-0b1c2d3e4f5a)",
+		"@[Welcome](mention://m1/document/copy1) @[Jane](mention://m2/user/doc1)",
+		"@[Jane](mention://m3/user/jane2) @[John](mention://m4/user/john)",
+	}, "\n")
+	assert.Equal(t, want, rw.Rewrite(text))
+
+	assert.True(t, rw.Internal("/doc/welcome-hDYep1TPAM"))
+	assert.True(t, rw.Internal("https://wiki.example.com/doc/welcome-hDYep1TPAM"))
+	assert.False(t, rw.Internal("https://other.example.com/doc/welcome-hDYep1TPAM"))
+}
+
+func TestDocumentsUpdateClient_ifRevision(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Status"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "API", Text: "All good"})
+
+	updated, err := cl.Documents().Update(doc.ID).Text("Degraded").IfRevision(doc.Revision).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, doc.Revision+1, updated.Revision)
+
+	_, err = cl.Documents().Update(doc.ID).Text("Outage").IfRevision(doc.Revision).Do(ctx)
+	require.ErrorIs(t, err, outline.ErrConflict)
+	conflict := &outline.ConflictError{}
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, doc.Revision, conflict.Revision)
+	assert.Equal(t, "Degraded", conflict.Current.Text)
+
+	stored, _ := srv.Document(doc.ID)
+	assert.Equal(t, "Degraded", stored.Text)
+}
+
+func TestClientModifyDocument(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+
+	col := srv.AddCollection(outline.Collection{Name: "Status"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Status", Text: "API: up\nWeb: up\nDB: up\n"})
+
+	// Someone else changes another line in the meantime. Changes to adjacent lines would conflict.
+	got, err := cl.ModifyDocument(ctx, doc.ID, func(d *outline.Document) error {
+		_, err := cl.Documents().Update(doc.ID).Text("API: up\nWeb: up\nDB: down\n").Do(ctx)
+		require.NoError(t, err)
+		d.Text = strings.Replace(d.Text, "API: up", "API: degraded", 1)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "API: degraded\nWeb: up\nDB: down\n", got.Text)
+	assert.Equal(t, doc.Revision+2, got.Revision)
+
+	// Someone else changes the same line in the meantime.
+	_, err = cl.ModifyDocument(ctx, doc.ID, func(d *outline.Document) error {
+		_, err := cl.Documents().Update(doc.ID).Text("API: down\nWeb: up\nDB: down\n").Do(ctx)
+		require.NoError(t, err)
+		d.Text = "API: up\nWeb: up\nDB: down\n"
+		return nil
+	})
+	require.ErrorIs(t, err, outline.ErrConflict)
+	stored, _ := srv.Document(doc.ID)
+	assert.Equal(t, "API: down\nWeb: up\nDB: down\n", stored.Text)
+
+	// Errors of fn are returned as is.
+	fnErr := errors.New("fn failed")
+	_, err = cl.ModifyDocument(ctx, doc.ID, func(d *outline.Document) error {
+		return fnErr
+	})
+	assert.Equal(t, fnErr, err)
+}
+
 func testAssertHeaders(t *testing.T, headers http.Header) {
 	t.Helper()
 	assert.Equal(t, headers.Get(common.HdrKeyAccept), common.HdrValueAccept)
@@ -935,3 +2971,179 @@ const exampleAttachmentsCreateResponse string = `{
 		}
 	}
 }`
+
+const exampleDocumentsListResponse_2documents string = `{
+	"data": [
+		{
+			"id": "doc1",
+			"collectionId": "collection id",
+			"title": "Doc 1",
+			"text": "Some text",
+			"urlId": "hDYep1TPAM",
+			"revision": 3,
+			"createdAt": "2019-08-24T14:15:22Z",
+			"updatedAt": "2019-08-24T14:15:22Z"
+		},
+		{
+			"id": "doc2",
+			"collectionId": "collection id",
+			"title": "Doc 2",
+			"text": "Some other text",
+			"urlId": "aBCde1TPAM",
+			"revision": 1,
+			"createdAt": "2019-08-24T14:15:22Z",
+			"updatedAt": "2019-08-24T14:15:22Z"
+		}
+	],
+	"pagination": {
+		"offset": 0,
+		"limit": 25
+	}
+}`
+
+const exampleViewsCreateResponse string = `{
+	"data": {
+		"id": "7c7a4ab9-7a4e-4a8c-b05e-6ab4fe8c3e1e",
+		"documentId": "doc1",
+		"count": 12,
+		"firstViewedAt": "2019-08-24T14:15:22Z",
+		"lastViewedAt": "2023-01-12T08:00:00Z",
+		"user": {
+			"id": "e4b5b3f0-2bb4-4b8e-a5d5-b24f1a3ab2d3",
+			"name": "Jane Doe",
+			"avatarUrl": "https://avatar.url",
+			"email": "jane@example.com",
+			"isAdmin": false,
+			"isSuspended": false,
+			"lastActiveAt": "2023-01-12T08:00:00Z",
+			"createdAt": "2019-08-24T14:15:22Z"
+		}
+	}
+}`
+
+const exampleViewsListResponse string = `{
+	"data": [
+		{
+			"id": "7c7a4ab9-7a4e-4a8c-b05e-6ab4fe8c3e1e",
+			"documentId": "doc1",
+			"count": 12,
+			"firstViewedAt": "2019-08-24T14:15:22Z",
+			"lastViewedAt": "2023-01-12T08:00:00Z",
+			"user": {
+				"id": "e4b5b3f0-2bb4-4b8e-a5d5-b24f1a3ab2d3",
+				"name": "Jane Doe",
+				"avatarUrl": "https://avatar.url",
+				"email": "jane@example.com",
+				"isAdmin": false,
+				"isSuspended": false,
+				"lastActiveAt": "2023-01-12T08:00:00Z",
+				"createdAt": "2019-08-24T14:15:22Z"
+			}
+		}
+	]
+}`
+
+const exampleDocumentsMembershipsResponse_1membership string = `{
+	"data": {
+		"users": [
+			{
+				"id": "user1",
+				"name": "Jane Doe",
+				"email": "jane@example.com"
+			}
+		],
+		"memberships": [
+			{
+				"id": "membership1",
+				"documentId": "doc1",
+				"userId": "user1",
+				"permission": "read"
+			}
+		]
+	}
+}`
+
+const exampleDocumentsMembershipsResponse_2memberships string = `{
+	"data": {
+		"users": [
+			{
+				"id": "user2",
+				"name": "John Doe",
+				"email": "john@example.com"
+			},
+			{
+				"id": "user1",
+				"name": "Jane Doe",
+				"email": "jane@example.com"
+			}
+		],
+		"memberships": [
+			{
+				"id": "membership1",
+				"documentId": "doc1",
+				"userId": "user1",
+				"permission": "read_write"
+			},
+			{
+				"id": "membership2",
+				"documentId": "doc1",
+				"userId": "user2",
+				"permission": "read_write"
+			}
+		]
+	},
+	"pagination": {
+		"offset": 0,
+		"limit": 25
+	}
+}`
+
+const exampleDocumentsAnswerQuestionResponse string = `{
+	"documents": [
+		{
+			"id": "doc1",
+			"title": "DB Runbook",
+			"url": "/doc/db-runbook-hDYep1TPAM",
+			"text": "Some text"
+		}
+	],
+	"search": {
+		"id": "a8b1dbfb-6d38-4c3e-9bd7-b5cb4d1e4e1b",
+		"query": "how do I rotate the DB password?",
+		"answer": "Run the rotation job described in the runbook.",
+		"source": "api",
+		"createdAt": "2023-01-12T08:00:00Z"
+	},
+	"policies": []
+}`
+
+const exampleAPIKeysCreateResponse string = `{
+	"data": {
+		"id": "key1",
+		"name": "sync job",
+		"value": "ol_api_secret",
+		"last4": "cret",
+		"scope": ["documents.info", "collections.*"],
+		"expiresAt": "2024-01-01T00:00:00Z",
+		"lastActiveAt": null,
+		"createdAt": "2023-01-12T08:00:00Z",
+		"updatedAt": "2023-01-12T08:00:00Z"
+	}
+}`
+
+const exampleOAuthClientsCreateResponse string = `{
+	"data": {
+		"id": "oauth client id",
+		"name": "Portal",
+		"description": "",
+		"developerName": "Platform",
+		"developerUrl": "",
+		"avatarUrl": "",
+		"clientId": "5f2a1e0b3c4d",
+		"clientSecret": "ol_sk_secret",
+		"redirectUris": ["https://portal.example.com/callback"],
+		"published": false,
+		"createdAt": "2023-01-12T08:00:00Z",
+		"updatedAt": "2023-01-12T08:00:00Z"
+	}
+}`
@@ -0,0 +1,130 @@
+package outline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Ability is an action on a resource which a [Policy] allows or denies.
+type Ability string
+
+const (
+	AbilityRead    Ability = "read"
+	AbilityUpdate  Ability = "update"
+	AbilityDelete  Ability = "delete"
+	AbilityShare   Ability = "share"
+	AbilityArchive Ability = "archive"
+)
+
+// Policy tells what the authorized user is allowed to do with a single resource e.g. a document or a collection.
+type Policy struct {
+	// ID is the id of the resource the policy applies to.
+	ID        string           `json:"id"`
+	Abilities map[Ability]bool `json:"abilities"`
+}
+
+// Allows returns true if the policy allows a.
+func (p Policy) Allows(a Ability) bool {
+	return p.Abilities[a]
+}
+
+func (p *Policy) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID        string                      `json:"id"`
+		Abilities map[Ability]json.RawMessage `json:"abilities"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	// Most abilities are booleans but some are lists of the ids they apply to. These are allowed if not empty.
+	p.ID = raw.ID
+	p.Abilities = make(map[Ability]bool, len(raw.Abilities))
+	for a, v := range raw.Abilities {
+		var allowed bool
+		var ids []string
+		if json.Unmarshal(v, &allowed) != nil && json.Unmarshal(v, &ids) == nil {
+			allowed = len(ids) > 0
+		}
+		p.Abilities[a] = allowed
+	}
+
+	return nil
+}
+
+// Policies collects the policies returned along with responses of calls made with a context created by
+// [CollectPolicies]. It is safe for concurrent use and its zero value is ready to use.
+type Policies struct {
+	mu   sync.Mutex
+	byID map[string]Policy
+}
+
+// Get returns the policy of the resource identified by id.
+func (ps *Policies) Get(id string) (Policy, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.byID[id]
+	return p, ok
+}
+
+// Can returns true if the policy of the resource identified by id allows a. It returns false if there is no policy for
+// the resource.
+func (ps *Policies) Can(id string, a Ability) bool {
+	p, _ := ps.Get(id)
+	return p.Allows(a)
+}
+
+func (ps *Policies) add(policies []Policy) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.byID == nil {
+		ps.byID = map[string]Policy{}
+	}
+	for _, p := range policies {
+		ps.byID[p.ID] = p
+	}
+}
+
+type policiesKey struct{}
+
+// CollectPolicies returns a copy of ctx which makes calls add the policies returned by the server to ps. The policies
+// tell what the authorized user can do with the resources part of the responses e.g. every collection listed:
+//
+//	policies := &outline.Policies{}
+//	err := cl.Collections().List().Do(outline.CollectPolicies(ctx, policies), fn)
+//	canEdit := policies.Can(string(col.ID), outline.AbilityUpdate)
+func CollectPolicies(ctx context.Context, ps *Policies) context.Context {
+	return context.WithValue(ctx, policiesKey{}, ps)
+}
+
+// policiesDecoder is a [rsling.ResponseDecoder] which decodes the response into the given value as usual but
+// additionally adds the policies part of the response to ps.
+type policiesDecoder struct {
+	ps *Policies
+}
+
+func (d policiesDecoder) Decode(resp *http.Response, v any) error {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return err
+	}
+
+	policies := struct {
+		Policies []Policy `json:"policies"`
+	}{}
+	if err := json.Unmarshal(b, &policies); err != nil {
+		return fmt.Errorf("failed decoding policies: %w", err)
+	}
+	d.ps.add(policies.Policies)
+
+	return nil
+}
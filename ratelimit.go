@@ -0,0 +1,166 @@
+package outline
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	hdrKeyRateLimitRemaining = "RateLimit-Remaining"
+	hdrKeyRateLimitReset     = "RateLimit-Reset"
+	hdrKeyRetryAfter         = "Retry-After"
+)
+
+// WithRateLimit makes all calls of the client, regardless of the resource level client used, share a token bucket
+// which allows rps calls per second on average and bursts of up to burst calls. Calls wait for a token and fail if
+// their context is done before. On top of that the limiter adapts to the rate limit headers returned by the server:
+// once the server reports no remaining calls, or responds with 429, all calls wait until the reported reset time.
+// A non-positive rps disables the client side budget and only the server's headers are honoured.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		o.limiter = newRateLimiter(rps, burst)
+	}
+}
+
+// rateLimiter is a token bucket which additionally blocks all calls until a given time when told so by the server.
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // maximum number of tokens
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time // last time tokens were added
+	blockedUntil time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// middleware returns the limiter as a Middleware.
+func (l *rateLimiter) middleware(next Invoker) Invoker {
+	return func(call *Call) *CallResult {
+		if err := l.wait(call.Request.Context()); err != nil {
+			return &CallResult{Err: err}
+		}
+
+		res := next(call)
+		if res.Response != nil {
+			l.observe(res.Response)
+		}
+		return res
+	}
+}
+
+// wait blocks until a call is allowed to be made or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available at now and returns zero. Otherwise it returns how long to wait before
+// trying again.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// observe adapts the limiter to the rate limit state reported by the server in resp.
+func (l *rateLimiter) observe(resp *http.Response) {
+	now := time.Now()
+
+	var until time.Time
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if t, ok := parseRateLimitTime(resp.Header.Get(hdrKeyRetryAfter), now); ok {
+			until = t
+		} else if t, ok := parseRateLimitTime(resp.Header.Get(hdrKeyRateLimitReset), now); ok {
+			until = t
+		} else {
+			until = now.Add(time.Second)
+		}
+	}
+
+	remaining, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get(hdrKeyRateLimitRemaining)))
+	hasRemaining := err == nil
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if hasRemaining && remaining <= 0 && until.IsZero() {
+		if t, ok := parseRateLimitTime(resp.Header.Get(hdrKeyRateLimitReset), now); ok {
+			until = t
+		}
+	}
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+
+	// Never hand out more tokens than the server is still willing to accept.
+	if hasRemaining && float64(remaining) < l.tokens {
+		l.tokens = float64(remaining)
+	}
+}
+
+// parseRateLimitTime parses the value of a rate limit related header into an absolute time. The value can either be a
+// number of seconds from now, a unix timestamp or a date.
+func parseRateLimitTime(v string, now time.Time) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		// Values too big for being a delay are unix timestamps.
+		if f > 1e9 {
+			return time.Unix(int64(f), 0), true
+		}
+		return now.Add(time.Duration(f * float64(time.Second))), true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	// Format of JavaScript's Date.prototype.toString() e.g. "Tue Jan 02 2024 15:04:05 GMT+0000 (Coordinated
+	// Universal Time)".
+	if i := strings.Index(v, " ("); i > 0 {
+		v = v[:i]
+	}
+	if t, err := time.Parse("Mon Jan 02 2006 15:04:05 GMT-0700", v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,53 @@
+package outline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRateLimitTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := map[string]struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		"empty":               {value: "", ok: false},
+		"garbage":             {value: "soon", ok: false},
+		"delay in seconds":    {value: "2", expected: now.Add(2 * time.Second), ok: true},
+		"fractional delay":    {value: "0.5", expected: now.Add(500 * time.Millisecond), ok: true},
+		"unix timestamp":      {value: "1704207850", expected: time.Unix(1704207850, 0), ok: true},
+		"HTTP date":           {value: "Tue, 02 Jan 2024 15:04:10 GMT", expected: now.Add(5 * time.Second), ok: true},
+		"JavaScript date":     {value: "Tue Jan 02 2024 15:04:10 GMT+0000", expected: now.Add(5 * time.Second), ok: true},
+		"JavaScript date +tz": {value: "Tue Jan 02 2024 16:04:10 GMT+0100 (CET)", expected: now.Add(5 * time.Second), ok: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := parseRateLimitTime(test.value, now)
+			assert.Equal(t, test.ok, ok)
+			assert.True(t, test.expected.Equal(got), "expected %s, got %s", test.expected, got)
+		})
+	}
+}
+
+func Test_rateLimiter_reserve(t *testing.T) {
+	start := time.Now()
+	l := newRateLimiter(10, 2)
+	l.last = start
+
+	// Burst is available right away.
+	assert.Zero(t, l.reserve(start))
+	assert.Zero(t, l.reserve(start))
+
+	// Then one token every 100ms.
+	assert.Equal(t, 100*time.Millisecond, l.reserve(start))
+	assert.Zero(t, l.reserve(start.Add(100*time.Millisecond)))
+
+	// Server side block overrides everything.
+	l.blockedUntil = start.Add(time.Second)
+	assert.Equal(t, 500*time.Millisecond, l.reserve(start.Add(500*time.Millisecond)))
+}
@@ -0,0 +1,121 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlIDPattern matches the url id at the end of a document slug e.g. hDYep1TPAM in welcome-to-acme-hDYep1TPAM.
+var urlIDPattern = regexp.MustCompile(`(?:^|-)([a-zA-Z0-9]{10})$`)
+
+// Resolve returns the document referenced by ref which can be any of the following:
+//   - the id of the document e.g. 497f6eca-6276-4993-bfeb-53cbbbba6f08
+//   - its url id e.g. hDYep1TPAM, or its slug e.g. welcome-to-acme-hDYep1TPAM
+//   - its url e.g. https://wiki.example.com/doc/welcome-to-acme-hDYep1TPAM
+//   - a share link e.g. https://wiki.example.com/s/6b2a5d0e-4b7e-4b5b-9d1a-0e6f4e1d2c3b
+//   - a path made of the name of the collection followed by the titles of the documents leading to the document e.g.
+//     Acme/Welcome/Getting started
+//
+// The host of urls is ignored, the document is always looked up on the server of the client. Title paths are resolved
+// via the collection's document structure hence only published documents can be found that way.
+func (cl *DocumentsClient) Resolve(ctx context.Context, ref string) (*Document, error) {
+	ref = strings.TrimSpace(ref)
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return cl.resolveURL(ctx, u)
+	}
+
+	if !strings.Contains(strings.Trim(ref, "/"), "/") {
+		return cl.Get().ByID(documentID(ref)).Do(ctx)
+	}
+
+	return cl.resolveTitlePath(ctx, ref)
+}
+
+// resolveURL gets the document referenced by a document url or a share link. Share links either point to the shared
+// document itself (/s/<share id>) or to one of its children (/s/<share id>/doc/<slug>).
+func (cl *DocumentsClient) resolveURL(ctx context.Context, u *url.URL) (*Document, error) {
+	get := cl.Get()
+	found := false
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		switch segments[i] {
+		case "s", "share":
+			get = get.ByShareID(DocumentShareID(segments[i+1]))
+			found = true
+		case "doc":
+			get = get.ByID(documentID(segments[i+1]))
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("url '%s' is neither a document url nor a share link", u)
+	}
+
+	return get.Do(ctx)
+}
+
+// resolveTitlePath gets the document referenced by a path like "Collection Name/Parent/Child".
+func (cl *DocumentsClient) resolveTitlePath(ctx context.Context, path string) (*Document, error) {
+	name, titles, _ := strings.Cut(strings.Trim(path, "/"), "/")
+
+	var col *Collection
+	err := newCollectionListClient(cl.sl).Do(ctx, func(c *Collection, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		if c.Name == name {
+			col = c
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing collections: %w", err)
+	}
+	if col == nil {
+		return nil, fmt.Errorf("collection '%s' not found", name)
+	}
+
+	st, err := newCollectionsDocumentStructureClient(cl.sl, col.ID).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting document structure of collection '%s': %w", name, err)
+	}
+	doc, ok := st.FindByTitlePath(titles)
+	if !ok {
+		return nil, fmt.Errorf("document '%s' not found in collection '%s'", titles, name)
+	}
+
+	return cl.Get().ByID(doc.ID).Do(ctx)
+}
+
+// documentID returns the id to get a document by given its id, url id or slug. The server accepts both, ids and url
+// ids, but not slugs. Note that ids never end in a dash followed by 10 characters.
+func documentID(ref string) DocumentID {
+	if id, ok := ParseURLID(ref); ok {
+		return DocumentID(id)
+	}
+	return DocumentID(ref)
+}
+
+// ParseURLID returns the url id of the document referenced by ref which is either its url id e.g. hDYep1TPAM, its slug
+// e.g. welcome-to-acme-hDYep1TPAM, or its url with or without host e.g. /doc/welcome-to-acme-hDYep1TPAM. False is
+// returned for anything else.
+func ParseURLID(ref string) (DocumentUrlID, bool) {
+	if u, err := url.Parse(ref); err == nil && strings.Contains(u.Path, "/") {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) < 2 || segments[0] != "doc" {
+			return "", false
+		}
+		ref = segments[1]
+	}
+
+	m := urlIDPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", false
+	}
+	return DocumentUrlID(m[1]), true
+}
@@ -0,0 +1,86 @@
+package outline
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// LinkRewriter rewrites the links and mentions in the text of documents copied from one server, or workspace, to
+// another such that they point to the copies instead of the source documents and attachments. The maps may be filled
+// while copying, only the copies known at the time of rewriting are taken into account.
+type LinkRewriter struct {
+	// Hosts are the hosts the source is reachable under e.g. wiki.example.com. Links without host are always rewritten,
+	// absolute links only if their host is one of these.
+	Hosts []string
+	// Documents maps the ids of source documents to the ids of their copies.
+	Documents map[DocumentID]DocumentID
+	// URLs maps the url ids of source documents to the urls of their copies.
+	URLs map[string]string
+	// Attachments maps the ids of source attachments to the ids of their copies.
+	Attachments map[AttachmentID]AttachmentID
+	// Users maps the ids of source users to the ids of the matching users of the target. Mentions of users missing
+	// from it, or mapped to an empty id, are left as is.
+	Users map[UserID]UserID
+}
+
+// Internal returns true if link points to the source i.e. it has no host or one of [LinkRewriter.Hosts].
+func (r *LinkRewriter) Internal(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	if u.Host == "" {
+		return true
+	}
+	for _, h := range r.Hosts {
+		if strings.EqualFold(h, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrite returns text with the links to copied documents and attachments, and the mentions of copied documents and
+// mapped users, pointing to the copies. Rewritten links have no host just like the links written by outline itself. Query and
+// fragment of links to documents are kept as anchors are the same for the copies. All other links are left as is.
+func (r *LinkRewriter) Rewrite(text string) string {
+	md := markdown.Parse(text)
+	for _, link := range md.Links() {
+		if !r.Internal(link.URL) {
+			continue
+		}
+
+		if id, ok := link.AttachmentID(); ok {
+			if newID, ok := r.Attachments[AttachmentID(id)]; ok {
+				link.URL = "/api/" + common.AttachmentsRedirectEndpoint() + "?id=" + string(newID)
+			}
+			continue
+		}
+		urlID, ok := ParseURLID(link.URL)
+		if !ok {
+			continue
+		}
+		if newURL, ok := r.URLs[string(urlID)]; ok {
+			u, _ := url.Parse(link.URL)
+			link.URL = (&url.URL{Path: newURL, RawQuery: u.RawQuery, Fragment: u.Fragment}).String()
+		}
+	}
+	for _, mention := range md.Mentions() {
+		id := mention.ModelID()
+		newID := ""
+		switch mention.Type() {
+		case "document":
+			newID = string(r.Documents[DocumentID(id)])
+		case "user":
+			newID = string(r.Users[UserID(id)])
+		}
+		if newID != "" {
+			mention.URL = strings.TrimSuffix(mention.URL, id) + newID
+		}
+	}
+
+	return md.String()
+}
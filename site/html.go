@@ -0,0 +1,99 @@
+package site
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"time"
+)
+
+//go:embed theme.html
+var defaultThemeText string
+
+var defaultTheme = template.Must(template.New("theme").Parse(defaultThemeText))
+
+// Page is the data a theme is executed with.
+type Page struct {
+	// Site is the title of the site.
+	Site  string
+	Title string
+	// Path is the path of the page relative to the root of the site e.g. runbooks/db/, it is empty for the root.
+	Path string
+	// Root is the url of the root of the site relative to the page e.g. ../../ to link to assets.
+	Root string
+	// Content is the text of a document, or the description of a collection, rendered as HTML.
+	Content template.HTML
+	// UpdatedAt is the time of the last change of the document or collection. It is zero for the root.
+	UpdatedAt time.Time
+	// Nav holds the collections of the site along with their documents.
+	Nav []*NavItem
+	// Breadcrumbs are the parents of the page starting with its collection.
+	Breadcrumbs []*NavItem
+	// Children are the pages right below the page i.e. the collections for the root.
+	Children []*NavItem
+}
+
+// NavItem is a link to a page.
+type NavItem struct {
+	Title string
+	// URL is the url of the page relative to the page being rendered.
+	URL string
+	// Current is true for the page being rendered.
+	Current bool
+	// Active is true for the page being rendered and its parents.
+	Active   bool
+	Children []*NavItem
+}
+
+// writeHTML renders p with the theme to index.html in the directory of p.
+func (b *builder) writeHTML(ctx context.Context, p *page) error {
+	md, err := b.rewrite(ctx, p)
+	if err != nil {
+		return fmt.Errorf("failed rewriting links of page '%s': %w", p.path, err)
+	}
+
+	data := &Page{
+		Site:      b.opts.title,
+		Title:     p.title,
+		Path:      p.path,
+		Root:      relURL(p.path, ""),
+		Content:   template.HTML(md.HTML()),
+		UpdatedAt: p.updatedAt,
+		Nav:       navItems(b.root.children, p),
+		Children:  navItems(p.children, p),
+	}
+	for parent := p.parent; parent != nil && parent != b.root; parent = parent.parent {
+		item := &NavItem{Title: parent.title, URL: relURL(p.path, parent.path), Active: true}
+		data.Breadcrumbs = append([]*NavItem{item}, data.Breadcrumbs...)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := b.opts.theme.Execute(buf, data); err != nil {
+		return fmt.Errorf("failed rendering page '%s': %w", p.path, err)
+	}
+	rel := filepath.Join(filepath.FromSlash(p.path), "index.html")
+	if err := writeFile(filepath.Join(b.dir, rel), buf.Bytes()); err != nil {
+		return fmt.Errorf("failed writing page '%s': %w", p.path, err)
+	}
+	b.res.Pages = append(b.res.Pages, filepath.ToSlash(rel))
+
+	return nil
+}
+
+// navItems returns the items of pages and the pages below them with urls relative to current.
+func navItems(pages []*page, current *page) []*NavItem {
+	items := []*NavItem{}
+	for _, p := range pages {
+		items = append(items, &NavItem{
+			Title:    p.title,
+			URL:      relURL(current.path, p.path),
+			Current:  p == current,
+			Active:   p.contains(current),
+			Children: navItems(p.children, current),
+		})
+	}
+	return items
+}
@@ -0,0 +1,73 @@
+package site
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// writeHugo writes p as Markdown file to the content tree. The root, collections and documents with children are
+// written as _index.md to the directory of the page, other documents as file named after the page.
+func (b *builder) writeHugo(ctx context.Context, p *page) error {
+	md, err := b.rewrite(ctx, p)
+	if err != nil {
+		return fmt.Errorf("failed rewriting links of page '%s': %w", p.path, err)
+	}
+	md.Blocks = noticesToQuotes(md.Blocks)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "---")
+	fmt.Fprintf(buf, "title: %s\n", strconv.Quote(p.title))
+	if p.parent != nil {
+		for i, sibling := range p.parent.children {
+			if sibling == p {
+				fmt.Fprintf(buf, "weight: %d\n", i+1)
+			}
+		}
+	}
+	if !p.updatedAt.IsZero() {
+		fmt.Fprintf(buf, "lastmod: %s\n", strconv.Quote(p.updatedAt.UTC().Format(time.RFC3339)))
+	}
+	fmt.Fprintln(buf, "---")
+	buf.WriteString(md.String())
+
+	rel := filepath.Join("content", filepath.FromSlash(p.path), "_index.md")
+	if len(p.children) == 0 && p.parent != nil && p.parent != b.root {
+		rel = filepath.Join("content", filepath.FromSlash(strings.TrimSuffix(p.path, "/")+".md"))
+	}
+	if err := writeFile(filepath.Join(b.dir, rel), buf.Bytes()); err != nil {
+		return fmt.Errorf("failed writing page '%s': %w", p.path, err)
+	}
+	b.res.Pages = append(b.res.Pages, filepath.ToSlash(rel))
+
+	return nil
+}
+
+// noticesToQuotes returns blocks with all notices replaced by block quotes holding the blocks of the notice. A blank
+// line is added after such quotes as the following lines would be part of the quote otherwise.
+func noticesToQuotes(blocks []markdown.Block) []markdown.Block {
+	replaced := []markdown.Block{}
+	for i, block := range blocks {
+		switch block := block.(type) {
+		case *markdown.Notice:
+			replaced = append(replaced, &markdown.Quote{Blocks: noticesToQuotes(block.Blocks), Line: block.Line})
+			if i+1 < len(blocks) {
+				if _, blank := blocks[i+1].(*markdown.BlankLine); !blank {
+					replaced = append(replaced, &markdown.BlankLine{Line: block.Line})
+				}
+			}
+		case *markdown.Quote:
+			block.Blocks = noticesToQuotes(block.Blocks)
+			replaced = append(replaced, block)
+		default:
+			replaced = append(replaced, block)
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,435 @@
+// Package site renders outline collections as static website e.g. to publish documentation on GitHub Pages with outline
+// as source of truth.
+//
+// Every collection and document becomes a page. The path of a page is made of the slugs of its title and the titles of
+// its parents, following the document structure:
+//
+//	index.html
+//	runbooks/
+//	  index.html
+//	  db/
+//	    index.html
+//	    failover/
+//	      index.html
+//	attachments/
+//	  0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10.png
+//
+// Pages are rendered with a theme, see [WithTheme], which gets the navigation along with the page. Links between
+// pages and to attachments are relative hence the site can be served from any path e.g. a GitHub project page.
+// Alternatively the site is written as content tree for Hugo, see [FormatHugo].
+package site
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/markdown"
+)
+
+// attachmentsDir is the directory, relative to the root of the site, holding all attachments.
+const attachmentsDir = "attachments"
+
+// Format is the kind of output written by [Build].
+type Format int
+
+const (
+	// FormatHTML writes a ready to serve website of HTML pages rendered with the theme.
+	FormatHTML Format = iota
+	// FormatHugo writes a content tree for Hugo: every page becomes a Markdown file with its title and weight in the
+	// front matter below content/, pages with children become branch bundles (_index.md). Attachments are written to
+	// static/ hence the directory can be merged into a Hugo site like cmd/outcli/docs/website. Notices become block
+	// quotes and mentions links or text as Hugo does not know them. The theme is not used.
+	FormatHugo
+)
+
+// Result lists the files written by [Build]. All paths are relative to the directory built to.
+type Result struct {
+	Pages       []string
+	Attachments []string
+}
+
+// Option configures [Build].
+type Option func(*options)
+
+type options struct {
+	format Format
+	theme  *template.Template
+	title  string
+	hosts  map[string]bool
+}
+
+// WithFormat sets the kind of output, the default is [FormatHTML].
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithTheme sets the template every page is rendered with. It is executed with a [Page]. The default theme, see
+// theme.html, is a single page layout with the navigation on the side and can serve as a starting point.
+func WithTheme(t *template.Template) Option {
+	return func(o *options) {
+		o.theme = t
+	}
+}
+
+// WithTitle sets the title of the site which is the title of its root page. The default is Documentation.
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithHosts makes [Build] rewrite absolute links to any of hosts e.g. wiki.example.com as well. By default only links
+// without host are rewritten, which is how outline writes links to its own documents and attachments.
+func WithHosts(hosts ...string) Option {
+	return func(o *options) {
+		for _, h := range hosts {
+			o.hosts[strings.ToLower(h)] = true
+		}
+	}
+}
+
+// Build renders the published documents of the collections identified by ids, or of all collections the client has
+// access to if no ids are given, into dir. Links to documents of the site are rewritten to point to their pages, links
+// to anything else are left as is. Attachments linked from the documents are copied to the site, links to attachments
+// which do not exist (anymore) are left as is. Files written by earlier builds are not removed hence dir should be
+// empty.
+func Build(
+	ctx context.Context,
+	cl *outline.Client,
+	dir string,
+	ids []outline.CollectionID,
+	opts ...Option,
+) (*Result, error) {
+	o := options{theme: defaultTheme, title: "Documentation", hosts: map[string]bool{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cols := []*outline.Collection{}
+	if len(ids) == 0 {
+		err := cl.Collections().List().Do(ctx, func(col *outline.Collection, err error) (bool, error) {
+			if err != nil {
+				return false, err
+			}
+			cols = append(cols, col)
+			return true, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed listing collections: %w", err)
+		}
+	}
+	for _, id := range ids {
+		col, err := cl.Collections().Get(id).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting collection '%s': %w", id, err)
+		}
+		cols = append(cols, col)
+	}
+
+	b := &builder{
+		cl:          cl,
+		dir:         dir,
+		opts:        o,
+		root:        &page{title: o.title},
+		docs:        map[outline.DocumentID]*page{},
+		urls:        map[string]*page{},
+		attachments: map[outline.AttachmentID]string{},
+		res:         &Result{Pages: []string{}, Attachments: []string{}},
+	}
+	// The directory of attachments must not be taken by a collection.
+	taken := map[string]bool{attachmentsDir: true}
+	for _, col := range cols {
+		if err := b.addCollection(ctx, col, taken); err != nil {
+			return nil, fmt.Errorf("failed adding collection '%s': %w", col.ID, err)
+		}
+	}
+
+	write := b.writeHTML
+	if o.format == FormatHugo {
+		write = b.writeHugo
+	}
+	if err := b.root.walk(func(p *page) error { return write(ctx, p) }); err != nil {
+		return nil, err
+	}
+	if o.format == FormatHTML {
+		// Tells GitHub Pages to serve the files as is instead of building them with Jekyll.
+		if err := writeFile(filepath.Join(dir, ".nojekyll"), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.res, nil
+}
+
+// page is a page of the site i.e. its root, a collection or a document.
+type page struct {
+	title string
+	// path is the path of the page relative to the root of the site, ending with a slash e.g. runbooks/db/
+	path string
+	// text is the text of a document or the description of a collection.
+	text      string
+	updatedAt time.Time
+	parent    *page
+	children  []*page
+}
+
+// walk calls fn for p and all pages below it, parents before their children.
+func (p *page) walk(fn func(*page) error) error {
+	if err := fn(p); err != nil {
+		return err
+	}
+	for _, c := range p.children {
+		if err := c.walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contains returns true if q is p or one of the pages below p.
+func (p *page) contains(q *page) bool {
+	for ; q != nil; q = q.parent {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// builder holds the state of a single build.
+type builder struct {
+	cl   *outline.Client
+	dir  string
+	opts options
+	root *page
+	docs map[outline.DocumentID]*page
+	// urls maps the url ids of documents and collections to their pages.
+	urls map[string]*page
+	// attachments maps the ids of copied attachments to their file names, the file name is empty for attachments
+	// which do not exist.
+	attachments map[outline.AttachmentID]string
+	res         *Result
+}
+
+// addCollection adds the pages of col and its documents below the root. Taken holds the names of the root's children
+// in lower case.
+func (b *builder) addCollection(ctx context.Context, col *outline.Collection, taken map[string]bool) error {
+	st, err := b.cl.Collections().DocumentStructure(col.ID).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting document structure: %w", err)
+	}
+	docs := map[outline.DocumentID]*outline.Document{}
+	ids := []outline.DocumentID{}
+	for _, doc := range st.Flatten() {
+		ids = append(ids, doc.ID)
+	}
+	for _, res := range b.cl.Documents().BatchGet(ctx, ids) {
+		if res.Err != nil {
+			return fmt.Errorf("failed getting document: %w", res.Err)
+		}
+		docs[res.Value.ID] = res.Value
+	}
+
+	colPage := b.addPage(b.root, col.Name, col.URLID, taken)
+	colPage.text = col.Description
+	colPage.updatedAt = col.UpdatedAt
+	b.urls[col.URLID] = colPage
+
+	var add func(parent *page, summaries []outline.DocumentSummary)
+	add = func(parent *page, summaries []outline.DocumentSummary) {
+		taken := map[string]bool{}
+		for _, summary := range summaries {
+			doc := docs[summary.ID]
+			p := b.addPage(parent, doc.Title, doc.URLID, taken)
+			p.text = doc.Text
+			p.updatedAt = doc.UpdatedAt
+			b.docs[doc.ID] = p
+			b.urls[doc.URLID] = p
+			add(p, summary.Children)
+		}
+	}
+	add(colPage, st)
+
+	return nil
+}
+
+// addPage adds a page with the given title as last child of parent. Siblings with the same slug are told apart by
+// their url id. Slugs are compared case insensitive as not all file systems are case sensitive.
+func (b *builder) addPage(parent *page, title string, urlID string, taken map[string]bool) *page {
+	name := slug(title)
+	if name == "" {
+		name = strings.ToLower(urlID)
+	} else if taken[name] {
+		name += "-" + strings.ToLower(urlID)
+	}
+	taken[name] = true
+
+	p := &page{title: title, path: parent.path + name + "/", parent: parent}
+	parent.children = append(parent.children, p)
+	return p
+}
+
+// rewrite returns the parsed text of p with links to pages of the site and to attachments pointing to them, relative
+// to p. Mentions of documents of the site become links to their pages.
+func (b *builder) rewrite(ctx context.Context, p *page) (*markdown.Document, error) {
+	md := markdown.Parse(p.text)
+	for _, link := range md.Links() {
+		u, err := url.Parse(link.URL)
+		if err != nil || (u.Host != "" && !b.opts.hosts[strings.ToLower(u.Hostname())]) {
+			continue
+		}
+
+		if id, ok := link.AttachmentID(); ok {
+			name, err := b.attachment(ctx, outline.AttachmentID(id))
+			if err != nil {
+				return nil, fmt.Errorf("failed copying attachment '%s': %w", id, err)
+			}
+			if name != "" {
+				link.URL = relURL(p.path, attachmentsDir+"/"+name)
+			}
+			continue
+		}
+		if urlID, ok := pageURLID(u); ok {
+			if target, ok := b.urls[urlID]; ok {
+				link.URL = (&url.URL{Path: relURL(p.path, target.path), Fragment: u.Fragment}).String()
+			}
+		}
+	}
+
+	replaceMentions(md, func(m *markdown.Mention) markdown.Inline {
+		if target, ok := b.docs[outline.DocumentID(m.ModelID())]; ok && m.Type() == "document" {
+			return &markdown.Link{Text: m.Name, URL: relURL(p.path, target.path), Line: m.Line}
+		}
+		if b.opts.format == FormatHugo {
+			return &markdown.Text{Value: "@" + m.Name, Line: m.Line}
+		}
+		return m
+	})
+
+	return md, nil
+}
+
+// pageURLID returns the url id of the document or collection u links to e.g. /doc/runbooks-hDYep1TPAM or
+// /collection/ops-k1CmJHTrFb.
+func pageURLID(u *url.URL) (string, bool) {
+	if segments := strings.Split(strings.Trim(u.Path, "/"), "/"); len(segments) >= 2 && segments[0] == "collection" {
+		urlID, ok := outline.ParseURLID(segments[1])
+		return string(urlID), ok
+	}
+	urlID, ok := outline.ParseURLID(u.String())
+	return string(urlID), ok
+}
+
+// attachment copies the attachment identified by id to the site unless done already, and returns its file name. The
+// name is empty if there is no such attachment.
+func (b *builder) attachment(ctx context.Context, id outline.AttachmentID) (string, error) {
+	if name, ok := b.attachments[id]; ok {
+		return name, nil
+	}
+
+	buf := &bytes.Buffer{}
+	contentType, err := b.cl.Attachments().Download(id).Do(ctx, buf)
+	if outline.IsNotFound(err) {
+		b.attachments[id] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name := string(id) + outline.FileExtension(contentType)
+	rel := filepath.Join(attachmentsDir, name)
+	if b.opts.format == FormatHugo {
+		rel = filepath.Join("static", rel)
+	}
+	if err := writeFile(filepath.Join(b.dir, rel), buf.Bytes()); err != nil {
+		return "", err
+	}
+	b.attachments[id] = name
+	b.res.Attachments = append(b.res.Attachments, filepath.ToSlash(rel))
+
+	return name, nil
+}
+
+// replaceMentions replaces every mention of md by the inline returned by fn.
+func replaceMentions(md *markdown.Document, fn func(*markdown.Mention) markdown.Inline) {
+	markdown.Walk(md, func(n markdown.Node) bool {
+		var content markdown.Inlines
+		switch n := n.(type) {
+		case *markdown.Paragraph:
+			content = n.Content
+		case *markdown.Heading:
+			content = n.Content
+		case *markdown.ListItem:
+			content = n.Content
+		case *markdown.Table:
+			content = n.Content
+		}
+		for i, in := range content {
+			if m, ok := in.(*markdown.Mention); ok {
+				content[i] = fn(m)
+			}
+		}
+		return true
+	})
+}
+
+// relURL returns the url of the page or file at the site path to relative to the page at the site path from.
+func relURL(from string, to string) string {
+	fromParts := []string{}
+	if from != "" {
+		fromParts = strings.Split(strings.TrimSuffix(from, "/"), "/")
+	}
+	toParts := strings.Split(to, "/")
+
+	i := 0
+	for i < len(fromParts) && i < len(toParts)-1 && fromParts[i] == toParts[i] {
+		i++
+	}
+	rel := strings.Repeat("../", len(fromParts)-i) + path.Join(toParts[i:]...)
+	if strings.HasSuffix(to, "/") && rel != "" && !strings.HasSuffix(rel, "/") {
+		rel += "/"
+	}
+	if rel == "" {
+		return "./"
+	}
+	return rel
+}
+
+// slug returns title in lower case with runs of other characters than letters and digits replaced by single dashes.
+func slug(title string) string {
+	b := &strings.Builder{}
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// writeFile writes data to the file at path creating its directory if needed.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,154 @@
+package site_test
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+	"github.com/ioki-mobility/go-outline/site"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+// testSite adds two collections to srv and returns the id of the first one.
+func testSite(t *testing.T, srv *outlinetest.Server) outline.CollectionID {
+	ops := srv.AddCollection(outline.Collection{Name: "Ops", Description: "All **runbooks**"})
+	db := srv.AddDocument(outline.Document{CollectionID: ops.ID, Title: "DB"})
+	img := srv.AddAttachment("diagram.png", "image/png", []byte("png"), db.ID)
+	srv.AddDocument(outline.Document{
+		CollectionID:     ops.ID,
+		ParentDocumentID: db.ID,
+		Title:            "Failover",
+		Text: ":::warning\nAsk @[Jane](mention://m1/user/u1) first\n:::\n" +
+			"See @[DB](mention://m2/document/" + string(db.ID) + ") and ![diagram](" + img + ").\n" +
+			"[lost](/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b)",
+	})
+	dev := srv.AddCollection(outline.Collection{Name: "Dev"})
+	srv.AddDocument(outline.Document{
+		CollectionID: dev.ID,
+		Title:        "Setup",
+		Text: "Read [DB](https://wiki.example.com/doc/db-" + db.URLID + "#h-backup) of [Ops](/collection/ops-" +
+			ops.URLID + "/recent) first",
+	})
+	return ops.ID
+}
+
+func TestBuild(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	testSite(t, srv)
+	dir := t.TempDir()
+
+	theme := template.Must(template.New("test").Parse(
+		"{{.Site}}|{{.Title}}|{{.Root}}|{{range .Breadcrumbs}}{{.URL}} {{end}}|" +
+			"{{range .Nav}}{{.URL}}{{if .Active}}*{{end}} {{end}}|{{range .Children}}{{.URL}} {{end}}|{{.Content}}",
+	))
+	res, err := site.Build(context.Background(), srv.OutlineClient(), dir, nil,
+		site.WithTheme(theme), site.WithTitle("Docs"), site.WithHosts("wiki.example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"index.html",
+		"ops/index.html",
+		"ops/db/index.html",
+		"ops/db/failover/index.html",
+		"dev/index.html",
+		"dev/setup/index.html",
+	}, res.Pages)
+	require.Len(t, res.Attachments, 1)
+	assert.Regexp(t, `^attachments/[0-9a-f-]{36}\.png$`, res.Attachments[0])
+
+	assert.Equal(t, "Docs|Docs|./||ops/ dev/ |ops/ dev/ |", readFile(t, dir, "index.html"))
+	assert.Equal(t, "Docs|Ops|../||./* ../dev/ |db/ |<p>All <strong>runbooks</strong></p>\n",
+		readFile(t, dir, "ops/index.html"))
+	assert.Equal(t,
+		"Docs|Failover|../../../|../../ ../ |../../* ../../../dev/ ||"+
+			"<div class=\"notice notice-warning\">\n<p>Ask <span class=\"mention\">@Jane</span> first</p>\n</div>\n"+
+			"<p>See <a href=\"../\">DB</a> and <img src=\"../../../"+res.Attachments[0]+"\" alt=\"diagram\">.\n"+
+			"<a href=\"/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b\">lost</a></p>\n",
+		readFile(t, dir, "ops/db/failover/index.html"),
+	)
+	assert.Equal(t,
+		"Docs|Setup|../../|../ |../../ops/ ../* ||"+
+			"<p>Read <a href=\"../../ops/db/#h-backup\">DB</a> of <a href=\"../../ops/\">Ops</a> first</p>\n",
+		readFile(t, dir, "dev/setup/index.html"),
+	)
+	assert.Equal(t, "png", readFile(t, dir, res.Attachments[0]))
+	assert.FileExists(t, filepath.Join(dir, ".nojekyll"))
+}
+
+func TestBuild_defaultTheme(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	ops := testSite(t, srv)
+	dir := t.TempDir()
+
+	res, err := site.Build(context.Background(), srv.OutlineClient(), dir, []outline.CollectionID{ops})
+	require.NoError(t, err)
+	assert.Len(t, res.Pages, 4)
+
+	page := readFile(t, dir, "ops/db/failover/index.html")
+	assert.Contains(t, page, "<title>Failover - Documentation</title>")
+	assert.Contains(t, page, `<a class="site" href="../../../">Documentation</a>`)
+	assert.Contains(t, page, `<a class="current" href="./">Failover</a>`)
+	assert.Contains(t, page, `<div class="breadcrumbs"><a href="../../">Ops</a> / <a href="../">DB</a></div>`)
+	assert.NotContains(t, page, "Dev")
+}
+
+func TestBuild_hugo(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	testSite(t, srv)
+	dir := t.TempDir()
+
+	res, err := site.Build(context.Background(), srv.OutlineClient(), dir, nil, site.WithFormat(site.FormatHugo))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"content/_index.md",
+		"content/ops/_index.md",
+		"content/ops/db/_index.md",
+		"content/ops/db/failover.md",
+		"content/dev/_index.md",
+		"content/dev/setup.md",
+	}, res.Pages)
+	require.Len(t, res.Attachments, 1)
+	assert.True(t, strings.HasPrefix(res.Attachments[0], "static/attachments/"))
+
+	assert.Equal(t, "---\ntitle: \"Documentation\"\n---\n", readFile(t, dir, "content/_index.md"))
+	failover := readFile(t, dir, "content/ops/db/failover.md")
+	assert.Regexp(t, "^---\ntitle: \"Failover\"\nweight: 1\nlastmod: \"[0-9TZ:-]+\"\n---\n", failover)
+	assert.True(t, strings.HasSuffix(failover,
+		"> Ask @Jane first\n\nSee [DB](../) and ![diagram](../../../"+
+			strings.TrimPrefix(res.Attachments[0], "static/")+").\n"+
+			"[lost](/api/attachments.redirect?id=7c2e4a1f-3b5d-4f6e-8a9b-0c1d2e3f4a5b)",
+	), failover)
+	// Absolute links to the server are only rewritten for the given hosts.
+	assert.Contains(t, readFile(t, dir, "content/dev/setup.md"), "(https://wiki.example.com/doc/db-")
+}
+
+func TestBuild_error(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	testSite(t, srv)
+	srv.InjectFault(outlinetest.Fault{Endpoint: common.AttachmentsRedirectEndpoint(), Status: http.StatusBadGateway})
+
+	_, err := site.Build(context.Background(), srv.OutlineClient(), t.TempDir(), nil)
+	require.Error(t, err)
+	assert.True(t, outline.IsTemporary(err))
+}
+
+func readFile(t *testing.T, dir string, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(b)
+}
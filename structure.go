@@ -0,0 +1,207 @@
+package outline
+
+import (
+	"errors"
+	"strings"
+)
+
+// SkipChildren can be returned by a [DocumentStructureWalkFn] to skip the children of the document it was called with.
+// The walk continues with the remaining documents.
+var SkipChildren = errors.New("skip children")
+
+// DocumentStructureWalkFn is the type of function called by [DocumentStructure.Walk] and
+// [DocumentStructure.WalkBreadthFirst] for every document. The path contains the ancestors of doc starting at the top
+// level document, it is empty for top level documents. The path must not be retained after fn returns. If fn returns
+// an error other than [SkipChildren] the walk is aborted and the error returned.
+type DocumentStructureWalkFn func(doc *DocumentSummary, path []*DocumentSummary) error
+
+// Walk visits all documents depth first i.e. every document is visited before its children and the children before the
+// document's next sibling.
+func (ds DocumentStructure) Walk(fn DocumentStructureWalkFn) error {
+	return walk(ds, nil, fn)
+}
+
+func walk(docs []DocumentSummary, path []*DocumentSummary, fn DocumentStructureWalkFn) error {
+	for i := range docs {
+		doc := &docs[i]
+		err := fn(doc, path)
+		if errors.Is(err, SkipChildren) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := walk(doc.Children, append(path, doc), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkBreadthFirst visits all documents level by level i.e. all top level documents first, then their children and so
+// on.
+func (ds DocumentStructure) WalkBreadthFirst(fn DocumentStructureWalkFn) error {
+	type item struct {
+		doc  *DocumentSummary
+		path []*DocumentSummary
+	}
+
+	queue := []item{}
+	for i := range ds {
+		queue = append(queue, item{doc: &ds[i]})
+	}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+
+		err := fn(it.doc, it.path)
+		if errors.Is(err, SkipChildren) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Every child gets its own copy of the path as items stay around until their level is visited.
+		path := append(append(make([]*DocumentSummary, 0, len(it.path)+1), it.path...), it.doc)
+		for i := range it.doc.Children {
+			queue = append(queue, item{doc: &it.doc.Children[i], path: path})
+		}
+	}
+	return nil
+}
+
+// FindByTitlePath returns the document found by following the slash separated titles in path from the top level
+// e.g. "Runbooks/DB/Failover" is the document titled "Failover" below "DB" below the top level document "Runbooks".
+// Leading and trailing slashes are ignored. If there are siblings with the same title the first one is used.
+func (ds DocumentStructure) FindByTitlePath(path string) (*DocumentSummary, bool) {
+	titles := strings.Split(strings.Trim(path, "/"), "/")
+	docs := []DocumentSummary(ds)
+
+	var found *DocumentSummary
+	for _, title := range titles {
+		found = nil
+		for i := range docs {
+			if docs[i].Title == title {
+				found = &docs[i]
+				break
+			}
+		}
+		if found == nil {
+			return nil, false
+		}
+		docs = found.Children
+	}
+	return found, true
+}
+
+// Find returns the document identified by id.
+func (ds DocumentStructure) Find(id DocumentID) (*DocumentSummary, bool) {
+	doc, _, ok := ds.find(id)
+	return doc, ok
+}
+
+// Flatten returns all documents in the order visited by [DocumentStructure.Walk].
+func (ds DocumentStructure) Flatten() []*DocumentSummary {
+	docs := []*DocumentSummary{}
+	_ = ds.Walk(func(doc *DocumentSummary, _ []*DocumentSummary) error {
+		docs = append(docs, doc)
+		return nil
+	})
+	return docs
+}
+
+// Parent returns the parent of the document identified by id. The parent is nil for top level documents. False is
+// returned if there is no such document.
+func (ds DocumentStructure) Parent(id DocumentID) (*DocumentSummary, bool) {
+	_, path, ok := ds.find(id)
+	if !ok || len(path) == 0 {
+		return nil, ok
+	}
+	return path[len(path)-1], true
+}
+
+// Depth returns the depth of the document identified by id, top level documents have a depth of 0. False is returned
+// if there is no such document.
+func (ds DocumentStructure) Depth(id DocumentID) (int, bool) {
+	_, path, ok := ds.find(id)
+	return len(path), ok
+}
+
+// find returns the document identified by id along with its ancestors.
+func (ds DocumentStructure) find(id DocumentID) (*DocumentSummary, []*DocumentSummary, bool) {
+	errFound := errors.New("found")
+
+	var found *DocumentSummary
+	var foundPath []*DocumentSummary
+	err := ds.Walk(func(doc *DocumentSummary, path []*DocumentSummary) error {
+		if doc.ID != id {
+			return nil
+		}
+		found, foundPath = doc, append([]*DocumentSummary{}, path...)
+		return errFound
+	})
+	return found, foundPath, errors.Is(err, errFound)
+}
+
+// DocumentStructureDiff describes how a document structure changed, see [DiffDocumentStructures].
+type DocumentStructureDiff struct {
+	// Added are the documents only part of the new structure.
+	Added []*DocumentSummary
+	// Removed are the documents only part of the old structure.
+	Removed []*DocumentSummary
+	// Moved are the documents which got a different parent.
+	Moved []DocumentMove
+}
+
+// DocumentMove describes a document which was moved to another parent.
+type DocumentMove struct {
+	Document *DocumentSummary // as part of the new structure
+	// From and To are the ids of the old and the new parent. An empty id means top level.
+	From DocumentID
+	To   DocumentID
+}
+
+// Empty returns true if there are no differences.
+func (d DocumentStructureDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// DiffDocumentStructures compares the structure before a change with the one after. Documents are matched by id. All
+// lists are in the order the documents are visited by [DocumentStructure.Walk].
+func DiffDocumentStructures(before, after DocumentStructure) DocumentStructureDiff {
+	oldParents := parents(before)
+	newParents := parents(after)
+
+	diff := DocumentStructureDiff{}
+	for _, doc := range after.Flatten() {
+		from, ok := oldParents[doc.ID]
+		if !ok {
+			diff.Added = append(diff.Added, doc)
+			continue
+		}
+		if to := newParents[doc.ID]; from != to {
+			diff.Moved = append(diff.Moved, DocumentMove{Document: doc, From: from, To: to})
+		}
+	}
+	for _, doc := range before.Flatten() {
+		if _, ok := newParents[doc.ID]; !ok {
+			diff.Removed = append(diff.Removed, doc)
+		}
+	}
+
+	return diff
+}
+
+// parents returns the parent id of every document, top level documents have an empty parent id.
+func parents(ds DocumentStructure) map[DocumentID]DocumentID {
+	m := map[DocumentID]DocumentID{}
+	_ = ds.Walk(func(doc *DocumentSummary, path []*DocumentSummary) error {
+		m[doc.ID] = ""
+		if len(path) > 0 {
+			m[doc.ID] = path[len(path)-1].ID
+		}
+		return nil
+	})
+	return m
+}
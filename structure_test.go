@@ -0,0 +1,170 @@
+package outline_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStructure returns the following structure:
+//
+//	Runbooks (rb)
+//	  DB (db)
+//	    Failover (fo)
+//	    Backup (bk)
+//	  Network (nw)
+//	Onboarding (ob)
+func testStructure() outline.DocumentStructure {
+	return outline.DocumentStructure{
+		{ID: "rb", Title: "Runbooks", Children: []outline.DocumentSummary{
+			{ID: "db", Title: "DB", Children: []outline.DocumentSummary{
+				{ID: "fo", Title: "Failover"},
+				{ID: "bk", Title: "Backup"},
+			}},
+			{ID: "nw", Title: "Network"},
+		}},
+		{ID: "ob", Title: "Onboarding"},
+	}
+}
+
+func TestDocumentStructure_Walk(t *testing.T) {
+	visited := []string{}
+	err := testStructure().Walk(func(doc *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		p := ""
+		for _, d := range path {
+			p += d.Title + "/"
+		}
+		visited = append(visited, p+doc.Title)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Runbooks", "Runbooks/DB", "Runbooks/DB/Failover", "Runbooks/DB/Backup", "Runbooks/Network", "Onboarding",
+	}, visited)
+
+	// Children can be skipped and the walk aborted.
+	visited = []string{}
+	errStop := errors.New("stop")
+	err = testStructure().Walk(func(doc *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		visited = append(visited, doc.Title)
+		switch doc.ID {
+		case "db":
+			return outline.SkipChildren
+		case "nw":
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"Runbooks", "DB", "Network"}, visited)
+}
+
+func TestDocumentStructure_WalkBreadthFirst(t *testing.T) {
+	visited := []string{}
+	depths := []int{}
+	err := testStructure().WalkBreadthFirst(func(doc *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		visited = append(visited, doc.Title)
+		depths = append(depths, len(path))
+		if doc.ID == "nw" {
+			assert.Equal(t, outline.DocumentID("rb"), path[0].ID)
+		}
+		if doc.ID == "ob" {
+			return outline.SkipChildren
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Runbooks", "Onboarding", "DB", "Network", "Failover", "Backup"}, visited)
+	assert.Equal(t, []int{0, 0, 1, 1, 2, 2}, depths)
+}
+
+func TestDocumentStructure_FindByTitlePath(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected outline.DocumentID
+		ok       bool
+	}{
+		"top level":        {path: "Onboarding", expected: "ob", ok: true},
+		"nested":           {path: "Runbooks/DB/Failover", expected: "fo", ok: true},
+		"surrounding /":    {path: "/Runbooks/DB/", expected: "db", ok: true},
+		"not found":        {path: "Runbooks/Failover", ok: false},
+		"path too long":    {path: "Onboarding/Day 1", ok: false},
+		"case differences": {path: "runbooks", ok: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, ok := testStructure().FindByTitlePath(test.path)
+			require.Equal(t, test.ok, ok)
+			if ok {
+				assert.Equal(t, test.expected, doc.ID)
+			}
+		})
+	}
+}
+
+func TestDocumentStructure_lookups(t *testing.T) {
+	st := testStructure()
+
+	ids := []outline.DocumentID{}
+	for _, doc := range st.Flatten() {
+		ids = append(ids, doc.ID)
+	}
+	assert.Equal(t, []outline.DocumentID{"rb", "db", "fo", "bk", "nw", "ob"}, ids)
+
+	doc, ok := st.Find("bk")
+	require.True(t, ok)
+	assert.Equal(t, "Backup", doc.Title)
+	_, ok = st.Find("unknown")
+	assert.False(t, ok)
+
+	parent, ok := st.Parent("bk")
+	require.True(t, ok)
+	assert.Equal(t, outline.DocumentID("db"), parent.ID)
+	parent, ok = st.Parent("ob")
+	assert.True(t, ok)
+	assert.Nil(t, parent)
+	_, ok = st.Parent("unknown")
+	assert.False(t, ok)
+
+	depth, ok := st.Depth("fo")
+	assert.True(t, ok)
+	assert.Equal(t, 2, depth)
+	depth, ok = st.Depth("ob")
+	assert.True(t, ok)
+	assert.Equal(t, 0, depth)
+	_, ok = st.Depth("unknown")
+	assert.False(t, ok)
+}
+
+func TestDiffDocumentStructures(t *testing.T) {
+	before := testStructure()
+	assert.True(t, outline.DiffDocumentStructures(before, testStructure()).Empty())
+
+	// Move Backup to the top level, remove Network and add Day 1 below Onboarding.
+	after := outline.DocumentStructure{
+		{ID: "rb", Title: "Runbooks", Children: []outline.DocumentSummary{
+			{ID: "db", Title: "DB", Children: []outline.DocumentSummary{
+				{ID: "fo", Title: "Failover"},
+			}},
+		}},
+		{ID: "bk", Title: "Backup"},
+		{ID: "ob", Title: "Onboarding", Children: []outline.DocumentSummary{
+			{ID: "d1", Title: "Day 1"},
+		}},
+	}
+
+	diff := outline.DiffDocumentStructures(before, after)
+	assert.False(t, diff.Empty())
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, outline.DocumentID("d1"), diff.Added[0].ID)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, outline.DocumentID("nw"), diff.Removed[0].ID)
+	require.Len(t, diff.Moved, 1)
+	assert.Equal(t, outline.DocumentID("bk"), diff.Moved[0].Document.ID)
+	assert.Equal(t, outline.DocumentID("db"), diff.Moved[0].From)
+	assert.Equal(t, outline.DocumentID(""), diff.Moved[0].To)
+}
@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// attachmentsDir is the directory, relative to the directory a collection is mirrored to, holding all attachments.
+const attachmentsDir = "_attachments"
+
+// attachmentLinkRe matches links to attachments as written by outline, with or without the server's origin.
+var attachmentLinkRe = regexp.MustCompile(`(?:https?://[^\s()"'<>]+)?/api/attachments\.redirect\?id=([0-9a-fA-F-]{36})`)
+
+// pullAttachments downloads all attachments linked from text, the text of the document stored at path, which are not
+// yet stored locally. The text is returned with the links replaced by paths relative to the document's file.
+func pullAttachments(ctx context.Context, cl *outline.Client, dir string, path string, text string) (string, error) {
+	local, err := localAttachments(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var pullErr error
+	text = attachmentLinkRe.ReplaceAllStringFunc(text, func(link string) string {
+		if pullErr != nil {
+			return link
+		}
+		id := outline.AttachmentID(attachmentLinkRe.FindStringSubmatch(link)[1])
+
+		name, ok := local[id]
+		if !ok {
+			name, pullErr = downloadAttachment(ctx, cl, dir, id)
+			if pullErr != nil {
+				return link
+			}
+			local[id] = name
+		}
+
+		rel, err := filepath.Rel(filepath.Dir(filepath.Join(dir, path)), filepath.Join(dir, attachmentsDir, name))
+		if err != nil {
+			pullErr = err
+			return link
+		}
+		return filepath.ToSlash(rel)
+	})
+	if pullErr != nil {
+		return "", pullErr
+	}
+
+	return text, nil
+}
+
+// localAttachments returns the file names of the attachments stored in dir by attachment id.
+func localAttachments(dir string) (map[outline.AttachmentID]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, attachmentsDir))
+	if os.IsNotExist(err) {
+		return map[outline.AttachmentID]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[outline.AttachmentID]string{}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		names[outline.AttachmentID(id)] = e.Name()
+	}
+	return names, nil
+}
+
+// downloadAttachment stores the attachment identified by id in the attachments directory and returns its file name.
+func downloadAttachment(ctx context.Context, cl *outline.Client, dir string, id outline.AttachmentID) (string, error) {
+	buf := &bytes.Buffer{}
+	contentType, err := cl.Attachments().Download(id).Do(ctx, buf)
+	if err != nil {
+		return "", fmt.Errorf("failed downloading attachment '%s': %w", id, err)
+	}
+
+	name := string(id) + outline.FileExtension(contentType)
+	if err := writeFile(filepath.Join(dir, attachmentsDir, name), buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed writing attachment '%s': %w", id, err)
+	}
+	return name, nil
+}
@@ -0,0 +1,250 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+const (
+	frontMatterDelimiter = "---"
+	maxFileNameLength    = 200
+)
+
+// frontMatter is the metadata of a document stored at the top of its file.
+type frontMatter struct {
+	ID        outline.DocumentID
+	URLID     string
+	Title     string
+	Revision  int
+	UpdatedAt time.Time
+	UpdatedBy string
+	// Hash is the hash of the text as written by the last pull, see [textHash].
+	Hash string
+}
+
+// file is the content of a document's file.
+type file struct {
+	meta frontMatter
+	text string
+}
+
+// unchanged reports whether f holds the current revision of doc i.e. the id, revision and last update in its front
+// matter equal the ones of doc. A nil f, i.e. a missing file, never does.
+func (f *file) unchanged(doc *outline.Document) bool {
+	return f != nil && f.meta.ID == doc.ID && f.meta.Revision == doc.Revision && f.meta.UpdatedAt.Equal(doc.UpdatedAt)
+}
+
+// modified reports whether the text of f was changed since it was written by the last pull. Files without hash, e.g.
+// created locally, are considered modified as that cannot be told. A nil f, i.e. a missing file, never is.
+func (f *file) modified() bool {
+	return f != nil && f.meta.Hash != textHash(f.text)
+}
+
+// textHash returns the hex encoded SHA-256 hash of text.
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// encode returns the file content i.e. the front matter in YAML followed by the text. All strings are double quoted
+// hence any title can be represented.
+func (f *file) encode() []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintln(b, frontMatterDelimiter)
+	fmt.Fprintf(b, "id: %s\n", strconv.Quote(string(f.meta.ID)))
+	fmt.Fprintf(b, "urlId: %s\n", strconv.Quote(f.meta.URLID))
+	fmt.Fprintf(b, "title: %s\n", strconv.Quote(f.meta.Title))
+	fmt.Fprintf(b, "revision: %d\n", f.meta.Revision)
+	fmt.Fprintf(b, "updatedAt: %s\n", strconv.Quote(f.meta.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+	fmt.Fprintf(b, "updatedBy: %s\n", strconv.Quote(f.meta.UpdatedBy))
+	if f.meta.Hash != "" {
+		fmt.Fprintf(b, "hash: %s\n", strconv.Quote(f.meta.Hash))
+	}
+	fmt.Fprintln(b, frontMatterDelimiter)
+	b.WriteString(f.text)
+
+	return b.Bytes()
+}
+
+// decodeFile parses content written by [file.encode]. Unknown front matter keys are ignored. Content without front
+// matter is taken as text as a whole.
+func decodeFile(content []byte) (*file, error) {
+	f := &file{}
+	s := string(content)
+	if !strings.HasPrefix(s, frontMatterDelimiter+"\n") {
+		f.text = s
+		return f, nil
+	}
+
+	header, text, ok := strings.Cut(s[len(frontMatterDelimiter)+1:], "\n"+frontMatterDelimiter+"\n")
+	if !ok {
+		return nil, fmt.Errorf("front matter not terminated by '%s'", frontMatterDelimiter)
+	}
+	f.text = text
+
+	sc := bufio.NewScanner(strings.NewReader(header))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid front matter line '%s'", line)
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			v, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of front matter key '%s': %w", key, err)
+			}
+			value = v
+		}
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "id":
+			f.meta.ID = outline.DocumentID(value)
+		case "urlId":
+			f.meta.URLID = value
+		case "title":
+			f.meta.Title = value
+		case "revision":
+			f.meta.Revision, err = strconv.Atoi(value)
+		case "updatedAt":
+			f.meta.UpdatedAt, err = time.Parse(time.RFC3339Nano, value)
+		case "updatedBy":
+			f.meta.UpdatedBy = value
+		case "hash":
+			f.meta.Hash = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of front matter key '%s': %w", key, err)
+		}
+	}
+
+	return f, nil
+}
+
+// readFiles returns the files of all documents below dir by their path relative to dir.
+func readFiles(dir string) (map[string]*file, error) {
+	files := map[string]*file{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() && d.Name() == attachmentsDir {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := decodeFile(content)
+		if err != nil {
+			return fmt.Errorf("failed reading '%s': %w", path, err)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = f
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// writeFile writes content to path creating missing parent directories. The content is written to a temporary file
+// first which then replaces path hence path is never left half written.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// removeEmptyDirs removes all empty directories below dir, dir itself is kept.
+func removeEmptyDirs(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		sub := filepath.Join(dir, e.Name())
+		if err := removeEmptyDirs(sub); err != nil {
+			return err
+		}
+		if rest, err := os.ReadDir(sub); err == nil && len(rest) == 0 {
+			if err := os.Remove(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fileName returns a name for a file or directory derived from title which is valid on all common file systems.
+func fileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, title)
+
+	// Leave room for suffixes added to tell apart siblings with the same title.
+	for len(name) > maxFileNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
@@ -0,0 +1,341 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+)
+
+// localAttachmentLinkRe matches links to attachments stored in the attachments directory as written by [Pull].
+var localAttachmentLinkRe = regexp.MustCompile(`(?:\.\.?/)*` + attachmentsDir + `/([0-9a-fA-F-]{36})(?:\.\w+)?`)
+
+// ChangeKind is the kind of a [Change] made by [Push].
+type ChangeKind string
+
+const (
+	ChangeCreate  ChangeKind = "create"
+	ChangeUpdate  ChangeKind = "update"
+	ChangeMove    ChangeKind = "move"
+	ChangeArchive ChangeKind = "archive"
+)
+
+// Change is a change of a single document made, or planned, by [Push].
+type Change struct {
+	Kind ChangeKind
+	// Path is the file of the document relative to the pushed directory. It is empty for archived documents.
+	Path string
+	// ID is empty for documents which are yet to be created.
+	ID    outline.DocumentID
+	Title string
+
+	parent string // file of the new parent, empty for top level
+	file   *file
+	text   string // text as sent to the server
+}
+
+func (c Change) String() string {
+	if c.Kind == ChangeArchive {
+		return fmt.Sprintf("%s '%s' (%s)", c.Kind, c.Title, c.ID)
+	}
+	if c.Kind == ChangeMove {
+		parent := c.parent
+		if parent == "" {
+			parent = "top level"
+		}
+		return fmt.Sprintf("%s %s below %s", c.Kind, filepath.ToSlash(c.Path), filepath.ToSlash(parent))
+	}
+	return fmt.Sprintf("%s %s", c.Kind, filepath.ToSlash(c.Path))
+}
+
+// PushResult lists the changes made by [Push], or the ones which would be made on a dry run.
+type PushResult struct {
+	Changes []Change
+	DryRun  bool
+}
+
+// String returns the changes one per line.
+func (r *PushResult) String() string {
+	b := &strings.Builder{}
+	for _, c := range r.Changes {
+		fmt.Fprintln(b, c)
+	}
+	return b.String()
+}
+
+// PushOption configures [Push].
+type PushOption func(*pushOptions)
+
+type pushOptions struct {
+	dryRun  bool
+	archive bool
+}
+
+// WithDryRun makes [Push] only plan the changes without making them.
+func WithDryRun() PushOption {
+	return func(o *pushOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithArchiveRemoved makes [Push] archive the documents of the collection which have no file anymore. By default such
+// documents are left as is.
+func WithArchiveRemoved() PushOption {
+	return func(o *pushOptions) {
+		o.archive = true
+	}
+}
+
+// Push makes the collection identified by id reflect the Markdown files in dir, the counterpart of [Pull]. Files are
+// matched with documents by the id in their front matter:
+//
+//   - Files without id, or with the id of a document which is not part of the collection, are created as new documents.
+//   - Documents whose title or text differs from their file are updated.
+//   - Documents whose file moved to another parent directory are moved accordingly.
+//   - Documents without file are archived if enabled by [WithArchiveRemoved].
+//
+// The parent of a file is the file named like the directory it is in, e.g. the parent of Runbooks/DB.md is
+// Runbooks.md. Parents are created before their children. The title is taken from the front matter, or from the file
+// name if there is none. The front matter of created and updated files is written back hence pushing again changes
+// nothing.
+//
+// Nothing is changed if a document was updated on the server since its file was pulled, instead an error listing
+// those files is returned. Pull first to get the changes from the server. Documents updated on the server while pushing
+// are not overwritten either, an error matching [outline.ErrConflict] is returned instead.
+func Push(
+	ctx context.Context,
+	cl *outline.Client,
+	dir string,
+	id outline.CollectionID,
+	opts ...PushOption,
+) (*PushResult, error) {
+	o := pushOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	local, err := readFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading local files: %w", err)
+	}
+	st, err := cl.Collections().DocumentStructure(id).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting document structure: %w", err)
+	}
+
+	changes, err := planPush(ctx, cl, local, st, o)
+	if err != nil {
+		return nil, err
+	}
+	res := &PushResult{Changes: changes, DryRun: o.dryRun}
+	if o.dryRun {
+		return res, nil
+	}
+
+	ids := map[string]outline.DocumentID{}
+	for path, f := range local {
+		ids[path] = f.meta.ID
+	}
+	for i := range res.Changes {
+		c := &res.Changes[i]
+		if err := applyChange(ctx, cl, dir, id, c, ids); err != nil {
+			return nil, fmt.Errorf("failed to %s: %w", c, err)
+		}
+	}
+
+	return res, nil
+}
+
+// planPush returns the changes needed to make the collection with the structure st reflect the local files.
+func planPush(
+	ctx context.Context,
+	cl *outline.Client,
+	local map[string]*file,
+	st outline.DocumentStructure,
+	o pushOptions,
+) ([]Change, error) {
+	serverParents := map[outline.DocumentID]outline.DocumentID{}
+	_ = st.Walk(func(doc *outline.DocumentSummary, path []*outline.DocumentSummary) error {
+		serverParents[doc.ID] = ""
+		if len(path) > 0 {
+			serverParents[doc.ID] = path[len(path)-1].ID
+		}
+		return nil
+	})
+
+	// Parents first, siblings by name to get a stable order.
+	paths := make([]string, 0, len(local))
+	for path := range local {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], string(filepath.Separator)), strings.Count(paths[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return paths[i] < paths[j]
+	})
+
+	changes := []Change{}
+	conflicts := []string{}
+	pushed := map[outline.DocumentID]bool{}
+	created := map[string]bool{}
+	for _, path := range paths {
+		f := local[path]
+		title := f.meta.Title
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+		c := Change{
+			Path:   path,
+			ID:     f.meta.ID,
+			Title:  title,
+			parent: parentFile(local, path),
+			file:   f,
+			text:   remoteAttachmentLinks(f.text),
+		}
+
+		serverParent, ok := serverParents[f.meta.ID]
+		if !ok || pushed[f.meta.ID] {
+			// A copied file still carries the id of the original document.
+			c.Kind, c.ID = ChangeCreate, ""
+			changes = append(changes, c)
+			created[path] = true
+			continue
+		}
+		pushed[f.meta.ID] = true
+
+		parentID := outline.DocumentID("")
+		if c.parent != "" {
+			parentID = local[c.parent].meta.ID
+		}
+		if created[c.parent] || parentID != serverParent {
+			c.Kind = ChangeMove
+			changes = append(changes, c)
+		}
+
+		doc, err := cl.Documents().Get().ByID(f.meta.ID).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting document '%s': %w", f.meta.ID, err)
+		}
+		if doc.Title == c.Title && normalizeAttachmentLinks(doc.Text) == c.text {
+			continue
+		}
+		if f.meta.Revision != 0 && f.meta.Revision != doc.Revision {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		c.Kind = ChangeUpdate
+		changes = append(changes, c)
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("documents changed on the server since they were pulled: %s", strings.Join(conflicts, ", "))
+	}
+
+	if o.archive {
+		// Children are archived along with their parent. Children which are kept are moved elsewhere before.
+		archived := map[outline.DocumentID]bool{}
+		_ = st.Walk(func(doc *outline.DocumentSummary, _ []*outline.DocumentSummary) error {
+			if pushed[doc.ID] {
+				return nil
+			}
+			archived[doc.ID] = true
+			if !archived[serverParents[doc.ID]] {
+				changes = append(changes, Change{Kind: ChangeArchive, ID: doc.ID, Title: doc.Title})
+			}
+			return nil
+		})
+	}
+
+	return changes, nil
+}
+
+// applyChange makes the change c. The ids of the documents by file are looked up in ids, which is updated with the ids
+// of created documents.
+func applyChange(
+	ctx context.Context,
+	cl *outline.Client,
+	dir string,
+	colID outline.CollectionID,
+	c *Change,
+	ids map[string]outline.DocumentID,
+) error {
+	var doc *outline.Document
+	var err error
+	switch c.Kind {
+	case ChangeCreate:
+		req := cl.Documents().Create(c.Title, colID).Text(c.text).Publish(true)
+		if c.parent != "" {
+			req = req.ParentDocumentID(ids[c.parent])
+		}
+		doc, err = req.Do(ctx)
+		if err == nil {
+			c.ID = doc.ID
+			ids[c.Path] = doc.ID
+		}
+	case ChangeUpdate:
+		req := cl.Documents().Update(c.ID).Title(c.Title).Text(c.text)
+		if c.file.meta.Revision != 0 {
+			// The document might have been changed since it was planned to be updated.
+			req = req.IfRevision(c.file.meta.Revision)
+		}
+		doc, err = req.Do(ctx)
+	case ChangeMove:
+		req := cl.Documents().Move(c.ID).CollectionID(colID)
+		if c.parent != "" {
+			req = req.ParentDocumentID(ids[c.parent])
+		}
+		_, err = req.Do(ctx)
+	case ChangeArchive:
+		_, err = cl.Documents().Archive(c.ID).Do(ctx)
+	}
+	if err != nil || doc == nil {
+		return err
+	}
+
+	f := &file{
+		meta: frontMatter{
+			ID:        doc.ID,
+			URLID:     doc.URLID,
+			Title:     doc.Title,
+			Revision:  doc.Revision,
+			UpdatedAt: doc.UpdatedAt,
+			UpdatedBy: doc.UpdatedBy.Name,
+			// The file holds the pushed text now hence it is no longer considered changed locally.
+			Hash: textHash(c.file.text),
+		},
+		text: c.file.text,
+	}
+	return writeFile(filepath.Join(dir, c.Path), f.encode())
+}
+
+// parentFile returns the file of the parent of the document stored at path, which is the file named like the closest
+// ancestor directory. It is empty for top level documents.
+func parentFile(local map[string]*file, path string) string {
+	for d := filepath.Dir(path); d != "." && d != string(filepath.Separator); d = filepath.Dir(d) {
+		if _, ok := local[d+".md"]; ok {
+			return d + ".md"
+		}
+	}
+	return ""
+}
+
+// remoteAttachmentLinks replaces links to attachments in the attachments directory with the links used by outline.
+func remoteAttachmentLinks(text string) string {
+	return localAttachmentLinkRe.ReplaceAllString(text, attachmentURL("$1"))
+}
+
+// normalizeAttachmentLinks replaces links to attachments which include the server's origin with the links used by
+// outline hence texts differing only in that respect are equal.
+func normalizeAttachmentLinks(text string) string {
+	return attachmentLinkRe.ReplaceAllString(text, attachmentURL("$1"))
+}
+
+func attachmentURL(id string) string {
+	return "/api/" + common.AttachmentsRedirectEndpoint() + "?id=" + id
+}
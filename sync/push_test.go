@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPush(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+	runbooks := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Runbooks", Text: "All runbooks"})
+	db := srv.AddDocument(outline.Document{CollectionID: col.ID, ParentDocumentID: runbooks.ID, Title: "DB"})
+	img := srv.AddAttachment("diagram.png", "image/png", []byte("png"), db.ID)
+	_, err := cl.Documents().Update(db.ID).Text("![diagram](" + img + ")").Do(ctx)
+	require.NoError(t, err)
+	old := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Old"})
+	oldChild := srv.AddDocument(outline.Document{CollectionID: col.ID, ParentDocumentID: old.ID, Title: "Older"})
+
+	_, err = Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+
+	// Nothing changed locally hence there is nothing to push.
+	res, err := Push(ctx, cl, dir, col.ID, WithArchiveRemoved())
+	require.NoError(t, err)
+	assert.Empty(t, res.Changes)
+
+	f := readTestFile(t, dir, "Runbooks.md")
+	f.text = "All runbooks, updated"
+	require.NoError(t, writeFile(filepath.Join(dir, "Runbooks.md"), f.encode()))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Runbooks", "Restart.md"), []byte("How to restart"), 0o644))
+	require.NoError(t, os.Rename(filepath.Join(dir, "Runbooks", "DB.md"), filepath.Join(dir, "DB.md")))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "Old")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "Old.md")))
+
+	res, err = Push(ctx, cl, dir, col.ID, WithArchiveRemoved(), WithDryRun())
+	require.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, "move DB.md below top level\n"+
+		"update Runbooks.md\n"+
+		"create Runbooks/Restart.md\n"+
+		"archive 'Old' ("+string(old.ID)+")\n", res.String())
+	stored, _ := srv.Document(runbooks.ID)
+	assert.Equal(t, "All runbooks", stored.Text)
+
+	res, err = Push(ctx, cl, dir, col.ID, WithArchiveRemoved())
+	require.NoError(t, err)
+	assert.Len(t, res.Changes, 4)
+
+	stored, _ = srv.Document(runbooks.ID)
+	assert.Equal(t, "All runbooks, updated", stored.Text)
+	stored, _ = srv.Document(db.ID)
+	assert.Empty(t, stored.ParentDocumentID)
+	assert.Equal(t, "![diagram]("+img+")", stored.Text)
+	stored, _ = srv.Document(old.ID)
+	assert.False(t, stored.ArchivedAt.IsZero())
+	stored, _ = srv.Document(oldChild.ID)
+	assert.False(t, stored.ArchivedAt.IsZero())
+
+	created := readTestFile(t, dir, filepath.Join("Runbooks", "Restart.md"))
+	require.NotEmpty(t, created.meta.ID)
+	stored, _ = srv.Document(created.meta.ID)
+	assert.Equal(t, "Restart", stored.Title)
+	assert.Equal(t, "How to restart", stored.Text)
+	assert.Equal(t, runbooks.ID, stored.ParentDocumentID)
+	assert.Equal(t, "How to restart", created.text)
+	assert.False(t, created.modified())
+	assert.False(t, readTestFile(t, dir, "Runbooks.md").modified())
+
+	// The front matter was written back hence pushing again changes nothing.
+	res, err = Push(ctx, cl, dir, col.ID, WithArchiveRemoved())
+	require.NoError(t, err)
+	assert.Empty(t, res.Changes)
+}
+
+func TestPush_conflict(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+	doc := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Status", Text: "All good"})
+	_, err := Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+
+	_, err = cl.Documents().Update(doc.ID).Text("Degraded").Do(ctx)
+	require.NoError(t, err)
+	f := readTestFile(t, dir, "Status.md")
+	f.text = "Outage"
+	require.NoError(t, writeFile(filepath.Join(dir, "Status.md"), f.encode()))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "New.md"), []byte("New"), 0o644))
+
+	_, err = Push(ctx, cl, dir, col.ID)
+	require.ErrorContains(t, err, "Status.md")
+
+	// Nothing was changed.
+	stored, _ := srv.Document(doc.ID)
+	assert.Equal(t, "Degraded", stored.Text)
+	st, err := cl.Collections().DocumentStructure(col.ID).Do(ctx)
+	require.NoError(t, err)
+	assert.Len(t, st, 1)
+}
@@ -0,0 +1,193 @@
+// Package sync mirrors outline collections to local directories of Markdown files.
+//
+// Every document of a collection becomes a file named after its title. The children of a document are placed in a
+// directory next to its file, named like the file without extension:
+//
+//	Runbooks.md
+//	Runbooks/
+//	  DB.md
+//	  DB/
+//	    Failover.md
+//	_attachments/
+//	  0f6a3c9e-8d2b-4c4a-9a57-6e1d3b2f7a10.png
+//
+// Every file starts with YAML front matter holding the id, url id, title, revision and last update of the document as
+// well as a hash of the text as written, which tells whether the file was changed locally since.
+// Attachments referenced by documents are stored in the _attachments directory and links to them are rewritten to
+// relative paths.
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ioki-mobility/go-outline"
+)
+
+// PullResult lists the files affected by [Pull]. All paths are relative to the directory pulled to.
+type PullResult struct {
+	// Written are the files of new or changed documents. Documents which moved, or got a new title, are written to their
+	// new path.
+	Written []string
+	// Unchanged are the files of documents which did not change since the last pull.
+	Unchanged []string
+	// Removed are the files of documents which are no longer part of the collection or moved to a different path.
+	Removed []string
+	// Modified are the files changed locally since they were pulled, which would have been overwritten or removed
+	// otherwise. They are left as is. Push them, or delete them to pull the server's version.
+	Modified []string
+}
+
+// Pull mirrors the published documents of the collection identified by id to dir. Repeated pulls to the same directory
+// are incremental: all documents are listed page by page and only those whose revision or last update differ from the
+// ones in the file's front matter are written. Other files are left as is, and attachments are only downloaded once.
+// Files of documents which are no longer part of the collection, or moved to a different path, are removed.
+//
+// Local changes are never lost: a file whose text was changed since it was pulled is neither overwritten nor removed,
+// even if the document changed on the server, was moved or deleted. Such files are reported as modified instead and
+// the document is not written to any other path either. Files created locally are kept unless they are in the way of
+// a document, then they are reported as modified as well.
+func Pull(ctx context.Context, cl *outline.Client, id outline.CollectionID, dir string) (*PullResult, error) {
+	local, err := readFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading local files: %w", err)
+	}
+	localPaths := map[outline.DocumentID]string{}
+	for path, f := range local {
+		if f.meta.ID != "" {
+			localPaths[f.meta.ID] = path
+		}
+	}
+
+	st, err := cl.Collections().DocumentStructure(id).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting document structure: %w", err)
+	}
+
+	listed := map[outline.DocumentID]*outline.Document{}
+	err = cl.Documents().GetAll().Collection(id).Do(ctx, func(doc *outline.Document, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		listed[doc.ID] = doc
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing documents: %w", err)
+	}
+
+	res := &PullResult{}
+	paths := structurePaths(st)
+	keep := map[string]bool{}
+	err = st.Walk(func(summary *outline.DocumentSummary, _ []*outline.DocumentSummary) error {
+		path := paths[summary.ID]
+		keep[path] = true
+
+		// Only documents missing from the list, as they were added in between, are fetched.
+		doc, ok := listed[summary.ID]
+		if !ok {
+			if doc, err = cl.Documents().Get().ByID(summary.ID).Do(ctx); err != nil {
+				return fmt.Errorf("failed getting document '%s': %w", summary.ID, err)
+			}
+		}
+		if local[path].unchanged(doc) {
+			res.Unchanged = append(res.Unchanged, path)
+			return nil
+		}
+		if old, ok := localPaths[doc.ID]; ok && local[old].modified() {
+			keep[old] = true
+			res.Modified = append(res.Modified, old)
+			return nil
+		}
+		if local[path].modified() && local[path].meta.ID != doc.ID {
+			res.Modified = append(res.Modified, path)
+			return nil
+		}
+
+		text, err := pullAttachments(ctx, cl, dir, path, doc.Text)
+		if err != nil {
+			return fmt.Errorf("failed pulling attachments of document '%s': %w", doc.ID, err)
+		}
+		f := &file{
+			meta: frontMatter{
+				ID:        doc.ID,
+				URLID:     doc.URLID,
+				Title:     doc.Title,
+				Revision:  doc.Revision,
+				UpdatedAt: doc.UpdatedAt,
+				UpdatedBy: doc.UpdatedBy.Name,
+				Hash:      textHash(text),
+			},
+			text: text,
+		}
+		if err := writeFile(filepath.Join(dir, path), f.encode()); err != nil {
+			return fmt.Errorf("failed writing document '%s': %w", doc.ID, err)
+		}
+		res.Written = append(res.Written, path)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove files of documents which are gone or were written to a new path above.
+	for _, path := range localPaths {
+		if keep[path] {
+			continue
+		}
+		if local[path].modified() {
+			res.Modified = append(res.Modified, path)
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, path)); err != nil {
+			return nil, fmt.Errorf("failed removing '%s': %w", path, err)
+		}
+		res.Removed = append(res.Removed, path)
+	}
+	sort.Strings(res.Removed)
+	sort.Strings(res.Modified)
+	if err := removeEmptyDirs(dir); err != nil {
+		return nil, fmt.Errorf("failed removing empty directories: %w", err)
+	}
+
+	return res, nil
+}
+
+// structurePaths returns the path of every document's file relative to the directory the collection is mirrored to.
+// Siblings with the same file name are told apart by their url id. File names are compared case insensitive as not
+// all file systems are case sensitive.
+func structurePaths(st outline.DocumentStructure) map[outline.DocumentID]string {
+	paths := map[outline.DocumentID]string{}
+
+	var assign func(docs []outline.DocumentSummary, dir string)
+	assign = func(docs []outline.DocumentSummary, dir string) {
+		taken := map[string]bool{}
+		for _, doc := range docs {
+			name := fileName(doc.Title)
+			if taken[strings.ToLower(name)] {
+				name += " (" + urlIDOf(doc) + ")"
+			}
+			taken[strings.ToLower(name)] = true
+
+			paths[doc.ID] = filepath.Join(dir, name+".md")
+			assign(doc.Children, filepath.Join(dir, name))
+		}
+	}
+	assign(st, "")
+
+	return paths
+}
+
+// urlIDOf returns the url id of doc which is the last part of its url e.g. /doc/welcome-hDYep1TPAM
+func urlIDOf(doc outline.DocumentSummary) string {
+	if i := strings.LastIndex(doc.URL, "-"); i >= 0 {
+		return doc.URL[i+1:]
+	}
+	return string(doc.ID)
+}
@@ -0,0 +1,228 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/ioki-mobility/go-outline/outlinetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "api key"
+
+func TestPull(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+	runbooks := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Runbooks", Text: "All runbooks"})
+	db := srv.AddDocument(outline.Document{CollectionID: col.ID, ParentDocumentID: runbooks.ID, Title: "DB"})
+	img := srv.AddAttachment("diagram.png", "image/png", []byte("png"), db.ID)
+	_, err := cl.Documents().Update(db.ID).Text("Setup\n\n![diagram](" + img + ")\n").Do(ctx)
+	require.NoError(t, err)
+	srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "a/b"})
+	srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "A/B"})
+
+	res, err := Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+	assert.Len(t, res.Written, 4)
+	assert.Empty(t, res.Unchanged)
+	assert.Empty(t, res.Removed)
+
+	f := readTestFile(t, dir, "Runbooks.md")
+	assert.Equal(t, runbooks.ID, f.meta.ID)
+	assert.Equal(t, runbooks.URLID, f.meta.URLID)
+	assert.Equal(t, 1, f.meta.Revision)
+	assert.True(t, runbooks.UpdatedAt.Equal(f.meta.UpdatedAt))
+	assert.Equal(t, srv.User.Name, f.meta.UpdatedBy)
+	assert.Equal(t, "All runbooks", f.text)
+	assert.Equal(t, textHash("All runbooks"), f.meta.Hash)
+	assert.False(t, f.modified())
+
+	f = readTestFile(t, dir, filepath.Join("Runbooks", "DB.md"))
+	assert.Equal(t, 2, f.meta.Revision)
+	attachment := "../_attachments/" + img[len(img)-36:] + ".png"
+	assert.Equal(t, "Setup\n\n![diagram]("+attachment+")\n", f.text)
+	data, err := os.ReadFile(filepath.Join(dir, "Runbooks", filepath.FromSlash(attachment)))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	// Siblings with the same file name are told apart by url id.
+	assert.FileExists(t, filepath.Join(dir, "a-b.md"))
+	assert.Len(t, glob(t, dir, "A-B (*).md"), 1)
+
+	// Nothing changed hence nothing is fetched, written or downloaded again.
+	downloads := srv.RequestCount(common.AttachmentsRedirectEndpoint())
+	fetches := srv.RequestCount(common.DocumentsGetEndpoint())
+	res, err = Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+	assert.Empty(t, res.Written)
+	assert.Len(t, res.Unchanged, 4)
+	assert.Empty(t, res.Removed)
+	assert.Equal(t, downloads, srv.RequestCount(common.AttachmentsRedirectEndpoint()))
+	assert.Equal(t, fetches, srv.RequestCount(common.DocumentsGetEndpoint()))
+
+	// Renamed documents are written to their new path, deleted ones are removed along with empty directories.
+	_, err = cl.Documents().Update(runbooks.ID).Title("Playbooks").Do(ctx)
+	require.NoError(t, err)
+	err = cl.Documents().Delete(db.ID).Do(ctx)
+	require.NoError(t, err)
+
+	res, err = Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+	// Changed documents are taken from the list rather than fetched one by one.
+	assert.Equal(t, fetches, srv.RequestCount(common.DocumentsGetEndpoint()))
+	assert.Equal(t, []string{"Playbooks.md"}, res.Written)
+	assert.ElementsMatch(t, []string{"Runbooks.md", filepath.Join("Runbooks", "DB.md")}, res.Removed)
+	assert.NoFileExists(t, filepath.Join(dir, "Runbooks.md"))
+	assert.NoDirExists(t, filepath.Join(dir, "Runbooks"))
+	assert.Equal(t, "All runbooks", readTestFile(t, dir, "Playbooks.md").text)
+}
+
+func TestPull_keepsLocalFiles(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	dir := t.TempDir()
+
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+	srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Runbooks"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Notes.md"), []byte("Not yet pushed"), 0o644))
+
+	res, err := Pull(context.Background(), cl, col.ID, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Runbooks.md"}, res.Written)
+	assert.Empty(t, res.Removed)
+	assert.FileExists(t, filepath.Join(dir, "Notes.md"))
+}
+
+func TestPull_keepsLocalChanges(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+	cl := srv.OutlineClient()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	col := srv.AddCollection(outline.Collection{Name: "Ops"})
+	runbooks := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Runbooks", Text: "All runbooks"})
+	db := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "DB", Text: "Setup"})
+	failover := srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Failover", Text: "Steps"})
+	_, err := Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+
+	edit := func(path string) {
+		f := readTestFile(t, dir, path)
+		f.text += "\nLocal change"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), f.encode(), 0o644))
+	}
+	edit("Runbooks.md")
+	edit("DB.md")
+	edit("Failover.md")
+
+	// The documents are changed, deleted and moved on the server.
+	_, err = cl.Documents().Update(runbooks.ID).Text("Remote change").Do(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cl.Documents().Delete(db.ID).Do(ctx))
+	_, err = cl.Documents().Move(failover.ID).ParentDocumentID(runbooks.ID).Do(ctx)
+	require.NoError(t, err)
+	// A file created locally is in the way of a new document.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Alerts.md"), []byte("Not yet pushed"), 0o644))
+	srv.AddDocument(outline.Document{CollectionID: col.ID, Title: "Alerts", Text: "Pages"})
+
+	res, err := Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+	assert.Empty(t, res.Written)
+	assert.Empty(t, res.Removed)
+	assert.Equal(t, []string{"Alerts.md", "DB.md", "Failover.md", "Runbooks.md"}, res.Modified)
+	assert.Equal(t, "All runbooks\nLocal change", readTestFile(t, dir, "Runbooks.md").text)
+	assert.Equal(t, "Setup\nLocal change", readTestFile(t, dir, "DB.md").text)
+	assert.Equal(t, "Steps\nLocal change", readTestFile(t, dir, "Failover.md").text)
+	assert.NoFileExists(t, filepath.Join(dir, "Runbooks", "Failover.md"))
+	assert.Equal(t, "Not yet pushed", readTestFile(t, dir, "Alerts.md").text)
+
+	// Once the local changes are dropped the server's version is pulled.
+	require.NoError(t, os.Remove(filepath.Join(dir, "Runbooks.md")))
+	res, err = Pull(ctx, cl, col.ID, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Runbooks.md"}, res.Written)
+	assert.Equal(t, "Remote change", readTestFile(t, dir, "Runbooks.md").text)
+}
+
+func TestPull_error(t *testing.T) {
+	srv := outlinetest.NewServer(testApiKey)
+	defer srv.Close()
+
+	_, err := Pull(context.Background(), srv.OutlineClient(), "unknown", t.TempDir())
+	require.Error(t, err)
+}
+
+func TestFile_encodeDecode(t *testing.T) {
+	f := &file{
+		meta: frontMatter{
+			ID:        "id",
+			URLID:     "urlID",
+			Title:     `Say "hi": now`,
+			Revision:  3,
+			UpdatedAt: time.Now(),
+			UpdatedBy: "Jane",
+			Hash:      textHash("---\n# Heading\n"),
+		},
+		text: "---\n# Heading\n",
+	}
+
+	got, err := decodeFile(f.encode())
+	require.NoError(t, err)
+	assert.Equal(t, f.meta.Title, got.meta.Title)
+	assert.True(t, f.meta.UpdatedAt.Equal(got.meta.UpdatedAt))
+	got.meta.UpdatedAt = f.meta.UpdatedAt
+	assert.Equal(t, f, got)
+
+	got, err = decodeFile([]byte("# No front matter"))
+	require.NoError(t, err)
+	assert.Equal(t, &file{text: "# No front matter"}, got)
+
+	_, err = decodeFile([]byte("---\nid: \"x\"\n"))
+	require.Error(t, err)
+}
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"Runbooks":     "Runbooks",
+		"a/b\\c:d?":    "a-b-c-d-",
+		" .hidden. ":   "hidden",
+		"":             "Untitled",
+		"Ünïcödé 🚀":    "Ünïcödé 🚀",
+		"tab\tinside":  "tab-inside",
+		"...":          "Untitled",
+		"trailing ...": "trailing",
+	}
+	for title, want := range tests {
+		assert.Equal(t, want, fileName(title), title)
+	}
+}
+
+func readTestFile(t *testing.T, dir string, path string) *file {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	require.NoError(t, err)
+	f, err := decodeFile(content)
+	require.NoError(t, err)
+	return f
+}
+
+func glob(t *testing.T, dir string, pattern string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	require.NoError(t, err)
+	return matches
+}
@@ -0,0 +1,59 @@
+// Package tracing provides an [outline.Middleware] which creates an OpenTelemetry span for every call made by an
+// outline client.
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/ioki-mobility/go-outline"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer used by the middleware.
+const instrumentationName = "github.com/ioki-mobility/go-outline/tracing"
+
+// Middleware returns a middleware which wraps every call into a client span created by a tracer of tp. If tp is nil
+// then the global tracer provider is used. The span is named after the called endpoint e.g. "documents.update" and
+// becomes the parent of spans created further down the chain, like the ones of an instrumented HTTP transport.
+func Middleware(tp trace.TracerProvider) outline.Middleware {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	tracer := tp.Tracer(instrumentationName)
+
+	return func(next outline.Invoker) outline.Invoker {
+		return func(call *outline.Call) *outline.CallResult {
+			ctx, span := tracer.Start(
+				call.Request.Context(),
+				call.Endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("outline.endpoint", call.Endpoint),
+					attribute.String("http.request.method", call.Request.Method),
+					attribute.String("server.address", call.Request.URL.Hostname()),
+					attribute.Int("http.request.body.size", len(call.Body)),
+				),
+			)
+			defer span.End()
+
+			call.Request = call.Request.WithContext(ctx)
+			res := next(call)
+
+			if res.Status != 0 {
+				span.SetAttributes(attribute.Int("http.response.status_code", res.Status))
+			}
+			switch {
+			case res.Err != nil:
+				span.RecordError(res.Err)
+				span.SetStatus(codes.Error, res.Err.Error())
+			case res.Status >= 400:
+				span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", res.Status))
+			}
+
+			return res
+		}
+	}
+}
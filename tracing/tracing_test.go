@@ -0,0 +1,85 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ioki-mobility/go-outline"
+	"github.com/ioki-mobility/go-outline/internal/testutils"
+	"github.com/ioki-mobility/go-outline/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		// The span must be available to further layers via the request context.
+		assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+
+		status := http.StatusOK
+		if strings.HasSuffix(r.URL.Path, "collections.update") {
+			status = http.StatusForbidden
+		}
+		return &http.Response{
+			Request:       r,
+			StatusCode:    status,
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(`{"data": {}}`)),
+		}, nil
+	}}
+
+	cl := outline.New("https://wiki.example.com", hc, "api key", outline.WithMiddleware(tracing.Middleware(tp)))
+	_, err := cl.Collections().Get("collection id").Do(context.Background())
+	require.NoError(t, err)
+	_, err = cl.Collections().Update("collection id").Name("name").Do(context.Background())
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "collections.info", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("outline.endpoint", "collections.info"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("server.address", "wiki.example.com"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	assert.Equal(t, "collections.update", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("http.response.status_code", http.StatusForbidden))
+}
+
+func TestMiddleware_failedRequest(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	hc := &http.Client{}
+	hc.Transport = &testutils.MockRoundTripper{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return nil, &net.DNSError{}
+	}}
+
+	cl := outline.New("https://wiki.example.com", hc, "api key", outline.WithMiddleware(tracing.Middleware(tp)))
+	_, err := cl.Documents().Get().ByID("id").Do(context.Background())
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "documents.info", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
@@ -0,0 +1,91 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/rsjethani/rsling"
+)
+
+// UsersClient exposes operations around the users resource.
+type UsersClient struct {
+	sl *rsling.Sling
+}
+
+// newUsersClient creates a new instance of UsersClient.
+func newUsersClient(sl *rsling.Sling) *UsersClient {
+	return &UsersClient{sl: sl}
+}
+
+// List returns a client for listing the users of the workspace.
+// API reference: https://www.getoutline.com/developers#tag/Users/paths/~1users.list/post
+func (cl *UsersClient) List() *UsersListClient {
+	return newUsersListClient(cl.sl)
+}
+
+// usersListParams represents the Outline Users.list parameters
+type usersListParams struct {
+	Query string `json:"query,omitempty"`
+}
+
+// UsersListClient is a client for listing the users of the workspace.
+type UsersListClient struct {
+	sl     *rsling.Sling
+	params usersListParams
+}
+
+func newUsersListClient(sl *rsling.Sling) *UsersListClient {
+	return &UsersListClient{sl: sl.New()}
+}
+
+// Query selects only users whose name or email matches query.
+func (cl *UsersListClient) Query(query string) *UsersListClient {
+	c := *cl
+	c.params.Query = query
+	return &c
+}
+
+// UsersListFn is the type of function called by [UsersListClient.Do] for every user it finds.
+type UsersListFn func(*User, error) (bool, error)
+
+// Do makes the actual request for listing users. If the request is successful then fn is called sequentially with
+// every user received. But if there is some error/bad response then fn is called with the error. If fn returns false
+// then the whole process is aborted otherwise the request is retried.
+func (cl *UsersListClient) Do(ctx context.Context, fn UsersListFn) error {
+	params := &paginationQueryParams{}
+	for {
+		success := &struct {
+			Data       []*User    `json:"data"`
+			Pagination pagination `json:"pagination"`
+		}{}
+
+		// Create a fresh copy of original request for every page then set query parameters accordingly.
+		copy := cl.sl.New().Post(common.UsersListEndpoint()).BodyJSON(&cl.params).QueryStruct(params)
+
+		br, err := request(ctx, copy, success)
+		if err != nil {
+			err = fmt.Errorf("failed making HTTP request: %w", err)
+		}
+		if br != nil {
+			err = fmt.Errorf("bad response: %w", &apiError{br: *br})
+		}
+		if err != nil {
+			if ok, e := fn(nil, err); !ok {
+				return e
+			}
+			continue
+		}
+
+		for _, u := range success.Data {
+			if ok, e := fn(u, nil); !ok {
+				return e
+			}
+		}
+
+		if len(success.Data) <= 1 {
+			return nil
+		}
+		params.Offset += len(success.Data)
+	}
+}
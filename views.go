@@ -0,0 +1,108 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ioki-mobility/go-outline/internal/common"
+	"github.com/rsjethani/rsling"
+)
+
+// ViewsClient exposes operations around the views resource i.e. who looked at which document and how often.
+type ViewsClient struct {
+	sl *rsling.Sling
+}
+
+// newViewsClient creates a new instance of ViewsClient.
+func newViewsClient(sl *rsling.Sling) *ViewsClient {
+	return &ViewsClient{sl: sl}
+}
+
+// List returns a client for listing all views of the document identified by id.
+// API reference: https://www.getoutline.com/developers#tag/Views/paths/~1views.list/post
+func (cl *ViewsClient) List(id DocumentID) *ViewsListClient {
+	return newViewsListClient(cl.sl, id)
+}
+
+// Create returns a client for recording a view of the document identified by id by the current user.
+// API reference: https://www.getoutline.com/developers#tag/Views/paths/~1views.create/post
+func (cl *ViewsClient) Create(id DocumentID) *ViewsCreateClient {
+	return newViewsCreateClient(cl.sl, id)
+}
+
+// viewsListParams represents the Outline Views.list parameters
+type viewsListParams struct {
+	DocumentID       DocumentID `json:"documentId"`
+	IncludeSuspended bool       `json:"includeSuspended,omitempty"`
+}
+
+// ViewsListClient is a client for listing views of a single document.
+type ViewsListClient struct {
+	sl     *rsling.Sling
+	params viewsListParams
+}
+
+func newViewsListClient(sl *rsling.Sling, id DocumentID) *ViewsListClient {
+	copy := sl.New()
+	params := viewsListParams{DocumentID: id}
+	return &ViewsListClient{sl: copy, params: params}
+}
+
+// IncludeSuspended configures whether views of suspended users should be part of the result.
+func (cl *ViewsListClient) IncludeSuspended(include bool) *ViewsListClient {
+	c := *cl
+	c.params.IncludeSuspended = include
+	return &c
+}
+
+// Do makes the actual request and returns views of the document, one per user.
+func (cl *ViewsListClient) Do(ctx context.Context) ([]View, error) {
+	req := cl.sl.New().Post(common.ViewsListEndpoint()).BodyJSON(&cl.params)
+
+	success := &struct {
+		Data []View `json:"data"`
+	}{}
+
+	br, err := request(ctx, req, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}
+
+// ViewsCreateClient is a client for recording a single view.
+type ViewsCreateClient struct {
+	sl *rsling.Sling
+}
+
+func newViewsCreateClient(sl *rsling.Sling, id DocumentID) *ViewsCreateClient {
+	data := struct {
+		DocumentID DocumentID `json:"documentId"`
+	}{DocumentID: id}
+
+	copy := sl.New()
+	copy.Post(common.ViewsCreateEndpoint()).BodyJSON(&data)
+
+	return &ViewsCreateClient{sl: copy}
+}
+
+// Do makes the actual request to record the view and returns the updated view.
+func (cl *ViewsCreateClient) Do(ctx context.Context) (*View, error) {
+	success := &struct {
+		Data *View `json:"data"`
+	}{}
+
+	br, err := request(ctx, cl.sl, success)
+	if err != nil {
+		return nil, fmt.Errorf("failed making HTTP request: %w", err)
+	}
+	if br != nil {
+		return nil, fmt.Errorf("bad response: %w", &apiError{br: *br})
+	}
+
+	return success.Data, nil
+}